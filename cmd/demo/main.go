@@ -4,46 +4,55 @@ import (
 	"encoding/xml"
 	"fmt"
 
+	"github.com/rodascaar/sifen-go-py/sifen"
+	"github.com/rodascaar/sifen-go-py/sifen/builder"
 	"github.com/rodascaar/sifen-go-py/sifen/models"
 	"github.com/rodascaar/sifen-go-py/sifen/types"
 )
 
 func main() {
-	de := models.NewDE("0180000001")
+	config := sifen.NewSifenConfig()
+	config.RucEmisor = "80069563"
+	config.DvEmisor = "1"
+	config.TipoContribuyente = types.TiTipCont_PersonaJuridica
 
-	// Create some sample data
-	de.DE.GOpeDE = models.TgOpeDE{
-		ITipEmi: types.TTipEmi_Normal,
-		DCodSeg: "12345",
-	}
-
-	de.DE.GTimb = models.TgTimb{
-		ITiDE:   types.TTiDE_FacturaElectronica,
-		DNumTim: 12345678,
-		DEst:    "001",
-		DPunExp: "001",
-		DNumDoc: "0000001",
-		DFeIniT: "2024-01-01",
-	}
-
-	// Just a smoke test for nested structures
-	de.DE.GDatGralOpe = models.TdDatGralOpe{
-		DFeEmiDE: "2024-01-01T10:00:00",
-		GOpeCom: &models.TgOpeCom{
-			ITImp:    types.TTImp_IVA,
-			CMoneOpe: types.CMondT_PYG,
-		},
-		GEmis: models.TgEmis{
-			DRucEm:  "80000001",
-			DNomEmi: "Empresa Test SA",
-			CDepEmi: types.TDepartamento_Capital,
-			CCiuEmi: 1,
-			DTelEmi: "123456",
-			DEmailE: "test@test.com",
+	de, err := builder.NewFactura().
+		DesdeConfig(config).
+		Emisor(models.TgEmis{
+			DNomEmi:    "Empresa Test SA",
+			DDirEmi:    "Calle 1",
+			DNumCas:    "0",
+			CDepEmi:    types.TDepartamento_Capital,
+			DDesDepEmi: types.TDepartamento_Capital.String(),
+			CCiuEmi:    1,
+			DDesCiuEmi: "ASUNCION (DISTRITO)",
+			DTelEmi:    "021123456",
+			DEmailE:    "test@test.com",
 			GActEcoList: []models.TgActEco{
-				{CActEco: "12345", DDesActEco: "Activadad Test"},
+				{CActEco: "46510", DDesActEco: "Comercio al por mayor de equipos informáticos"},
 			},
-		},
+		}).
+		Timbrado(12345678, "2024-01-01").
+		Numero("001", "001", "1").
+		Receptor(models.TgDatRec{
+			INatRec:    types.TiNatRec_NoContribuyente,
+			ITiOpe:     types.TiTiOpe_B2C,
+			CPaisRec:   types.PaisType_PRY,
+			DDesPaisRe: "Paraguay",
+			DNumIDRec:  "1234567",
+			DNomRec:    "Cliente de Prueba",
+		}).
+		Item(models.TgCamItem{
+			DCodInt:     "001",
+			DDesProSer:  "Producto de prueba",
+			CUniMed:     types.TcUniMed_Unidad,
+			DDesUniMed:  "UNI",
+			DCantProSer: types.DecimalFromInt(1),
+			GValorItem:  models.TgValorItem{DPUniProSer: types.DecimalFromInt(110000), DTotBruOpeItem: types.DecimalFromInt(110000)},
+		}).
+		Build()
+	if err != nil {
+		panic(err)
 	}
 
 	output, err := xml.MarshalIndent(de, "", "  ")
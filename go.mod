@@ -6,11 +6,14 @@ require (
 	github.com/beevik/etree v1.6.0
 	github.com/stretchr/testify v1.5.1
 	github.com/ucarion/c14n v0.1.0
-	golang.org/x/crypto v0.46.0
+	github.com/youmark/pkcs8 v0.0.0-20240726163527-a2c0da244d78
+	gopkg.in/yaml.v3 v3.0.1
+	software.sslmate.com/src/go-pkcs12 v0.7.3
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v2 v2.2.2 // indirect
+	golang.org/x/crypto v0.46.0 // indirect
+	gopkg.in/yaml.v2 v2.4.0 // indirect
 )
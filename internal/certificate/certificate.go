@@ -0,0 +1,206 @@
+// Package certificate loads client certificates and their private keys from
+// PKCS#12 (PFX) or PEM encoded data.
+package certificate
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/youmark/pkcs8"
+)
+
+// Format is the encoding of a certificate
+type Format string
+
+const (
+	FormatAuto   Format = ""
+	FormatPKCS12 Format = "PFX"
+	FormatPEM    Format = "PEM"
+)
+
+// errPKCS8IncorrectPassword is the message of github.com/youmark/pkcs8 when the
+// decrypted key does not parse, which it reports without a sentinel error
+const errPKCS8IncorrectPassword = "pkcs8: incorrect password"
+
+var (
+	// ErrIncorrectPassword is returned when the password does not decrypt the key or verify the PFX integrity
+	ErrIncorrectPassword = errors.New("certificate: incorrect password")
+	// ErrNoPrivateKey is returned when the data holds no private key
+	ErrNoPrivateKey = errors.New("certificate: no private key found")
+	// ErrNoCertificate is returned when no certificate matches the private key
+	ErrNoCertificate = errors.New("certificate: no certificate matches the private key")
+)
+
+// Load decodes a certificate and its private key. certData holds a PFX file or
+// PEM blocks; for PEM the key may be in certData or in keyData. The password
+// decrypts the PFX or an encrypted PEM key. FormatAuto detects PEM by its
+// "-----BEGIN" marker.
+func Load(format Format, certData, keyData []byte, password string) (tls.Certificate, error) {
+	if format == FormatAuto {
+		format = FormatPKCS12
+		if IsPEM(certData) {
+			format = FormatPEM
+		}
+	}
+
+	switch format {
+	case FormatPKCS12:
+		return LoadPKCS12(certData, password)
+	case FormatPEM:
+		return LoadPEM(certData, keyData, password)
+	default:
+		return tls.Certificate{}, fmt.Errorf("certificate: unknown format %q", format)
+	}
+}
+
+// IsPEM reports whether data looks like PEM encoded content
+func IsPEM(data []byte) bool {
+	return bytes.Contains(data, []byte("-----BEGIN "))
+}
+
+// LoadPEM decodes the certificates and the private key in certPEM and keyPEM.
+// Keys may be PKCS#1, SEC 1, PKCS#8 or password protected PKCS#8; the
+// certificate chain may come in any order.
+func LoadPEM(certPEM, keyPEM []byte, password string) (tls.Certificate, error) {
+	var certs []*x509.Certificate
+	var key crypto.PrivateKey
+
+	for _, data := range [][]byte{certPEM, keyPEM} {
+		for {
+			var block *pem.Block
+			block, data = pem.Decode(data)
+			if block == nil {
+				break
+			}
+
+			switch block.Type {
+			case "CERTIFICATE":
+				cert, err := x509.ParseCertificate(block.Bytes)
+				if err != nil {
+					return tls.Certificate{}, fmt.Errorf("certificate: %w", err)
+				}
+				certs = append(certs, cert)
+			case "PRIVATE KEY", "RSA PRIVATE KEY", "EC PRIVATE KEY", "ENCRYPTED PRIVATE KEY":
+				if key != nil {
+					return tls.Certificate{}, errors.New("certificate: more than one private key found")
+				}
+				parsed, err := parsePEMKey(block, password)
+				if err != nil {
+					return tls.Certificate{}, err
+				}
+				key = parsed
+			}
+		}
+	}
+
+	return newCertificate(key, certs)
+}
+
+func parsePEMKey(block *pem.Block, password string) (crypto.PrivateKey, error) {
+	der := block.Bytes
+
+	if block.Type == "ENCRYPTED PRIVATE KEY" {
+		// PBES2 (PBKDF2 with AES or 3DES), the default of current OpenSSL.
+		// Without a password the library reads it as an unencrypted key.
+		key, err := pkcs8.ParsePKCS8PrivateKey(der, []byte(password))
+		if err != nil {
+			if err.Error() == errPKCS8IncorrectPassword || password == "" {
+				return nil, ErrIncorrectPassword
+			}
+			return nil, fmt.Errorf("certificate: %w", err)
+		}
+		return key, nil
+	}
+	if x509.IsEncryptedPEMBlock(block) {
+		// Legacy OpenSSL encryption ("Proc-Type: 4,ENCRYPTED"); still produced by some CAs
+		decrypted, err := x509.DecryptPEMBlock(block, []byte(password))
+		if err != nil {
+			return nil, ErrIncorrectPassword
+		}
+		der = decrypted
+	}
+
+	var key crypto.PrivateKey
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(der)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(der)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(der)
+		if err != nil && block.Type == "PRIVATE KEY" {
+			// Some tools label PKCS#1 keys as "PRIVATE KEY"
+			if rsaKey, rsaErr := x509.ParsePKCS1PrivateKey(der); rsaErr == nil {
+				key, err = rsaKey, nil
+			}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("certificate: %w", err)
+	}
+	return key, nil
+}
+
+// newCertificate pairs key with the certificate holding its public key and
+// orders the remaining certificates as the chain that issued it.
+func newCertificate(key crypto.PrivateKey, certs []*x509.Certificate) (tls.Certificate, error) {
+	if key == nil {
+		return tls.Certificate{}, ErrNoPrivateKey
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return tls.Certificate{}, fmt.Errorf("certificate: unsupported private key type %T", key)
+	}
+	public, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok {
+		return tls.Certificate{}, fmt.Errorf("certificate: unsupported public key type %T", signer.Public())
+	}
+
+	var leaf *x509.Certificate
+	rest := make([]*x509.Certificate, 0, len(certs))
+	for _, cert := range certs {
+		if leaf == nil && public.Equal(cert.PublicKey) {
+			leaf = cert
+			continue
+		}
+		rest = append(rest, cert)
+	}
+	if leaf == nil {
+		return tls.Certificate{}, ErrNoCertificate
+	}
+
+	result := tls.Certificate{
+		Certificate: [][]byte{leaf.Raw},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}
+
+	// Follow the issuers from the leaf; certificates outside the chain go last
+	current := leaf
+	for len(rest) > 0 {
+		next := -1
+		for i, cert := range rest {
+			if bytes.Equal(cert.RawSubject, current.RawIssuer) && !bytes.Equal(current.RawSubject, current.RawIssuer) {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			break
+		}
+		current = rest[next]
+		result.Certificate = append(result.Certificate, current.Raw)
+		rest = append(rest[:next], rest[next+1:]...)
+	}
+	for _, cert := range rest {
+		result.Certificate = append(result.Certificate, cert.Raw)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,138 @@
+package certificate
+
+import (
+	"crypto/x509"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testPassword = "secreto"
+
+func readTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name      string
+		format    Format
+		cert, key string
+	}{
+		{"PFX with AES and SHA-256 MAC", FormatPKCS12, "modern.pfx", ""},
+		{"PFX with RC2 and 3DES", FormatPKCS12, "legacy.pfx", ""},
+		{"PEM chain and key", FormatPEM, "chain.pem", "key.pem"},
+		{"PEM chain and encrypted PKCS#8 key", FormatPEM, "chain.pem", "key-encrypted.pem"},
+		{"autodetected PFX", FormatAuto, "modern.pfx", ""},
+		{"autodetected PEM", FormatAuto, "chain.pem", "key-encrypted.pem"},
+	}
+
+	for _, tt := range tests {
+		var key []byte
+		if tt.key != "" {
+			key = readTestdata(t, tt.key)
+		}
+
+		cert, err := Load(tt.format, readTestdata(t, tt.cert), key, testPassword)
+		if err != nil {
+			t.Errorf("%s: Load() error = %v", tt.name, err)
+			continue
+		}
+		if cert.Leaf == nil || cert.Leaf.Subject.SerialNumber != "RUC80069563-1" {
+			t.Errorf("%s: leaf = %v; want the emitter certificate", tt.name, cert.Leaf)
+		}
+		if len(cert.Certificate) != 2 {
+			t.Fatalf("%s: chain length = %d; want 2", tt.name, len(cert.Certificate))
+		}
+		issuer, err := x509.ParseCertificate(cert.Certificate[1])
+		if err != nil || issuer.Subject.CommonName != "CA de Prueba" {
+			t.Errorf("%s: chain[1] = %v, %v; want the CA", tt.name, issuer, err)
+		}
+	}
+}
+
+func TestLoadPEMUnorderedChainInOneFile(t *testing.T) {
+	chain := readTestdata(t, "chain.pem")
+	key := readTestdata(t, "key-encrypted.pem")
+
+	// CA first, then the key, then the leaf
+	leafEnd := len("-----BEGIN CERTIFICATE-----")
+	for i := leafEnd; i < len(chain); i++ {
+		if string(chain[i:i+leafEnd]) == "-----BEGIN CERTIFICATE-----" {
+			leafEnd = i
+			break
+		}
+	}
+	data := append(append(append([]byte{}, chain[leafEnd:]...), key...), chain[:leafEnd]...)
+
+	cert, err := LoadPEM(data, nil, testPassword)
+	if err != nil {
+		t.Fatalf("LoadPEM() error = %v", err)
+	}
+	if cert.Leaf.Subject.SerialNumber != "RUC80069563-1" || len(cert.Certificate) != 2 {
+		t.Errorf("LoadPEM() leaf = %s, chain = %d", cert.Leaf.Subject, len(cert.Certificate))
+	}
+}
+
+func TestLoadIncorrectPassword(t *testing.T) {
+	tests := []struct {
+		name      string
+		cert, key string
+	}{
+		{"modern.pfx", "modern.pfx", ""},
+		{"legacy.pfx", "legacy.pfx", ""},
+		{"key-encrypted.pem", "chain.pem", "key-encrypted.pem"},
+	}
+
+	for _, tt := range tests {
+		var key []byte
+		if tt.key != "" {
+			key = readTestdata(t, tt.key)
+		}
+		if _, err := Load(FormatAuto, readTestdata(t, tt.cert), key, "incorrecta"); !errors.Is(err, ErrIncorrectPassword) {
+			t.Errorf("%s: Load() error = %v; want ErrIncorrectPassword", tt.name, err)
+		}
+	}
+}
+
+func TestLoadPEMWithoutKey(t *testing.T) {
+	if _, err := LoadPEM(readTestdata(t, "chain.pem"), nil, ""); !errors.Is(err, ErrNoPrivateKey) {
+		t.Errorf("LoadPEM() error = %v; want ErrNoPrivateKey", err)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	cert, err := LoadPEM(readTestdata(t, "chain.pem"), readTestdata(t, "key.pem"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info := Inspect(cert.Leaf)
+	if info.RUC != "80069563" || info.DV != "1" {
+		t.Errorf("RUC = %q-%q; want 80069563-1", info.RUC, info.DV)
+	}
+	if info.Issuer != "CN=CA de Prueba,O=CA de Prueba,C=PY" || info.SerialNumber == "" {
+		t.Errorf("Inspect() = %+v", info)
+	}
+	if !info.ValidAt(info.NotBefore) || info.ValidAt(info.NotAfter.Add(time.Second)) {
+		t.Errorf("ValidAt() does not match the validity window %s - %s", info.NotBefore, info.NotAfter)
+	}
+
+	for serial, want := range map[string][2]string{
+		"RUC80069563-1": {"80069563", "1"},
+		"CI1234567":     {"1234567", ""},
+		"80012345-0":    {"80012345", "0"},
+		"PASAPORTE X1":  {"", ""},
+	} {
+		if ruc, dv := parseSubjectRUC(serial); ruc != want[0] || dv != want[1] {
+			t.Errorf("parseSubjectRUC(%q) = %q, %q; want %q, %q", serial, ruc, dv, want[0], want[1])
+		}
+	}
+}
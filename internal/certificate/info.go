@@ -0,0 +1,82 @@
+package certificate
+
+import (
+	"crypto/x509"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rodascaar/sifen-go-py/sifen/errors"
+)
+
+// Info describes a certificate as needed to check it against the emitter
+type Info struct {
+	Subject      string
+	Issuer       string
+	SerialNumber string // Hexadecimal serial of the certificate
+	NotBefore    time.Time
+	NotAfter     time.Time
+
+	// RUC and DV taken from the subject serialNumber attribute. Paraguayan CAs
+	// write "RUC80069563-1" for companies and "CI1234567" for individuals,
+	// whose RUC is the identity card number.
+	RUC string
+	DV  string
+}
+
+// Inspect returns the Info of cert
+func Inspect(cert *x509.Certificate) Info {
+	info := Info{
+		Subject:      cert.Subject.String(),
+		Issuer:       cert.Issuer.String(),
+		SerialNumber: strings.ToUpper(cert.SerialNumber.Text(16)),
+		NotBefore:    cert.NotBefore,
+		NotAfter:     cert.NotAfter,
+	}
+	info.RUC, info.DV = parseSubjectRUC(cert.Subject.SerialNumber)
+	return info
+}
+
+// ValidAt reports whether t is within the validity window
+func (i Info) ValidAt(t time.Time) bool {
+	return !t.Before(i.NotBefore) && !t.After(i.NotAfter)
+}
+
+// CheckValidity returns errors.ErrCertificadoExpirado when t is outside the validity window
+func (i Info) CheckValidity(t time.Time) error {
+	if i.ValidAt(t) {
+		return nil
+	}
+	err := errors.ErrCertificadoExpirado.WithCause(nil).
+		WithContext("subject", i.Subject).
+		WithContext("not_before", i.NotBefore).
+		WithContext("not_after", i.NotAfter)
+	if t.Before(i.NotBefore) {
+		err.Message = fmt.Sprintf("Certificado aún no vigente (desde %s)", i.NotBefore.Format(time.RFC3339))
+	}
+	return err
+}
+
+// DaysLeft returns the number of whole days from t until the certificate expires
+func (i Info) DaysLeft(t time.Time) int {
+	return int(i.NotAfter.Sub(t) / (24 * time.Hour))
+}
+
+func parseSubjectRUC(serial string) (ruc, dv string) {
+	value := strings.ToUpper(strings.TrimSpace(serial))
+	for _, prefix := range []string{"RUC", "CI"} {
+		if strings.HasPrefix(value, prefix) {
+			value = strings.TrimSpace(strings.TrimPrefix(value, prefix))
+			break
+		}
+	}
+	if i := strings.IndexByte(value, '-'); i >= 0 {
+		value, dv = value[:i], value[i+1:]
+	}
+	for _, r := range value + dv {
+		if r < '0' || r > '9' {
+			return "", ""
+		}
+	}
+	return value, dv
+}
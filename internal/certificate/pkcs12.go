@@ -0,0 +1,25 @@
+package certificate
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// LoadPKCS12 decodes a PFX file holding a private key, its certificate and
+// optionally the issuing chain. Besides the legacy SHA-1/3DES/RC2 files it
+// accepts the PBES2 (AES) encryption and SHA-2 integrity used by current
+// OpenSSL and Windows exports. Only DER encoded files are supported.
+func LoadPKCS12(data []byte, password string) (tls.Certificate, error) {
+	key, leaf, chain, err := pkcs12.DecodeChain(data, password)
+	if err != nil {
+		if errors.Is(err, pkcs12.ErrIncorrectPassword) || errors.Is(err, pkcs12.ErrDecryption) {
+			return tls.Certificate{}, ErrIncorrectPassword
+		}
+		return tls.Certificate{}, fmt.Errorf("certificate: %w", err)
+	}
+	return newCertificate(key, append([]*x509.Certificate{leaf}, chain...))
+}
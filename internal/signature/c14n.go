@@ -0,0 +1,72 @@
+package signature
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+
+	"github.com/beevik/etree"
+	"github.com/ucarion/c14n"
+)
+
+// canonicalize returns the Exclusive C14N form of elem as it appears in its
+// document, so the namespaces declared on its ancestors (e.g. the default
+// namespace of rDE) stay in scope. exclude, when it is a descendant of elem, is
+// left out of the output as the enveloped-signature transform requires.
+func canonicalize(elem *etree.Element, exclude *etree.Element) ([]byte, error) {
+	// Child indexes from elem down to exclude, to find it again in the copy
+	var path []int
+	if exclude != nil {
+		e := exclude
+		for e != nil && e != elem {
+			path = append([]int{e.Index()}, path...)
+			e = e.Parent()
+		}
+		if e == nil {
+			path = nil // not a descendant
+		}
+	}
+
+	cp := elem.Copy()
+	if len(path) > 0 {
+		parent := cp
+		for _, i := range path[:len(path)-1] {
+			parent = parent.Child[i].(*etree.Element)
+		}
+		parent.RemoveChildAt(path[len(path)-1])
+	}
+
+	// Bring the in-scope declarations onto the copy; Exclusive C14N only
+	// renders the ones that are visibly used
+	declared := map[string]bool{}
+	for _, attr := range cp.Attr {
+		if isNamespaceDecl(attr) {
+			declared[attr.FullKey()] = true
+		}
+	}
+	for anc := elem.Parent(); anc != nil; anc = anc.Parent() {
+		for _, attr := range anc.Attr {
+			if isNamespaceDecl(attr) && !declared[attr.FullKey()] {
+				declared[attr.FullKey()] = true
+				cp.CreateAttr(attr.FullKey(), attr.Value)
+			}
+		}
+	}
+
+	doc := etree.NewDocument()
+	doc.SetRoot(cp)
+	raw, err := doc.WriteToBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	canonical, err := c14n.Canonicalize(xml.NewDecoder(bytes.NewReader(raw)))
+	if err != nil {
+		return nil, fmt.Errorf("c14n failed: %w", err)
+	}
+	return canonical, nil
+}
+
+func isNamespaceDecl(attr etree.Attr) bool {
+	return attr.Space == "xmlns" || (attr.Space == "" && attr.Key == "xmlns")
+}
@@ -0,0 +1,43 @@
+package signature
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"fmt"
+	"io"
+)
+
+// DigestSigner is the minimal contract for keys that never leave a PKCS#11
+// token or a cloud KMS: it receives the SHA-256 digest of the canonical
+// SignedInfo and returns the RSASSA-PKCS1-v1_5 signature, which is what
+// operations such as AWS KMS Sign (RSASSA_PKCS1_V1_5_SHA_256, MessageType
+// DIGEST) or PKCS#11 CKM_RSA_PKCS over a DigestInfo produce.
+type DigestSigner interface {
+	// Public returns the RSA public key matching the signing certificate
+	Public() crypto.PublicKey
+	// SignDigest signs a SHA-256 digest with RSASSA-PKCS1-v1_5
+	SignDigest(digest []byte) ([]byte, error)
+}
+
+// FromDigestSigner adapts ds to crypto.Signer so it can be used with NewKeySigner
+func FromDigestSigner(ds DigestSigner) crypto.Signer {
+	return digestSigner{ds}
+}
+
+type digestSigner struct {
+	ds DigestSigner
+}
+
+func (d digestSigner) Public() crypto.PublicKey {
+	return d.ds.Public()
+}
+
+func (d digestSigner) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if opts == nil || opts.HashFunc() != crypto.SHA256 {
+		return nil, fmt.Errorf("digest signer only supports SHA-256")
+	}
+	if _, pss := opts.(*rsa.PSSOptions); pss {
+		return nil, fmt.Errorf("digest signer only supports RSASSA-PKCS1-v1_5")
+	}
+	return d.ds.SignDigest(digest)
+}
@@ -7,46 +7,124 @@ import (
 	"crypto/rsa"
 	"crypto/sha256"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/xml"
 	"fmt"
+	"time"
 
 	"github.com/beevik/etree"
+	"github.com/rodascaar/sifen-go-py/internal/certificate"
+	"github.com/rodascaar/sifen-go-py/sifen/errors"
 	"github.com/ucarion/c14n"
 )
 
-// Signer handles XML Digital Signature logic
+// Placement selects where Sign inserts the Signature element
+type Placement int
+
+const (
+	// PlaceAfter inserts the Signature right after the signed element within
+	// its parent. The v150 schema expects it there: inside rDE after DE and
+	// before gCamFuFD, and inside rGesEve after rEve.
+	PlaceAfter Placement = iota
+	// PlaceInside appends the Signature as the last child of the signed element
+	PlaceInside
+)
+
+// Signer handles XML Digital Signature logic. The private key is only used
+// through crypto.Signer, so it may live in memory, in a PKCS#11 token or in a
+// cloud KMS (see DigestSigner).
 type Signer struct {
-	Cert tls.Certificate
+	key   crypto.Signer
+	chain []*x509.Certificate
 }
 
-func NewSigner(cert tls.Certificate) *Signer {
-	return &Signer{Cert: cert}
+// NewSigner creates a signer from a certificate loaded with its private key
+func NewSigner(cert tls.Certificate) (*Signer, error) {
+	key, ok := cert.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, errors.ErrFirmaInvalida.WithCause(fmt.Errorf("private key %T does not implement crypto.Signer", cert.PrivateKey))
+	}
+
+	chain := make([]*x509.Certificate, 0, len(cert.Certificate))
+	for i, der := range cert.Certificate {
+		if i == 0 && cert.Leaf != nil {
+			chain = append(chain, cert.Leaf)
+			continue
+		}
+		parsed, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, errors.ErrCertificadoNoEncontrado.WithCause(err)
+		}
+		chain = append(chain, parsed)
+	}
+	return NewKeySigner(key, chain)
+}
+
+// NewKeySigner creates a signer from any crypto.Signer and its certificate
+// chain, leaf first. SIFEN requires RSA-SHA256, so the key must be RSA and
+// match the leaf certificate.
+func NewKeySigner(key crypto.Signer, chain []*x509.Certificate) (*Signer, error) {
+	if len(chain) == 0 {
+		return nil, errors.ErrCertificadoNoEncontrado.WithCause(fmt.Errorf("empty certificate chain"))
+	}
+
+	public, ok := key.Public().(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.ErrClavePrivadaNoRSA.WithCause(fmt.Errorf("public key is %T", key.Public()))
+	}
+	if !public.Equal(chain[0].PublicKey) {
+		return nil, errors.ErrFirmaInvalida.WithCause(fmt.Errorf("private key does not match the certificate %s", chain[0].Subject))
+	}
+
+	return &Signer{key: key, chain: chain}, nil
 }
 
+// Certificate returns the signing certificate
+func (s *Signer) Certificate() *x509.Certificate {
+	return s.chain[0]
+}
+
+// Chain returns the signing certificate followed by its issuers
+func (s *Signer) Chain() []*x509.Certificate {
+	return s.chain
+}
+
+// Sign signs the element with the given Id and places the Signature after it
+// (PlaceAfter). It fails with errors.ErrCertificadoExpirado when the
+// certificate is not valid at the time of signing.
 func (s *Signer) Sign(xmlBytes []byte, elementID string) ([]byte, error) {
+	return s.SignAt(xmlBytes, elementID, PlaceAfter)
+}
+
+// SignAt is like Sign but inserts the Signature at the given placement
+func (s *Signer) SignAt(xmlBytes []byte, elementID string, placement Placement) ([]byte, error) {
+	if err := certificate.Inspect(s.chain[0]).CheckValidity(time.Now()); err != nil {
+		return nil, err
+	}
+
 	doc := etree.NewDocument()
 	if err := doc.ReadFromBytes(xmlBytes); err != nil {
 		return nil, fmt.Errorf("failed to parse xml: %w", err)
 	}
 
 	// 1. Find the element to sign
-	// XPath is tricky, we'll traverse.
 	// We assume ID attribute is named "Id" (case sensitive for Sifen).
-	elem := doc.FindElement(fmt.Sprintf("//*[@Id='%s']", elementID))
-	if elem == nil {
+	elems := elementsByID(doc.Root(), elementID)
+	if len(elems) == 0 {
 		return nil, fmt.Errorf("element with Id='%s' not found", elementID)
 	}
+	elem := elems[0]
+	parent := elem.Parent()
+	if placement == PlaceAfter && (parent == nil || parent.Parent() == nil) {
+		return nil, fmt.Errorf("element with Id='%s' is the document root; the Signature cannot be placed after it", elementID)
+	}
 
-	// 2. Canonicalize the element (Exclusive C14N)
-	docTemp := etree.NewDocument()
-	docTemp.SetRoot(elem.Copy())
-	xmlBytesTemp, _ := docTemp.WriteToBytes()
-
-	decoder := xml.NewDecoder(bytes.NewReader(xmlBytesTemp))
-	canonicalBytes, err := c14n.Canonicalize(decoder)
+	// 2. Canonicalize the element (Exclusive C14N) with the namespaces it
+	// inherits in the document, as Verify and SIFEN do
+	canonicalBytes, err := canonicalize(elem, nil)
 	if err != nil {
-		return nil, fmt.Errorf("c14n failed: %w", err)
+		return nil, err
 	}
 
 	// 3. Calculate Digest
@@ -57,25 +135,25 @@ func (s *Signer) Sign(xmlBytes []byte, elementID string) ([]byte, error) {
 
 	// 4. Construct SignedInfo
 	signedInfo := etree.NewElement("SignedInfo")
-	signedInfo.CreateAttr("xmlns", "http://www.w3.org/2000/09/xmldsig#")
+	signedInfo.CreateAttr("xmlns", NamespaceDSig)
 
 	c14nMethod := signedInfo.CreateElement("CanonicalizationMethod")
-	c14nMethod.CreateAttr("Algorithm", "http://www.w3.org/2001/10/xml-exc-c14n#")
+	c14nMethod.CreateAttr("Algorithm", AlgorithmExcC14N)
 
 	sigMethod := signedInfo.CreateElement("SignatureMethod")
-	sigMethod.CreateAttr("Algorithm", "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256")
+	sigMethod.CreateAttr("Algorithm", AlgorithmRSASHA256)
 
 	ref := signedInfo.CreateElement("Reference")
 	ref.CreateAttr("URI", "#"+elementID)
 
 	transforms := ref.CreateElement("Transforms")
 	trans1 := transforms.CreateElement("Transform")
-	trans1.CreateAttr("Algorithm", "http://www.w3.org/2000/09/xmldsig#enveloped-signature")
+	trans1.CreateAttr("Algorithm", AlgorithmEnvelopedSign)
 	trans2 := transforms.CreateElement("Transform")
-	trans2.CreateAttr("Algorithm", "http://www.w3.org/2001/10/xml-exc-c14n#")
+	trans2.CreateAttr("Algorithm", AlgorithmExcC14N)
 
 	digestMethod := ref.CreateElement("DigestMethod")
-	digestMethod.CreateAttr("Algorithm", "http://www.w3.org/2001/04/xmlenc#sha256")
+	digestMethod.CreateAttr("Algorithm", AlgorithmSHA256)
 
 	digestVal := ref.CreateElement("DigestValue")
 	digestVal.SetText(digestBase64)
@@ -92,26 +170,21 @@ func (s *Signer) Sign(xmlBytes []byte, elementID string) ([]byte, error) {
 	}
 
 	// 6. Sign SignedInfo digest
-	// We need RSA Private Key
-	privKey, ok := s.Cert.PrivateKey.(*rsa.PrivateKey)
-	if !ok {
-		return nil, fmt.Errorf("private key is not RSA")
-	}
-
 	// Hash the canonical SignedInfo
 	siHasher := sha256.New()
 	siHasher.Write(canonicalSiBytes)
 	siDigest := siHasher.Sum(nil)
 
-	signature, err := rsa.SignPKCS1v15(rand.Reader, privKey, crypto.SHA256, siDigest)
+	// crypto.SHA256 as options selects RSASSA-PKCS1-v1_5, as rsa-sha256 requires
+	signature, err := s.key.Sign(rand.Reader, siDigest, crypto.SHA256)
 	if err != nil {
-		return nil, fmt.Errorf("rsa sign failed: %w", err)
+		return nil, errors.ErrFirmaInvalida.WithCause(err)
 	}
 	signatureBase64 := base64.StdEncoding.EncodeToString(signature)
 
 	// 7. Construct Signature Element
 	signatureElem := etree.NewElement("Signature")
-	signatureElem.CreateAttr("xmlns", "http://www.w3.org/2000/09/xmldsig#")
+	signatureElem.CreateAttr("xmlns", NamespaceDSig)
 
 	signatureElem.AddChild(signedInfo) // The one we computed digest for
 
@@ -122,12 +195,16 @@ func (s *Signer) Sign(xmlBytes []byte, elementID string) ([]byte, error) {
 	x509DataElem := keyInfoElem.CreateElement("X509Data")
 	x509CertElem := x509DataElem.CreateElement("X509Certificate")
 
-	// Get first leaf cert
-	leafCert := s.Cert.Certificate[0]
-	x509CertElem.SetText(base64.StdEncoding.EncodeToString(leafCert))
+	// Only the leaf: SIFEN identifies the signer by a single X509Certificate
+	x509CertElem.SetText(base64.StdEncoding.EncodeToString(s.chain[0].Raw))
 
-	// 8. Append Signature to Root (or doc)
-	elem.AddChild(signatureElem)
+	// 8. Insert the Signature
+	switch placement {
+	case PlaceInside:
+		elem.AddChild(signatureElem)
+	default:
+		parent.InsertChildAt(elem.Index()+1, signatureElem)
+	}
 
 	// Return the full XML
 	return doc.WriteToBytes()
@@ -0,0 +1,58 @@
+package signature
+
+import (
+	"testing"
+
+	"github.com/beevik/etree"
+)
+
+func TestSignPlacement(t *testing.T) {
+	signer := newTestSigner(t)
+	const id = "01800695631001001000000612024123017595714694"
+	input := `<rDE xmlns="http://ekuatia.set.gov.py/sifen/xsd"><dVerFor>150</dVerFor>` +
+		`<DE Id="` + id + `"><dDVId>4</dDVId></DE><gCamFuFD><dCarQR>https://ekuatia.set.gov.py/consultas/qr?</dCarQR></gCamFuFD></rDE>`
+
+	tests := []struct {
+		name      string
+		placement Placement
+		parent    string
+		want      []string // child elements of parent
+	}{
+		{"after", PlaceAfter, "rDE", []string{"dVerFor", "DE", "Signature", "gCamFuFD"}},
+		{"inside", PlaceInside, "rDE/DE", []string{"dDVId", "Signature"}},
+	}
+
+	for _, tt := range tests {
+		signed, err := signer.SignAt([]byte(input), id, tt.placement)
+		if err != nil {
+			t.Fatalf("%s: SignAt() error = %v", tt.name, err)
+		}
+
+		doc := etree.NewDocument()
+		if err := doc.ReadFromBytes(signed); err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, e := range doc.FindElement(tt.parent).ChildElements() {
+			got = append(got, e.Tag)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: %s children = %v; want %v", tt.name, tt.parent, got, tt.want)
+		} else {
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("%s: %s children = %v; want %v", tt.name, tt.parent, got, tt.want)
+					break
+				}
+			}
+		}
+
+		if _, err := Verify(signed); err != nil {
+			t.Errorf("%s: Verify() error = %v", tt.name, err)
+		}
+	}
+
+	if _, err := signer.Sign([]byte(`<DE Id="`+id+`"/>`), id); err == nil {
+		t.Error("Sign() of the root element with PlaceAfter succeeded; want an error")
+	}
+}
@@ -0,0 +1,287 @@
+package signature
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/beevik/etree"
+	"github.com/rodascaar/sifen-go-py/sifen/errors"
+)
+
+// Algorithms used by SIFEN signatures
+const (
+	NamespaceDSig          = "http://www.w3.org/2000/09/xmldsig#"
+	AlgorithmExcC14N       = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	AlgorithmRSASHA256     = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+	AlgorithmSHA256        = "http://www.w3.org/2001/04/xmlenc#sha256"
+	AlgorithmEnvelopedSign = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+)
+
+// Result is the outcome of verifying one Signature element
+type Result struct {
+	ReferenceID string            // Id of the signed element
+	Element     string            // Tag of the signed element, e.g. "DE" or "rEve"
+	DigestValue []byte            // Digest recomputed over the signed element
+	Certificate *x509.Certificate // Signer certificate embedded in KeyInfo
+	Err         error             // nil when both the digest and the signature value verify
+}
+
+// Valid reports whether the signature verified
+func (r Result) Valid() bool {
+	return r.Err == nil
+}
+
+// Verify checks every Signature element in xmlBytes: it recomputes the
+// Exclusive C14N SHA-256 digest of the referenced element and checks the
+// RSA-SHA256 SignatureValue with the embedded X509Certificate. The referenced
+// Id must be unique in the document and belong to the Signature's preceding
+// sibling (DE in rDE, rEve in rGesEve) or to its parent.
+//
+// It returns one Result per signature. err is errors.ErrFirmaNoEncontrada
+// when the document has no signature and errors.ErrFirmaNoValida when any
+// signature fails. The certificate is not checked against a trust store;
+// callers decide whether the signer is acceptable.
+func Verify(xmlBytes []byte) ([]Result, error) {
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(xmlBytes); err != nil {
+		return nil, fmt.Errorf("failed to parse xml: %w", err)
+	}
+
+	var signatures []*etree.Element
+	var find func(e *etree.Element)
+	find = func(e *etree.Element) {
+		for _, child := range e.ChildElements() {
+			if child.Tag == "Signature" && child.NamespaceURI() == NamespaceDSig {
+				signatures = append(signatures, child)
+				continue
+			}
+			find(child)
+		}
+	}
+	find(&doc.Element)
+	if len(signatures) == 0 {
+		return nil, errors.ErrFirmaNoEncontrada.WithCause(nil)
+	}
+
+	results := make([]Result, 0, len(signatures))
+	var failed error
+	for _, sig := range signatures {
+		result := verifySignature(doc, sig)
+		if result.Err != nil && failed == nil {
+			failed = errors.ErrFirmaNoValida.WithCause(result.Err).WithContext("id", result.ReferenceID)
+		}
+		results = append(results, result)
+	}
+	return results, failed
+}
+
+func verifySignature(doc *etree.Document, sig *etree.Element) Result {
+	var result Result
+
+	signedInfo := child(sig, "SignedInfo")
+	if signedInfo == nil {
+		result.Err = fmt.Errorf("missing SignedInfo")
+		return result
+	}
+	if alg := algorithm(child(signedInfo, "CanonicalizationMethod")); alg != AlgorithmExcC14N {
+		result.Err = fmt.Errorf("unsupported canonicalization %q", alg)
+		return result
+	}
+	if alg := algorithm(child(signedInfo, "SignatureMethod")); alg != AlgorithmRSASHA256 {
+		result.Err = fmt.Errorf("unsupported signature method %q", alg)
+		return result
+	}
+
+	var refs []*etree.Element
+	for _, e := range signedInfo.ChildElements() {
+		if e.Tag == "Reference" {
+			refs = append(refs, e)
+		}
+	}
+	if len(refs) != 1 {
+		result.Err = fmt.Errorf("expected one Reference, found %d", len(refs))
+		return result
+	}
+	ref := refs[0]
+
+	uri := ref.SelectAttrValue("URI", "")
+	if !strings.HasPrefix(uri, "#") {
+		result.Err = fmt.Errorf("unsupported Reference URI %q", uri)
+		return result
+	}
+	result.ReferenceID = uri[1:]
+
+	// The Id must be unique and the element must be the one the Signature is
+	// attached to, so a copy elsewhere in the document (signature wrapping)
+	// cannot be verified in place of the element the application reads
+	targets := elementsByID(doc.Root(), result.ReferenceID)
+	switch len(targets) {
+	case 0:
+		result.Err = fmt.Errorf("element with Id='%s' not found", result.ReferenceID)
+		return result
+	case 1:
+	default:
+		result.Err = fmt.Errorf("Id='%s' appears %d times", result.ReferenceID, len(targets))
+		return result
+	}
+	target := targets[0]
+	if target != sig.Parent() && target != previousElement(sig) {
+		result.Err = fmt.Errorf("element with Id='%s' is neither the parent nor the preceding sibling of the Signature", result.ReferenceID)
+		return result
+	}
+	result.Element = target.Tag
+
+	var exclude *etree.Element
+	if transforms := child(ref, "Transforms"); transforms != nil {
+		for _, t := range transforms.ChildElements() {
+			switch alg := algorithm(t); alg {
+			case AlgorithmEnvelopedSign:
+				exclude = sig
+			case AlgorithmExcC14N:
+			default:
+				result.Err = fmt.Errorf("unsupported transform %q", alg)
+				return result
+			}
+		}
+	}
+	if alg := algorithm(child(ref, "DigestMethod")); alg != AlgorithmSHA256 {
+		result.Err = fmt.Errorf("unsupported digest method %q", alg)
+		return result
+	}
+
+	cert, err := keyInfoCertificate(sig)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Certificate = cert
+
+	// 1. Digest of the referenced element
+	canonical, err := canonicalize(target, exclude)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	digest := sha256.Sum256(canonical)
+	result.DigestValue = digest[:]
+
+	expected, err := decodeBase64(child(ref, "DigestValue"))
+	if err != nil {
+		result.Err = fmt.Errorf("invalid DigestValue: %w", err)
+		return result
+	}
+	if !bytes.Equal(expected, result.DigestValue) {
+		result.Err = fmt.Errorf("digest mismatch for Id='%s'", result.ReferenceID)
+		return result
+	}
+
+	// 2. Signature over the canonical SignedInfo
+	signatureValue, err := decodeBase64(child(sig, "SignatureValue"))
+	if err != nil {
+		result.Err = fmt.Errorf("invalid SignatureValue: %w", err)
+		return result
+	}
+	canonicalSI, err := canonicalize(signedInfo, nil)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	siDigest := sha256.Sum256(canonicalSI)
+
+	public, ok := result.Certificate.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		result.Err = fmt.Errorf("certificate public key is %T, not RSA", result.Certificate.PublicKey)
+		return result
+	}
+	if err := rsa.VerifyPKCS1v15(public, crypto.SHA256, siDigest[:], signatureValue); err != nil {
+		result.Err = fmt.Errorf("signature value: %w", err)
+	}
+	return result
+}
+
+func keyInfoCertificate(sig *etree.Element) (*x509.Certificate, error) {
+	certElem := child(child(child(sig, "KeyInfo"), "X509Data"), "X509Certificate")
+	der, err := decodeBase64(certElem)
+	if err != nil {
+		return nil, fmt.Errorf("invalid X509Certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("invalid X509Certificate: %w", err)
+	}
+	return cert, nil
+}
+
+// elementsByID returns every element under root, root included, whose Id
+// attribute is id. The Id comes from the document or the caller, so it is
+// compared directly instead of being spliced into an etree path, where quotes
+// or brackets would change the path or make etree panic
+func elementsByID(root *etree.Element, id string) []*etree.Element {
+	if root == nil {
+		return nil
+	}
+	var found []*etree.Element
+	if root.SelectAttrValue("Id", "") == id {
+		found = append(found, root)
+	}
+	for _, c := range root.ChildElements() {
+		found = append(found, elementsByID(c, id)...)
+	}
+	return found
+}
+
+// previousElement returns the element sibling right before e, or nil
+func previousElement(e *etree.Element) *etree.Element {
+	parent := e.Parent()
+	if parent == nil {
+		return nil
+	}
+	var prev *etree.Element
+	for _, c := range parent.ChildElements() {
+		if c == e {
+			return prev
+		}
+		prev = c
+	}
+	return nil
+}
+
+// child returns the first child element of e with the given local name; nil-safe
+func child(e *etree.Element, tag string) *etree.Element {
+	if e == nil {
+		return nil
+	}
+	for _, c := range e.ChildElements() {
+		if c.Tag == tag {
+			return c
+		}
+	}
+	return nil
+}
+
+func algorithm(e *etree.Element) string {
+	if e == nil {
+		return ""
+	}
+	return e.SelectAttrValue("Algorithm", "")
+}
+
+// decodeBase64 decodes the text of e, ignoring the line breaks some signers insert
+func decodeBase64(e *etree.Element) ([]byte, error) {
+	if e == nil {
+		return nil, fmt.Errorf("element not found")
+	}
+	text := strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\n' || r == '\r' || r == '\t' {
+			return -1
+		}
+		return r
+	}, e.Text())
+	return base64.StdEncoding.DecodeString(text)
+}
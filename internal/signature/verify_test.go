@@ -0,0 +1,134 @@
+package signature
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	stderrors "errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/rodascaar/sifen-go-py/sifen/errors"
+)
+
+const testRDE = `<rDE xmlns="http://ekuatia.set.gov.py/sifen/xsd"><dVerFor>150</dVerFor>` +
+	`<DE Id="01800695631001001000000612024123017595714694"><dDVId>4</dDVId><gTotSub><dTotGralOpe>100000</dTotGralOpe></gTotSub></DE></rDE>`
+
+func newTestSigner(t *testing.T) *Signer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "FIRMANTE", SerialNumber: "RUC80069563-1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := NewKeySigner(key, []*x509.Certificate{cert})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+func TestVerifySignedDE(t *testing.T) {
+	signer := newTestSigner(t)
+	signed, err := signer.Sign([]byte(testRDE), "01800695631001001000000612024123017595714694")
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	results, err := Verify(signed)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if len(results) != 1 || !results[0].Valid() || results[0].Element != "DE" {
+		t.Fatalf("Verify() = %+v", results)
+	}
+	if !results[0].Certificate.Equal(signer.Certificate()) {
+		t.Errorf("Verify() certificate = %s; want the signer certificate", results[0].Certificate.Subject)
+	}
+
+	tampered := strings.Replace(string(signed), "<dTotGralOpe>100000<", "<dTotGralOpe>1000<", 1)
+	results, err = Verify([]byte(tampered))
+	if !stderrors.Is(err, errors.ErrFirmaNoValida) || results[0].Valid() {
+		t.Errorf("Verify(tampered) error = %v; want ErrFirmaNoValida", err)
+	}
+}
+
+func TestVerifyRejectsSignatureWrapping(t *testing.T) {
+	signer := newTestSigner(t)
+	signed, err := signer.Sign([]byte(testRDE), "01800695631001001000000612024123017595714694")
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	signedDE := string(signed)[strings.Index(string(signed), "<DE ") : strings.Index(string(signed), "</DE>")+len("</DE>")]
+	forged := strings.Replace(signedDE, "<dTotGralOpe>100000<", "<dTotGralOpe>1000<", 1)
+
+	tests := map[string]string{
+		// A second DE with the same Id after the signed one
+		"duplicated Id": strings.Replace(string(signed), "</rDE>", forged+"</rDE>", 1),
+		// The signed DE moved out of the way and a forged one without Id in its place
+		"moved element": strings.Replace(strings.Replace(string(signed), signedDE, strings.Replace(forged, ` Id="01800695631001001000000612024123017595714694"`, "", 1), 1),
+			"</rDE>", "<gOtros>"+signedDE+"</gOtros></rDE>", 1),
+	}
+	for name, doc := range tests {
+		results, err := Verify([]byte(doc))
+		if !stderrors.Is(err, errors.ErrFirmaNoValida) || len(results) != 1 || results[0].Valid() {
+			t.Errorf("%s: Verify() = %+v, %v; want ErrFirmaNoValida", name, results, err)
+		}
+	}
+}
+
+func TestHostileReferenceURI(t *testing.T) {
+	signer := newTestSigner(t)
+	signed, err := signer.Sign([]byte(testRDE), "01800695631001001000000612024123017595714694")
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	// Quotes and brackets in the Id must not reach an etree path, where they panic
+	hostile := strings.Replace(string(signed), `URI="#01800695631001001000000612024123017595714694"`, `URI="#a']"`, 1)
+	results, err := Verify([]byte(hostile))
+	if !stderrors.Is(err, errors.ErrFirmaNoValida) || len(results) != 1 || results[0].Valid() {
+		t.Errorf("Verify() = %+v, %v; want ErrFirmaNoValida", results, err)
+	}
+	if _, err := signer.Sign([]byte(testRDE), "a']"); err == nil {
+		t.Error("Sign() error = nil; want element not found")
+	}
+}
+
+func TestVerifyWithoutSignature(t *testing.T) {
+	if _, err := Verify([]byte(testRDE)); !stderrors.Is(err, errors.ErrFirmaNoEncontrada) {
+		t.Errorf("Verify() error = %v; want ErrFirmaNoEncontrada", err)
+	}
+}
+
+func TestCanonicalizeKeepsInheritedNamespace(t *testing.T) {
+	doc := etree.NewDocument()
+	if err := doc.ReadFromString(testRDE); err != nil {
+		t.Fatal(err)
+	}
+	canonical, err := canonicalize(doc.FindElement("//DE"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<DE xmlns="http://ekuatia.set.gov.py/sifen/xsd" Id="01800695631001001000000612024123017595714694">`
+	if !strings.HasPrefix(string(canonical), want) {
+		t.Errorf("canonicalize() = %s; want prefix %s", canonical, want)
+	}
+}
@@ -1,33 +1,65 @@
 package soap
 
 import (
-	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/base64"
-	"encoding/pem"
 	"encoding/xml"
+	stderrors "errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"time"
 
-	"golang.org/x/crypto/pkcs12"
+	"github.com/rodascaar/sifen-go-py/internal/certificate"
+	"github.com/rodascaar/sifen-go-py/sifen/errors"
 )
 
 // ClientConfig holds the necessary configuration for the SOAP client
 type ClientConfig struct {
-	TimeoutMs          int
-	UseClientCert      bool
-	ClientCertPath     string
+	// TimeoutMs bounds establishing the TCP connection
+	TimeoutMs int
+	// TLSHandshakeTimeoutMs bounds the TLS handshake (0 = TimeoutMs)
+	TLSHandshakeTimeoutMs int
+	// ReadTimeoutMs bounds the wait from the moment the request has been
+	// written until the whole response has been read (0 = no limit). With a
+	// Transport that is not an *http.Transport it runs from the moment the
+	// request is sent.
+	// Both TimeoutMs and ReadTimeoutMs can be overridden per request with WithTimeouts.
+	ReadTimeoutMs int
+
+	UseClientCert bool
+	// ClientCertFormat selects PFX or PEM; empty detects PEM by its markers
+	ClientCertFormat certificate.Format
+	// ClientCertPath is a file path, PEM text or Base64 content
+	ClientCertPath string
+	// ClientKeyPath holds the PEM private key when it is not in ClientCertPath
+	ClientKeyPath string
+	// ClientCertificate, when set, is presented instead of loading ClientCertPath
+	// (e.g. a certificate whose key is held by an HSM)
+	ClientCertificate  *tls.Certificate
 	ClientCertPassword string
 	UserAgent          string
+
+	// Transport is an optional custom transport (e.g. one with a corporate proxy).
+	// An *http.Transport is cloned and the client certificate is added to its
+	// TLS config. Any other RoundTripper is used as is, so NewClient rejects it
+	// when UseClientCert is set: wrap an *http.Transport that already presents
+	// the certificate and leave UseClientCert off instead.
+	Transport http.RoundTripper
+	// Middlewares run in order around every exchange, the first one outermost
+	Middlewares []Middleware
 }
 
 type Client struct {
 	httpClient *http.Client
 	config     *ClientConfig
 	cert       tls.Certificate
+	handler    Handler
+	// traced reports whether the transport is an *http.Transport, which fires
+	// the httptrace hooks that start the read timeout
+	traced bool
 }
 
 func (c *Client) GetCertificate() tls.Certificate {
@@ -35,54 +67,106 @@ func (c *Client) GetCertificate() tls.Certificate {
 }
 
 func NewClient(cfg *ClientConfig) (*Client, error) {
-	client := &http.Client{
-		Timeout: time.Duration(cfg.TimeoutMs) * time.Millisecond,
-	}
-
-	var parsedCert tls.Certificate
+	c := &Client{config: cfg}
 
-	if cfg.UseClientCert {
-		certData, err := loadCertificate(cfg.ClientCertPath)
+	if cfg.UseClientCert && cfg.ClientCertificate != nil {
+		c.cert = *cfg.ClientCertificate
+	} else if cfg.UseClientCert {
+		cert, err := loadClientCertificate(cfg)
 		if err != nil {
-			return nil, fmt.Errorf("failed to load certificate: %w", err)
+			return nil, err
 		}
+		c.cert = cert
+	}
 
-		// Use pkcs12.ToPEM to get blocks, then parse X509KeyPair
-		blocks, err := pkcs12.ToPEM(certData, cfg.ClientCertPassword)
-		if err != nil {
-			return nil, fmt.Errorf("failed to decode pkcs12: %w", err)
+	// No http.Client.Timeout: connect, TLS handshake and read timeouts are
+	// applied separately so they can be classified and overridden per request
+	var transport http.RoundTripper
+	if cfg.Transport == nil {
+		t := &http.Transport{
+			Proxy:               http.ProxyFromEnvironment,
+			DialContext:         c.dialContext,
+			TLSHandshakeTimeout: cfg.tlsHandshakeTimeout(),
+			IdleConnTimeout:     90 * time.Second,
 		}
-
-		var pemData []byte
-		for _, b := range blocks {
-			pemData = append(pemData, pem.EncodeToMemory(b)...)
+		if cfg.UseClientCert {
+			t.TLSClientConfig = &tls.Config{
+				Certificates: []tls.Certificate{c.cert},
+				MinVersion:   tls.VersionTLS12,
+				MaxVersion:   tls.VersionTLS12,
+			}
 		}
-
-		parsedCert, err = tls.X509KeyPair(pemData, pemData)
+		transport = t
+	} else {
+		rt, err := withClientCertificate(cfg.Transport, cfg.UseClientCert, c.cert)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create x509 key pair: %w", err)
+			return nil, err
 		}
+		transport = c.withTimeouts(rt)
+	}
 
-		tlsConfig := &tls.Config{
-			Certificates: []tls.Certificate{parsedCert},
-			MinVersion:   tls.VersionTLS12,
-			MaxVersion:   tls.VersionTLS12,
-		}
+	_, c.traced = transport.(*http.Transport)
+	c.httpClient = &http.Client{Transport: transport}
+	c.handler = chain(c.roundTrip, cfg.Middlewares)
+	return c, nil
+}
+
+// withClientCertificate returns rt configured to present cert. Only
+// *http.Transport can be configured; it is cloned so the caller's value is not
+// modified, and any other RoundTripper is an error when useCert is set.
+func withClientCertificate(rt http.RoundTripper, useCert bool, cert tls.Certificate) (http.RoundTripper, error) {
+	if !useCert {
+		return rt, nil
+	}
+	transport, ok := rt.(*http.Transport)
+	if !ok {
+		return nil, errors.ErrConfigInvalida.WithCause(fmt.Errorf("the client certificate cannot be added to a %T; only *http.Transport is supported", rt))
+	}
+
+	transport = transport.Clone()
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	transport.TLSClientConfig.Certificates = append(transport.TLSClientConfig.Certificates, cert)
+	if transport.TLSClientConfig.MinVersion == 0 {
+		transport.TLSClientConfig.MinVersion = tls.VersionTLS12
+	}
+	if transport.TLSClientConfig.MaxVersion == 0 {
+		transport.TLSClientConfig.MaxVersion = tls.VersionTLS12
+	}
+	return transport, nil
+}
 
-		transport := &http.Transport{
-			TLSClientConfig: tlsConfig,
+// loadClientCertificate reads and decodes the configured certificate and key
+func loadClientCertificate(cfg *ClientConfig) (tls.Certificate, error) {
+	certData, err := loadCertificate(cfg.ClientCertPath)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to load certificate: %w", err)
+	}
+
+	var keyData []byte
+	if cfg.ClientKeyPath != "" {
+		if keyData, err = loadCertificate(cfg.ClientKeyPath); err != nil {
+			return tls.Certificate{}, fmt.Errorf("failed to load private key: %w", err)
 		}
-		client.Transport = transport
 	}
 
-	return &Client{
-		httpClient: client,
-		config:     cfg,
-		cert:       parsedCert,
-	}, nil
+	cert, err := certificate.Load(cfg.ClientCertFormat, certData, keyData, cfg.ClientCertPassword)
+	if stderrors.Is(err, certificate.ErrIncorrectPassword) {
+		return tls.Certificate{}, errors.ErrContrasenaCertificado.WithCause(err)
+	}
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to decode certificate: %w", err)
+	}
+	return cert, nil
 }
 
 func loadCertificate(pathOrBase64 string) ([]byte, error) {
+	// PEM content given inline, e.g. from a secrets manager
+	if certificate.IsPEM([]byte(pathOrBase64)) {
+		return []byte(pathOrBase64), nil
+	}
+
 	// Try reading as file
 	if _, err := os.Stat(pathOrBase64); err == nil {
 		return ioutil.ReadFile(pathOrBase64)
@@ -92,7 +176,22 @@ func loadCertificate(pathOrBase64 string) ([]byte, error) {
 	return base64.StdEncoding.DecodeString(pathOrBase64)
 }
 
+// Send posts the payload wrapped in a SOAP envelope to url.
+// It is equivalent to SendContext with context.Background().
 func (c *Client) Send(url string, payload interface{}) ([]byte, error) {
+	return c.SendContext(context.Background(), url, payload)
+}
+
+// SendContext posts the payload wrapped in a SOAP envelope to url.
+// The request is bound to ctx, so cancelling ctx or reaching its deadline
+// aborts the in-flight HTTP exchange.
+//
+// Transport failures and non-200 responses are returned as *errors.SifenError
+// values (ErrTimeoutConexion, ErrTimeoutLectura, ErrConexionRechazada,
+// ErrTLSHandshake, ErrServidorSifen, ErrEstadoHTTP) with the original error as
+// Cause and the URL, HTTP status and body in Context. For non-200 responses the
+// body is returned as well.
+func (c *Client) SendContext(ctx context.Context, url string, payload interface{}) ([]byte, error) {
 	// Construct SOAP Envelope
 	envelope := NewEnvelope(payload)
 
@@ -101,32 +200,26 @@ func (c *Client) Send(url string, payload interface{}) ([]byte, error) {
 		return nil, fmt.Errorf("failed to marshal soap envelope: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(reqBody))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	header := make(http.Header)
+	header.Set("Content-Type", "application/xml; charset=utf-8")
 	userAgent := c.config.UserAgent
 	if userAgent == "" {
 		userAgent = "rshk-jsifenlib-go"
 	}
-	req.Header.Set("User-Agent", userAgent)
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
+	header.Set("User-Agent", userAgent)
 
-	body, err := ioutil.ReadAll(resp.Body)
+	resp, err := c.handler(ctx, &Request{
+		URL:      url,
+		Envelope: reqBody,
+		Header:   header,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return body, fmt.Errorf("sifen returned status %d: %s", resp.StatusCode, string(body))
+		return resp.Body, classifyStatus(resp.StatusCode, resp.Body, url)
 	}
 
-	return body, nil
+	return resp.Body, nil
 }
@@ -0,0 +1,317 @@
+package soap
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sifenerrors "github.com/rodascaar/sifen-go-py/sifen/errors"
+)
+
+type testPayload struct {
+	Value string `xml:"value"`
+}
+
+func TestSendContextCanceled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client, err := NewClient(&ClientConfig{TimeoutMs: 10000})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = client.SendContext(ctx, server.URL, testPayload{Value: "x"})
+	if err == nil {
+		t.Fatal("SendContext() expected error after deadline")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("SendContext() error = %v; want context.DeadlineExceeded", err)
+	}
+}
+
+func TestSendOK(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<ok/>"))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&ClientConfig{TimeoutMs: 10000})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	body, err := client.Send(server.URL, testPayload{Value: "x"})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if string(body) != "<ok/>" {
+		t.Errorf("Send() body = %q; want %q", body, "<ok/>")
+	}
+}
+
+func TestSendClassifiesConnectionRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	url := "http://" + listener.Addr().String()
+	listener.Close()
+
+	client, err := NewClient(&ClientConfig{TimeoutMs: 10000})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	_, err = client.Send(url, testPayload{Value: "x"})
+	if !errors.Is(err, sifenerrors.ErrConexionRechazada) {
+		t.Fatalf("Send() error = %v; want ErrConexionRechazada", err)
+	}
+	if !sifenerrors.IsRecoverable(err) {
+		t.Error("IsRecoverable() = false; want true")
+	}
+}
+
+func TestSendClassifiesReadTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client, err := NewClient(&ClientConfig{TimeoutMs: 10000, ReadTimeoutMs: 50})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	_, err = client.Send(server.URL, testPayload{Value: "x"})
+	if !errors.Is(err, sifenerrors.ErrTimeoutLectura) {
+		t.Fatalf("Send() error = %v; want ErrTimeoutLectura", err)
+	}
+}
+
+func TestSendReadTimeoutOverride(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte("<ok/>"))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&ClientConfig{TimeoutMs: 10000, ReadTimeoutMs: 20})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	if _, err := client.Send(server.URL, testPayload{Value: "x"}); !errors.Is(err, sifenerrors.ErrTimeoutLectura) {
+		t.Fatalf("Send() error = %v; want ErrTimeoutLectura", err)
+	}
+
+	ctx := WithTimeouts(context.Background(), Timeouts{Read: 5 * time.Second})
+	if _, err := client.SendContext(ctx, server.URL, testPayload{Value: "x"}); err != nil {
+		t.Fatalf("SendContext() with read override error = %v", err)
+	}
+}
+
+// roundTripperFunc is a custom transport that is not an *http.Transport
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestSendReadTimeoutCustomTransport(t *testing.T) {
+	// Never answers and never fires the httptrace hooks
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		<-r.Context().Done()
+		return nil, r.Context().Err()
+	})
+
+	client, err := NewClient(&ClientConfig{TimeoutMs: 10000, ReadTimeoutMs: 50, Transport: transport})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Send("http://sifen.invalid/de/ws/consultas/consulta.wsdl", testPayload{Value: "x"})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, sifenerrors.ErrTimeoutLectura) {
+			t.Fatalf("Send() error = %v; want ErrTimeoutLectura", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Send() with a custom transport ignored ReadTimeoutMs")
+	}
+}
+
+func TestSendClassifiesTLSHandshakeTimeout(t *testing.T) {
+	// Accepts TCP connections but never answers the TLS ClientHello
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	client, err := NewClient(&ClientConfig{TimeoutMs: 10000, TLSHandshakeTimeoutMs: 50})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	_, err = client.Send("https://"+listener.Addr().String(), testPayload{Value: "x"})
+	if !errors.Is(err, sifenerrors.ErrTimeoutConexion) {
+		t.Fatalf("Send() error = %v; want ErrTimeoutConexion", err)
+	}
+}
+
+func TestSendClassifiesHTTPStatus(t *testing.T) {
+	tests := []struct {
+		status   int
+		expected *sifenerrors.SifenError
+	}{
+		{http.StatusServiceUnavailable, sifenerrors.ErrServidorSifen},
+		{http.StatusNotFound, sifenerrors.ErrEstadoHTTP},
+	}
+
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+			w.Write([]byte("down"))
+		}))
+
+		client, err := NewClient(&ClientConfig{TimeoutMs: 10000})
+		if err != nil {
+			t.Fatalf("NewClient() error = %v", err)
+		}
+
+		body, err := client.Send(server.URL, testPayload{Value: "x"})
+		server.Close()
+
+		if !errors.Is(err, tt.expected) {
+			t.Errorf("status %d: Send() error = %v; want %s", tt.status, err, tt.expected.Code)
+			continue
+		}
+		se, _ := sifenerrors.AsSifenError(err)
+		if se.Context[ContextKeyHTTPStatus] != tt.status || se.Context[ContextKeyHTTPBody] != "down" {
+			t.Errorf("status %d: Context = %v", tt.status, se.Context)
+		}
+		if string(body) != "down" {
+			t.Errorf("status %d: body = %q; want %q", tt.status, body, "down")
+		}
+		if tt.expected.Code != "NET_005" && sifenerrors.IsRecoverable(err) {
+			t.Errorf("status %d: IsRecoverable() = true; want false", tt.status)
+		}
+	}
+}
+
+func TestMiddlewaresRunInOrder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("X-Trace")))
+	}))
+	defer server.Close()
+
+	var order []string
+	tag := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, req *Request) (*Response, error) {
+				order = append(order, name)
+				req.Header.Set("X-Trace", req.Header.Get("X-Trace")+name)
+				return next(ctx, req)
+			}
+		}
+	}
+
+	client, err := NewClient(&ClientConfig{
+		TimeoutMs:   10000,
+		Middlewares: []Middleware{tag("a"), tag("b")},
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	body, err := client.Send(server.URL, testPayload{Value: "x"})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if string(body) != "ab" || len(order) != 2 || order[0] != "a" {
+		t.Errorf("body = %q, order = %v; want \"ab\" and [a b]", body, order)
+	}
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	inject := func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			return &Response{StatusCode: http.StatusServiceUnavailable, Body: []byte("injected")}, nil
+		}
+	}
+
+	client, err := NewClient(&ClientConfig{TimeoutMs: 10000, Middlewares: []Middleware{inject}})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	_, err = client.Send("http://127.0.0.1:1", testPayload{Value: "x"})
+	if !errors.Is(err, sifenerrors.ErrServidorSifen) {
+		t.Errorf("Send() error = %v; want ErrServidorSifen", err)
+	}
+}
+
+func TestWithClientCertificateClonesTransport(t *testing.T) {
+	original := &http.Transport{}
+	cert := tls.Certificate{Certificate: [][]byte{{1}}}
+
+	rt, err := withClientCertificate(original, true, cert)
+	if err != nil {
+		t.Fatalf("withClientCertificate() error = %v", err)
+	}
+	transport, ok := rt.(*http.Transport)
+	if !ok {
+		t.Fatalf("withClientCertificate() returned %T; want *http.Transport", rt)
+	}
+	if transport == original || (original.TLSClientConfig != nil && len(original.TLSClientConfig.Certificates) > 0) {
+		t.Error("withClientCertificate() modified the caller's transport")
+	}
+	if len(transport.TLSClientConfig.Certificates) != 1 {
+		t.Errorf("Certificates = %d; want 1", len(transport.TLSClientConfig.Certificates))
+	}
+}
+
+func TestNewClientRejectsCertificateOnCustomTransport(t *testing.T) {
+	cert := tls.Certificate{Certificate: [][]byte{{1}}}
+	custom := roundTripperFunc(func(r *http.Request) (*http.Response, error) { return nil, errors.New("unused") })
+
+	_, err := NewClient(&ClientConfig{UseClientCert: true, ClientCertificate: &cert, Transport: custom})
+	if !errors.Is(err, sifenerrors.ErrConfigInvalida) {
+		t.Errorf("NewClient() error = %v; want ErrConfigInvalida", err)
+	}
+	if _, err := NewClient(&ClientConfig{Transport: custom}); err != nil {
+		t.Errorf("NewClient() without certificate error = %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package soap
+
+import (
+	"context"
+	"crypto/tls"
+	stderrors "errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+
+	"github.com/rodascaar/sifen-go-py/sifen/errors"
+)
+
+// Context keys set on errors returned by Send
+const (
+	ContextKeyURL        = "url"
+	ContextKeyHTTPStatus = "http_status"
+	ContextKeyHTTPBody   = "http_body"
+)
+
+// classifyTransportError maps an error from http.Client.Do (or from reading the
+// response body) to the matching predefined NET_* error, keeping err as cause.
+func classifyTransportError(err error, url string) error {
+	var classified *errors.SifenError
+
+	var certErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	var alertErr tls.AlertError
+	var opErr *net.OpError
+
+	switch {
+	case stderrors.Is(err, context.Canceled):
+		// Cancelled by the caller: not a SIFEN failure, do not classify as recoverable
+		classified = errors.NewNetworkError("request canceled", err)
+		classified.Recoverable = false
+	case stderrors.Is(err, syscall.ECONNREFUSED):
+		classified = errors.ErrConexionRechazada.WithCause(err)
+	case stderrors.As(err, &certErr), stderrors.As(err, &recordErr), stderrors.As(err, &alertErr):
+		classified = errors.ErrTLSHandshake.WithCause(err)
+	case isTimeout(err):
+		dialing := stderrors.As(err, &opErr) && opErr.Op == "dial"
+		if dialing || strings.Contains(err.Error(), "TLS handshake timeout") {
+			classified = errors.ErrTimeoutConexion.WithCause(err)
+		} else {
+			classified = errors.ErrTimeoutLectura.WithCause(err)
+		}
+	default:
+		classified = errors.NewNetworkError("request failed", err)
+	}
+
+	return classified.WithContext(ContextKeyURL, url)
+}
+
+// classifyStatus builds the error returned for a non-200 HTTP response
+func classifyStatus(statusCode int, body []byte, url string) error {
+	base := errors.ErrEstadoHTTP
+	if statusCode >= http.StatusInternalServerError {
+		base = errors.ErrServidorSifen
+	}
+
+	classified := base.WithCause(nil)
+	classified.Message = fmt.Sprintf("%s (HTTP %d)", classified.Message, statusCode)
+
+	return classified.
+		WithContext(ContextKeyURL, url).
+		WithContext(ContextKeyHTTPStatus, statusCode).
+		WithContext(ContextKeyHTTPBody, string(body))
+}
+
+func isTimeout(err error) bool {
+	if stderrors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return stderrors.As(err, &netErr) && netErr.Timeout()
+}
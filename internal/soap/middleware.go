@@ -0,0 +1,103 @@
+package soap
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptrace"
+	"time"
+)
+
+// Request is the outgoing SOAP call as seen by middlewares
+type Request struct {
+	URL      string
+	Envelope []byte      // Serialized SOAP envelope
+	Header   http.Header // HTTP headers to send; middlewares may add or change entries
+}
+
+// Response is the raw HTTP answer as seen by middlewares
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Handler performs a SOAP exchange. A non-nil error means no HTTP response was obtained.
+type Handler func(ctx context.Context, req *Request) (*Response, error)
+
+// Middleware wraps a Handler. Middlewares may inspect or modify the request,
+// short-circuit it with a synthetic response or error, or inspect the response
+// returned by next.
+type Middleware func(next Handler) Handler
+
+// chain builds the handler that runs middlewares in order around final:
+// the first middleware is the outermost one.
+func chain(final Handler, middlewares []Middleware) Handler {
+	h := final
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+// roundTrip is the innermost Handler: it performs the HTTP POST with httpClient
+func (c *Client) roundTrip(ctx context.Context, req *Request) (*Response, error) {
+	readTimeout := time.Duration(c.config.ReadTimeoutMs) * time.Millisecond
+	if t := timeoutsFrom(ctx); t.Read > 0 {
+		readTimeout = t.Read
+	}
+	if readTimeout > 0 {
+		// The timer starts once the request is written, so neither the
+		// connection setup nor the upload of large batches count as reading.
+		// A custom RoundTripper may never fire that hook, so there the timer
+		// starts before sending and the hook, if it fires, restarts it.
+		var cancel context.CancelCauseFunc
+		ctx, cancel = context.WithCancelCause(ctx)
+		defer cancel(nil)
+
+		timer := time.AfterFunc(readTimeout, func() { cancel(readTimeoutError{readTimeout}) })
+		if c.traced {
+			timer.Stop()
+		}
+		defer timer.Stop()
+
+		ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+			WroteRequest: func(httptrace.WroteRequestInfo) { timer.Reset(readTimeout) },
+		})
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", req.URL, bytes.NewReader(req.Envelope))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range req.Header {
+		httpReq.Header[key] = values
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, classifyTransportError(readTimeoutCause(ctx, err), req.URL)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, classifyTransportError(readTimeoutCause(ctx, err), req.URL)
+	}
+
+	return &Response{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+	}, nil
+}
+
+// readTimeoutCause returns the read timeout that cancelled ctx, if any, instead of err
+func readTimeoutCause(ctx context.Context, err error) error {
+	if cause, ok := context.Cause(ctx).(readTimeoutError); ok {
+		return cause
+	}
+	return err
+}
@@ -0,0 +1,80 @@
+package soap
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Timeouts overrides the client timeouts for a single request.
+// Zero values keep the client defaults.
+type Timeouts struct {
+	// Connect bounds establishing the TCP connection
+	Connect time.Duration
+	// Read bounds the wait from the moment the request has been written
+	// until the whole response has been read
+	Read time.Duration
+}
+
+type timeoutsKey struct{}
+
+// WithTimeouts returns a context whose requests use the given timeouts
+func WithTimeouts(ctx context.Context, t Timeouts) context.Context {
+	return context.WithValue(ctx, timeoutsKey{}, t)
+}
+
+func timeoutsFrom(ctx context.Context) Timeouts {
+	t, _ := ctx.Value(timeoutsKey{}).(Timeouts)
+	return t
+}
+
+// readTimeoutError is the cancellation cause of a request that exceeded its read timeout
+type readTimeoutError struct {
+	timeout time.Duration
+}
+
+func (e readTimeoutError) Error() string {
+	return fmt.Sprintf("no response within read timeout of %s", e.timeout)
+}
+
+func (e readTimeoutError) Timeout() bool   { return true }
+func (e readTimeoutError) Temporary() bool { return true }
+
+// dialContext dials with the connect timeout of the request, or the client default
+func (c *Client) dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	timeout := time.Duration(c.config.TimeoutMs) * time.Millisecond
+	if t := timeoutsFrom(ctx); t.Connect > 0 {
+		timeout = t.Connect
+	}
+	dialer := net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}
+	return dialer.DialContext(ctx, network, addr)
+}
+
+// tlsHandshakeTimeout returns the configured handshake timeout, defaulting to the connect timeout
+func (cfg *ClientConfig) tlsHandshakeTimeout() time.Duration {
+	if cfg.TLSHandshakeTimeoutMs > 0 {
+		return time.Duration(cfg.TLSHandshakeTimeoutMs) * time.Millisecond
+	}
+	return time.Duration(cfg.TimeoutMs) * time.Millisecond
+}
+
+// withTimeouts returns rt with the client dialer and TLS handshake timeout.
+// Only *http.Transport can be configured; it is cloned so the caller's value is
+// not modified, and dialers or timeouts already set by the caller are kept.
+func (c *Client) withTimeouts(rt http.RoundTripper) http.RoundTripper {
+	transport, ok := rt.(*http.Transport)
+	if !ok {
+		return rt
+	}
+
+	transport = transport.Clone()
+	if transport.DialContext == nil && transport.Dial == nil {
+		transport.DialContext = c.dialContext
+	}
+	if transport.TLSHandshakeTimeout == 0 {
+		transport.TLSHandshakeTimeout = c.config.tlsHandshakeTimeout()
+	}
+	return transport
+}
@@ -5,6 +5,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/rodascaar/sifen-go-py/sifen/types"
 )
 
 // LeftPad adds padding characters to the left of a string
@@ -217,90 +219,65 @@ func GenerateSecurityCode() string {
 
 // TotalsInput contains item data for totals calculation
 type TotalsInput struct {
-	PrecioUnitario float64
-	Cantidad       float64
-	Descuento      float64
-	TasaIVA        float64 // 0, 5, or 10
+	PrecioUnitario types.Decimal
+	Cantidad       types.Decimal
+	Descuento      types.Decimal
+	TasaIVA        int // 0, 5, or 10
 	EsExento       bool
 	EsExonerado    bool
 }
 
 // TotalsResult contains calculated totals
 type TotalsResult struct {
-	SubtotalExe    float64
-	SubtotalExo    float64
-	Subtotal5      float64
-	Subtotal10     float64
-	TotalBruto     float64
-	TotalDescuento float64
-	TotalNeto      float64
-	BaseGravada5   float64
-	BaseGravada10  float64
-	IVA5           float64
-	IVA10          float64
-	TotalIVA       float64
+	SubtotalExe    types.Decimal
+	SubtotalExo    types.Decimal
+	Subtotal5      types.Decimal
+	Subtotal10     types.Decimal
+	TotalBruto     types.Decimal
+	TotalDescuento types.Decimal
+	TotalNeto      types.Decimal
+	BaseGravada5   types.Decimal
+	BaseGravada10  types.Decimal
+	IVA5           types.Decimal
+	IVA10          types.Decimal
+	TotalIVA       types.Decimal
 }
 
-// CalculateTotals calculates document totals from items
+// CalculateTotals calculates document totals from items. Amounts are exact;
+// the taxable base is rounded to 8 decimals and the IVA is the remainder, so
+// base + IVA always equals the net amount.
 func CalculateTotals(items []TotalsInput) TotalsResult {
 	result := TotalsResult{}
+	cien := types.DecimalFromInt(100)
 
 	for _, item := range items {
-		bruto := item.PrecioUnitario * item.Cantidad
-		neto := bruto - item.Descuento
+		bruto := item.PrecioUnitario.Mul(item.Cantidad)
+		neto := bruto.Sub(item.Descuento)
 
-		result.TotalBruto += bruto
-		result.TotalDescuento += item.Descuento
-		result.TotalNeto += neto
+		result.TotalBruto = result.TotalBruto.Add(bruto)
+		result.TotalDescuento = result.TotalDescuento.Add(item.Descuento)
+		result.TotalNeto = result.TotalNeto.Add(neto)
 
 		if item.EsExento {
-			result.SubtotalExe += neto
+			result.SubtotalExe = result.SubtotalExe.Add(neto)
 		} else if item.EsExonerado {
-			result.SubtotalExo += neto
+			result.SubtotalExo = result.SubtotalExo.Add(neto)
 		} else {
 			switch item.TasaIVA {
 			case 5:
-				result.Subtotal5 += neto
-				result.BaseGravada5 += neto / 1.05
-				result.IVA5 += neto - (neto / 1.05)
+				base := neto.Mul(cien).Div(types.DecimalFromInt(105))
+				result.Subtotal5 = result.Subtotal5.Add(neto)
+				result.BaseGravada5 = result.BaseGravada5.Add(base)
+				result.IVA5 = result.IVA5.Add(neto.Sub(base))
 			case 10:
-				result.Subtotal10 += neto
-				result.BaseGravada10 += neto / 1.10
-				result.IVA10 += neto - (neto / 1.10)
+				base := neto.Mul(cien).Div(types.DecimalFromInt(110))
+				result.Subtotal10 = result.Subtotal10.Add(neto)
+				result.BaseGravada10 = result.BaseGravada10.Add(base)
+				result.IVA10 = result.IVA10.Add(neto.Sub(base))
 			}
 		}
 	}
 
-	result.TotalIVA = result.IVA5 + result.IVA10
+	result.TotalIVA = result.IVA5.Add(result.IVA10)
 	return result
 }
-
-// ============================================================================
-// QR Code Generation
-// ============================================================================
-
-// QRParams contains parameters for QR code URL generation
-type QRParams struct {
-	CDC          string
-	NroTimbrado  string
-	FechaEmision time.Time
-	NroItem      int
-	FechaFirma   time.Time
-	MontoTotal   float64
-	CSCid        string
-}
-
-// GenerateQRURL generates the URL for SIFEN QR code
-func GenerateQRURL(baseURL string, params QRParams) string {
-	// QR format: baseURL?nVersion=150&Id=CDC&dFeEmiDE=fecha...
-	fecha := params.FechaEmision.Format("2006-01-02T15:04:05")
-
-	return fmt.Sprintf("%snVersion=150&Id=%s&dFeEmiDE=%s&dRucRec=&dTotGralOpe=%.2f&dTotIVA=0&cItems=%d&DigesValue=&IdCSC=%s",
-		baseURL,
-		params.CDC,
-		fecha,
-		params.MontoTotal,
-		params.NroItem,
-		params.CSCid,
-	)
-}
@@ -3,6 +3,8 @@ package util
 import (
 	"testing"
 	"time"
+
+	"github.com/rodascaar/sifen-go-py/sifen/types"
 )
 
 func TestLeftPad(t *testing.T) {
@@ -162,27 +164,37 @@ func TestGenerateCDCValidation(t *testing.T) {
 
 func TestCalculateTotals(t *testing.T) {
 	items := []TotalsInput{
-		{PrecioUnitario: 100000, Cantidad: 2, Descuento: 0, TasaIVA: 10},
-		{PrecioUnitario: 50000, Cantidad: 1, Descuento: 5000, TasaIVA: 5},
-		{PrecioUnitario: 25000, Cantidad: 1, Descuento: 0, EsExento: true},
+		{PrecioUnitario: types.DecimalFromInt(100000), Cantidad: types.DecimalFromInt(2), TasaIVA: 10},
+		{PrecioUnitario: types.DecimalFromInt(50000), Cantidad: types.DecimalFromInt(1), Descuento: types.DecimalFromInt(5000), TasaIVA: 5},
+		{PrecioUnitario: types.DecimalFromInt(25000), Cantidad: types.DecimalFromInt(1), EsExento: true},
 	}
 
 	result := CalculateTotals(items)
 
 	// Total bruto should be 100000*2 + 50000*1 + 25000*1 = 275000
-	expectedBruto := 275000.0
-	if result.TotalBruto != expectedBruto {
-		t.Errorf("TotalBruto = %.2f; want %.2f", result.TotalBruto, expectedBruto)
+	if got := result.TotalBruto.String(); got != "275000" {
+		t.Errorf("TotalBruto = %s; want 275000", got)
 	}
 
 	// Total descuento should be 5000
-	if result.TotalDescuento != 5000.0 {
-		t.Errorf("TotalDescuento = %.2f; want 5000", result.TotalDescuento)
+	if got := result.TotalDescuento.String(); got != "5000" {
+		t.Errorf("TotalDescuento = %s; want 5000", got)
 	}
 
 	// Exento should be 25000
-	if result.SubtotalExe != 25000.0 {
-		t.Errorf("SubtotalExe = %.2f; want 25000", result.SubtotalExe)
+	if got := result.SubtotalExe.String(); got != "25000" {
+		t.Errorf("SubtotalExe = %s; want 25000", got)
+	}
+
+	// 200000 / 1.10 and 45000 / 1.05, with base + IVA equal to the net amount
+	if got := result.BaseGravada10.String(); got != "181818.18181818" {
+		t.Errorf("BaseGravada10 = %s; want 181818.18181818", got)
+	}
+	if got := result.IVA10.Add(result.BaseGravada10).String(); got != "200000" {
+		t.Errorf("BaseGravada10 + IVA10 = %s; want 200000", got)
+	}
+	if got := result.IVA5.String(); got != "2142.85714286" {
+		t.Errorf("IVA5 = %s; want 2142.85714286", got)
 	}
 }
 
@@ -192,26 +204,3 @@ func TestGenerateSecurityCode(t *testing.T) {
 		t.Errorf("GenerateSecurityCode() length = %d; want 9", len(code))
 	}
 }
-
-func TestGenerateQRURL(t *testing.T) {
-	params := QRParams{
-		CDC:          "01800695631001001000000612021112917595714694",
-		NroTimbrado:  "12345678",
-		FechaEmision: time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
-		NroItem:      5,
-		MontoTotal:   150000.00,
-		CSCid:        "0001",
-	}
-
-	baseURL := "https://ekuatia.set.gov.py/consultas/qr?"
-	url := GenerateQRURL(baseURL, params)
-
-	if url == "" {
-		t.Error("GenerateQRURL() returned empty string")
-	}
-
-	// Basic validation that URL contains expected components
-	if len(url) < 50 {
-		t.Error("GenerateQRURL() URL seems too short")
-	}
-}
@@ -0,0 +1,246 @@
+package sifen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rodascaar/sifen-go-py/internal/soap"
+)
+
+// ============================================================================
+// Auditoría de Intercambios SIFEN
+// ============================================================================
+
+// Resultados posibles de un intercambio auditado
+const (
+	AuditOutcomeOK    = "OK"    // Respuesta SIFEN recibida y decodificada
+	AuditOutcomeFault = "FAULT" // SIFEN respondió con un SOAP Fault
+	AuditOutcomeError = "ERROR" // Error de transporte, HTTP o decodificación
+)
+
+// AuditRecord registra un intercambio con SIFEN tal como se envió y recibió
+type AuditRecord struct {
+	Operation    Operacion
+	DId          int64
+	CDC          string   // CDC del DE o del documento referenciado por el evento
+	CDCs         []string // CDCs incluidos en un lote
+	Lote         string   // Número de lote
+	Timestamp    time.Time
+	Duration     time.Duration
+	Outcome      string
+	ResponseCode string // dCodRes o código del SOAP Fault
+	Error        string
+	URL          string
+	HTTPStatus   int
+	Request      []byte // Sobre SOAP enviado, byte a byte
+	Response     []byte // Cuerpo de la respuesta recibida
+}
+
+// AuditSink recibe un registro por cada intento de intercambio con SIFEN.
+// Un error del sink no interrumpe la operación.
+type AuditSink interface {
+	Record(rec *AuditRecord) error
+}
+
+// auditMeta identifica el documento o lote de un intercambio
+type auditMeta struct {
+	dId  int64
+	cdc  string
+	cdcs []string
+	lote string
+}
+
+// auditCapture guarda lo que efectivamente viajó por HTTP en un intercambio
+type auditCapture struct {
+	url      string
+	envelope []byte
+	status   int
+	body     []byte
+}
+
+type auditCaptureKey struct{}
+
+// auditMiddleware es el middleware más interno del cliente: registra el sobre
+// final, luego de que los middlewares del usuario lo hayan modificado
+func auditMiddleware(next soap.Handler) soap.Handler {
+	return func(ctx context.Context, req *soap.Request) (*soap.Response, error) {
+		capture, _ := ctx.Value(auditCaptureKey{}).(*auditCapture)
+		if capture != nil {
+			capture.url = req.URL
+			capture.envelope = req.Envelope
+		}
+		resp, err := next(ctx, req)
+		if capture != nil && resp != nil {
+			capture.status = resp.StatusCode
+			capture.body = resp.Body
+		}
+		return resp, err
+	}
+}
+
+// ============================================================================
+// FileAuditSink
+// ============================================================================
+
+// FileAuditSink guarda cada intercambio como un archivo JSON en Dir.
+//
+// Los registros se agrupan en un directorio por CDC (Dir/<cdc>/), por lote
+// (Dir/lote-<numero>/) o por operación para las que no refieren un documento.
+// Los lotes además dejan un archivo .ref en el directorio de cada CDC incluido,
+// de modo que FindByCDC encuentra tanto envíos individuales como por lote.
+// Cada archivo se escribe en forma atómica (archivo temporal + rename).
+type FileAuditSink struct {
+	Dir string
+}
+
+// NewFileAuditSink crea el directorio de auditoría si no existe
+func NewFileAuditSink(dir string) (*FileAuditSink, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("no se pudo crear el directorio de auditoría: %w", err)
+	}
+	return &FileAuditSink{Dir: dir}, nil
+}
+
+type auditFile struct {
+	Operation    Operacion `json:"operation"`
+	DId          int64     `json:"dId"`
+	CDC          string    `json:"cdc,omitempty"`
+	CDCs         []string  `json:"cdcs,omitempty"`
+	Lote         string    `json:"lote,omitempty"`
+	Timestamp    time.Time `json:"timestamp"`
+	DurationMs   int64     `json:"durationMs"`
+	Outcome      string    `json:"outcome"`
+	ResponseCode string    `json:"responseCode,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	URL          string    `json:"url"`
+	HTTPStatus   int       `json:"httpStatus,omitempty"`
+	Request      string    `json:"request"`
+	Response     string    `json:"response"`
+}
+
+// Record implementa AuditSink
+func (s *FileAuditSink) Record(rec *AuditRecord) error {
+	group := string(rec.Operation)
+	switch {
+	case rec.CDC != "":
+		group = rec.CDC
+	case rec.Lote != "":
+		group = "lote-" + rec.Lote
+	}
+
+	name := fmt.Sprintf("%s_%s_%d.json",
+		rec.Timestamp.UTC().Format("20060102T150405.000000000Z"), rec.Operation, rec.DId)
+	relPath := filepath.Join(safeName(group), name)
+
+	var buf strings.Builder
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(auditFile{
+		Operation:    rec.Operation,
+		DId:          rec.DId,
+		CDC:          rec.CDC,
+		CDCs:         rec.CDCs,
+		Lote:         rec.Lote,
+		Timestamp:    rec.Timestamp,
+		DurationMs:   rec.Duration.Milliseconds(),
+		Outcome:      rec.Outcome,
+		ResponseCode: rec.ResponseCode,
+		Error:        rec.Error,
+		URL:          rec.URL,
+		HTTPStatus:   rec.HTTPStatus,
+		Request:      string(rec.Request),
+		Response:     string(rec.Response),
+	}); err != nil {
+		return fmt.Errorf("error al serializar registro de auditoría: %w", err)
+	}
+
+	if err := writeFileAtomic(filepath.Join(s.Dir, relPath), []byte(buf.String())); err != nil {
+		return err
+	}
+
+	// Referencias desde cada CDC del lote al registro
+	if rec.CDC == "" {
+		for _, cdc := range rec.CDCs {
+			refPath := filepath.Join(s.Dir, safeName(cdc), strings.TrimSuffix(name, ".json")+".ref")
+			if err := writeFileAtomic(refPath, []byte(relPath)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// FindByCDC retorna las rutas de los registros de auditoría de un CDC,
+// incluyendo los lotes que lo contienen, ordenadas cronológicamente
+func (s *FileAuditSink) FindByCDC(cdc string) ([]string, error) {
+	dir := filepath.Join(s.Dir, safeName(cdc))
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	var paths []string
+	for _, entry := range entries {
+		switch filepath.Ext(entry.Name()) {
+		case ".json":
+			paths = append(paths, filepath.Join(dir, entry.Name()))
+		case ".ref":
+			target, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, filepath.Join(s.Dir, string(target)))
+		}
+	}
+	return paths, nil
+}
+
+// writeFileAtomic escribe data en un archivo temporal del mismo directorio y lo renombra
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("no se pudo crear %s: %w", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("no se pudo crear archivo temporal: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error al escribir %s: %w", path, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error al sincronizar %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// safeName deja solo caracteres seguros para un nombre de archivo
+func safeName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, s)
+}
@@ -0,0 +1,77 @@
+package sifen
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/rodascaar/sifen-go-py/sifen/models"
+	"github.com/rodascaar/sifen-go-py/sifen/types"
+)
+
+func TestFileAuditSinkRecordsExchanges(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "recibe-lote.wsdl") {
+			fmt.Fprintf(w, soapEnvelopeFmt, "<rRetEnviLoteDe><dCodRes>0300</dCodRes><dProtConsLot>777</dProtConsLot></rRetEnviLoteDe>")
+			return
+		}
+		fmt.Fprintf(w, soapEnvelopeFmt, "<rRetEnviDe><dCodRes>0260</dCodRes></rRetEnviDe>")
+	})
+
+	sink, err := NewFileAuditSink(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileAuditSink() error = %v", err)
+	}
+
+	base := newTestClient(t, handler, fastPolicy())
+	config := *base.GetConfig()
+	config.AuditSink = sink
+	client, err := NewSifenClient(&config)
+	if err != nil {
+		t.Fatalf("NewSifenClient() error = %v", err)
+	}
+	defer client.Close()
+
+	cdc := strings.Repeat("1", 44)
+	de := models.NewDE(cdc)
+	de.DE.GTimb.ITiDE = types.TTiDE_FacturaElectronica
+	if _, err := client.RecepcionDE(de); err != nil {
+		t.Fatalf("RecepcionDE() error = %v", err)
+	}
+	if _, err := client.EnviarLoteDE(LoteParams{
+		Documentos:    []*models.DocumentoElectronico{de},
+		TipoDocumento: types.TTiDE_FacturaElectronica,
+	}); err != nil {
+		t.Fatalf("EnviarLoteDE() error = %v", err)
+	}
+
+	paths, err := sink.FindByCDC(cdc)
+	if err != nil {
+		t.Fatalf("FindByCDC() error = %v", err)
+	}
+	if len(paths) != 2 {
+		t.Fatalf("FindByCDC() = %v; want 2 records", paths)
+	}
+
+	data, err := os.ReadFile(paths[0])
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	var rec auditFile
+	if err := json.Unmarshal(data, &rec); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if rec.Operation != OpRecepcionDE || rec.Outcome != AuditOutcomeOK || rec.ResponseCode != "0260" {
+		t.Errorf("record = %+v", rec)
+	}
+	if !strings.Contains(rec.Request, "rEnviDe") || !strings.Contains(rec.Request, cdc) {
+		t.Errorf("Request does not contain the sent DE: %s", rec.Request)
+	}
+
+	if !strings.Contains(paths[1], "lote-777") {
+		t.Errorf("batch record path = %s; want it under lote-777", paths[1])
+	}
+}
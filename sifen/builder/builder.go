@@ -0,0 +1,316 @@
+package builder
+
+import (
+	stderrors "errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rodascaar/sifen-go-py/internal/util"
+	"github.com/rodascaar/sifen-go-py/sifen"
+	"github.com/rodascaar/sifen-go-py/sifen/errors"
+	"github.com/rodascaar/sifen-go-py/sifen/models"
+	"github.com/rodascaar/sifen-go-py/sifen/types"
+)
+
+// ============================================================================
+// Builder de Documentos Electrónicos
+// ============================================================================
+
+// Builder arma un DE de un tipo determinado. Los métodos se encadenan y Build
+// completa los grupos obligatorios, genera dCodSeg y el CDC y retorna el DE:
+//
+//	de, err := builder.NewFactura().
+//		DesdeConfig(config).
+//		Emisor(emisor).
+//		Timbrado(12345678, "2024-01-01").
+//		Numero("001", "001", "0000061").
+//		Receptor(receptor).
+//		Item(item).
+//		Build()
+//
+// Cada builder construye un solo DE.
+type Builder struct {
+	de      *models.DocumentoElectronico
+	config  *sifen.SifenConfig
+	fecha   time.Time
+	totales *models.TotalsOptions
+	usado   bool // Build ya retornó el DE
+}
+
+func newBuilder(tipo types.TTiDE) *Builder {
+	b := &Builder{de: models.NewDE(""), fecha: time.Now()}
+	de := &b.de.DE
+	de.GOpeDE.ITipEmi = types.TTipEmi_Normal
+	de.GTimb.ITiDE = tipo
+	if tipo != types.TTiDE_NotaRemisionElectronica {
+		// gOpeCom es obligatorio salvo en la nota de remisión
+		de.GDatGralOpe.GOpeCom = &models.TgOpeCom{ITImp: types.TTImp_IVA, CMoneOpe: types.CMondT_PYG}
+	}
+	return b
+}
+
+// NewFactura crea una factura electrónica (iTiDE 1) con venta presencial de
+// mercaderías al contado
+func NewFactura() *Builder {
+	b := newBuilder(types.TTiDE_FacturaElectronica)
+	tipTra := types.TTipTra_VentaMercaderia
+	b.de.DE.GDatGralOpe.GOpeCom.ITipTra = &tipTra
+	b.de.DE.GDtipDE.GCamFE = &models.TgCamFE{IIndPres: types.TiIndPres_Presencial}
+	b.de.DE.GDtipDE.GCamCond = &models.TgCamCond{ICondOpe: types.TiCondOpe_Contado}
+	return b
+}
+
+// NewNotaCredito crea una nota de crédito electrónica (iTiDE 5). Requiere el
+// documento que ajusta con DocumentoAsociado.
+func NewNotaCredito(motivo types.TiMotEmiNC) *Builder {
+	b := newBuilder(types.TTiDE_NotaCreditoElectronica)
+	b.de.DE.GDtipDE.GCamNCDE = &models.TgCamNCDE{IMotEmi: motivo}
+	return b
+}
+
+// NewNotaDebito crea una nota de débito electrónica (iTiDE 6). Requiere el
+// documento que ajusta con DocumentoAsociado.
+func NewNotaDebito(motivo types.TiMotEmiNC) *Builder {
+	b := newBuilder(types.TTiDE_NotaDebitoElectronica)
+	b.de.DE.GDtipDE.GCamNCDE = &models.TgCamNCDE{IMotEmi: motivo}
+	return b
+}
+
+// NewNotaRemision crea una nota de remisión electrónica (iTiDE 7). Requiere
+// los datos del traslado con Transporte.
+func NewNotaRemision(motivo types.TiMotEmiNR, responsable types.TiRespFlete) *Builder {
+	b := newBuilder(types.TTiDE_NotaRemisionElectronica)
+	b.de.DE.GDtipDE.GCamNRE = &models.TgCamNRE{IMotEmiNR: motivo, IRespEmiNR: responsable}
+	return b
+}
+
+// NewAutofactura crea una autofactura electrónica (iTiDE 4) con los datos del
+// vendedor. Requiere la constancia de no contribuyente con DocumentoAsociado.
+func NewAutofactura(vendedor models.TgCamAE) *Builder {
+	b := newBuilder(types.TTiDE_AutofacturaElectronica)
+	tipTra := types.TTipTra_CompraProductos
+	b.de.DE.GDatGralOpe.GOpeCom.ITipTra = &tipTra
+	b.de.DE.GDtipDE.GCamAE = &vendedor
+	b.de.DE.GDtipDE.GCamCond = &models.TgCamCond{ICondOpe: types.TiCondOpe_Contado}
+	return b
+}
+
+// DesdeConfig toma de la configuración el RUC, DV y tipo de contribuyente del
+// emisor y el establecimiento por defecto, si no se indicaron en el builder
+func (b *Builder) DesdeConfig(config *sifen.SifenConfig) *Builder {
+	b.config = config
+	return b
+}
+
+// Emisor fija los datos del emisor (grupo D2)
+func (b *Builder) Emisor(emisor models.TgEmis) *Builder {
+	b.de.DE.GDatGralOpe.GEmis = emisor
+	return b
+}
+
+// Receptor fija los datos del receptor (grupo D3)
+func (b *Builder) Receptor(receptor models.TgDatRec) *Builder {
+	b.de.DE.GDatGralOpe.GDatRec = receptor
+	return b
+}
+
+// Timbrado fija el número de timbrado y su fecha de inicio de vigencia (yyyy-MM-dd)
+func (b *Builder) Timbrado(numero int32, inicioVigencia string) *Builder {
+	b.de.DE.GTimb.DNumTim = numero
+	b.de.DE.GTimb.DFeIniT = inicioVigencia
+	return b
+}
+
+// Numero fija establecimiento, punto de expedición y número del documento;
+// se completan con ceros a la izquierda
+func (b *Builder) Numero(establecimiento, puntoExpedicion, numero string) *Builder {
+	b.de.DE.GTimb.DEst = util.LeftPad(establecimiento, '0', 3)
+	b.de.DE.GTimb.DPunExp = util.LeftPad(puntoExpedicion, '0', 3)
+	b.de.DE.GTimb.DNumDoc = util.LeftPad(numero, '0', 7)
+	return b
+}
+
+// Serie fija la serie del número de documento
+func (b *Builder) Serie(serie string) *Builder {
+	b.de.DE.GTimb.DSerieNum = serie
+	return b
+}
+
+// Fecha fija la fecha de emisión; por defecto es el momento de crear el builder
+func (b *Builder) Fecha(fecha time.Time) *Builder {
+	b.fecha = fecha
+	return b
+}
+
+// TipoEmision fija el tipo de emisión; por defecto es normal
+func (b *Builder) TipoEmision(tipo types.TTipEmi) *Builder {
+	b.de.DE.GOpeDE.ITipEmi = tipo
+	return b
+}
+
+// CodigoSeguridad fija dCodSeg (9 dígitos); por defecto se genera al construir
+func (b *Builder) CodigoSeguridad(codigo string) *Builder {
+	b.de.DE.GOpeDE.DCodSeg = codigo
+	return b
+}
+
+// TipoTransaccion fija iTipTra
+func (b *Builder) TipoTransaccion(tipo types.TTipTra) *Builder {
+	if ope := b.de.DE.GDatGralOpe.GOpeCom; ope != nil {
+		ope.ITipTra = &tipo
+	}
+	return b
+}
+
+// Impuesto fija el tipo de impuesto afectado; por defecto es IVA
+func (b *Builder) Impuesto(tipo types.TTImp) *Builder {
+	if ope := b.de.DE.GDatGralOpe.GOpeCom; ope != nil {
+		ope.ITImp = tipo
+	}
+	return b
+}
+
+// Moneda fija la moneda de la operación; por defecto es PYG
+func (b *Builder) Moneda(moneda types.CMondT) *Builder {
+	if ope := b.de.DE.GDatGralOpe.GOpeCom; ope != nil {
+		ope.CMoneOpe = moneda
+	}
+	return b
+}
+
+// Presencia fija el indicador de presencia de la factura
+func (b *Builder) Presencia(indicador types.TiIndPres) *Builder {
+	if fe := b.de.DE.GDtipDE.GCamFE; fe != nil {
+		fe.IIndPres = indicador
+	}
+	return b
+}
+
+// Contado fija la condición contado con sus formas de pago
+func (b *Builder) Contado(pagos ...models.TgPaConEIni) *Builder {
+	b.de.DE.GDtipDE.GCamCond = &models.TgCamCond{ICondOpe: types.TiCondOpe_Contado, GPaConEIni: pagos}
+	return b
+}
+
+// Credito fija la condición crédito
+func (b *Builder) Credito(credito models.TgCredCond) *Builder {
+	b.de.DE.GDtipDE.GCamCond = &models.TgCamCond{ICondOpe: types.TiCondOpe_Credito, GCredCond: &credito}
+	return b
+}
+
+// Item agrega ítems a la operación
+func (b *Builder) Item(items ...models.TgCamItem) *Builder {
+	b.de.DE.GDtipDE.GCamItemList = append(b.de.DE.GDtipDE.GCamItemList, items...)
+	return b
+}
+
+// Totales fija el grupo de subtotales y totales
+func (b *Builder) Totales(totales models.TgTotSub) *Builder {
+	b.de.DE.GTotSub = &totales
+	return b
+}
+
+// CalcularTotales hace que Build complete los valores de los ítems y el grupo
+// gTotSub con models.CalculateTotals. Los datos que se fijen con Totales
+// (descuento global y comisión) se usan en el cálculo. Si algún monto no cabe
+// en un types.Decimal, Build falla con ErrMontoInvalido.
+func (b *Builder) CalcularTotales(opts models.TotalsOptions) *Builder {
+	b.totales = &opts
+	return b
+}
+
+// Transporte fija los datos del traslado, obligatorios en la nota de remisión
+func (b *Builder) Transporte(transporte models.TgTransp) *Builder {
+	b.de.DE.GDtipDE.GTransp = &transporte
+	return b
+}
+
+// DocumentoAsociado agrega documentos asociados (grupo H)
+func (b *Builder) DocumentoAsociado(docs ...models.TgCamDEAsoc) *Builder {
+	b.de.DE.GCamDEAsoc = append(b.de.DE.GCamDEAsoc, docs...)
+	return b
+}
+
+// Build completa los datos derivados y las descripciones de los códigos y
+// retorna el DE. Falla si faltan el RUC del emisor, el timbrado, la numeración
+// o los ítems, o si un código no tiene descripción conocida ni cargada.
+//
+// El DE retornado ya no pertenece al builder: los métodos llamados después no
+// lo modifican y un nuevo Build falla con ErrBuilderUsado. Si Build falla, el
+// builder puede corregirse y volver a construir.
+func (b *Builder) Build() (*models.DocumentoElectronico, error) {
+	if b.usado {
+		return nil, errors.ErrBuilderUsado
+	}
+	de := &b.de.DE
+	if b.config != nil {
+		b.config.ApplyEmisor(b.de)
+	}
+
+	emis := &de.GDatGralOpe.GEmis
+	if emis.DRucEm == "" {
+		return nil, errors.ErrRUCInvalido.WithCause(fmt.Errorf("RUC del emisor requerido"))
+	}
+	if emis.DDVEmi == "" {
+		emis.DDVEmi = strconv.Itoa(util.CalculateRUCVerifyDigit(emis.DRucEm))
+	}
+	if emis.ITipCont == 0 {
+		return nil, errors.ErrTipoContribuyenteRequerido
+	}
+	if de.GTimb.DNumTim <= 0 {
+		return nil, errors.ErrTimbradoInvalido.WithCause(nil).WithContext("timbrado", de.GTimb.DNumTim)
+	}
+	if len(de.GTimb.DEst) != 3 {
+		return nil, errors.ErrEstablecimientoInvalido.WithCause(nil).WithContext("establecimiento", de.GTimb.DEst)
+	}
+	if len(de.GTimb.DPunExp) != 3 {
+		return nil, errors.ErrPuntoExpedicionInvalido.WithCause(nil).WithContext("puntoExpedicion", de.GTimb.DPunExp)
+	}
+	if len(de.GDtipDE.GCamItemList) == 0 {
+		return nil, errors.ErrDocumentoVacio
+	}
+
+	de.GDatGralOpe.DFeEmiDE = b.fecha.Format("2006-01-02T15:04:05")
+	if de.GOpeDE.DCodSeg == "" {
+		de.GOpeDE.DCodSeg = util.GenerateSecurityCode()
+	}
+
+	cdc, err := util.GenerateCDC(util.CDCParams{
+		TipoDocumento:     int16(de.GTimb.ITiDE),
+		RUC:               emis.DRucEm,
+		DigitoVerificador: emis.DDVEmi,
+		Establecimiento:   de.GTimb.DEst,
+		PuntoExpedicion:   de.GTimb.DPunExp,
+		NumeroDocumento:   de.GTimb.DNumDoc,
+		TipoContribuyente: int16(emis.ITipCont),
+		FechaEmision:      b.fecha,
+		TipoEmision:       int16(de.GOpeDE.ITipEmi),
+		CodigoSeguridad:   de.GOpeDE.DCodSeg,
+	})
+	if err != nil {
+		return nil, errors.ErrCDCInvalido.WithCause(err)
+	}
+	de.Id = cdc
+	de.DDVId = cdc[len(cdc)-1:]
+
+	if b.totales != nil {
+		if err := b.de.CalculateTotals(*b.totales); err != nil {
+			return nil, errors.ErrMontoInvalido.WithCause(err)
+		}
+	}
+
+	if unknown := b.de.Normalize(); len(unknown) > 0 {
+		errs := make([]error, len(unknown))
+		for i, u := range unknown {
+			errs[i] = u
+		}
+		return nil, errors.ErrDescripcionDesconocida.WithCause(stderrors.Join(errs...))
+	}
+
+	// Los métodos siguientes escriben en un DE descartable
+	built := b.de
+	b.de = models.NewDE("")
+	b.usado = true
+	return built, nil
+}
@@ -0,0 +1,186 @@
+package builder
+
+import (
+	stderrors "errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rodascaar/sifen-go-py/internal/util"
+	"github.com/rodascaar/sifen-go-py/sifen"
+	"github.com/rodascaar/sifen-go-py/sifen/errors"
+	"github.com/rodascaar/sifen-go-py/sifen/models"
+	"github.com/rodascaar/sifen-go-py/sifen/types"
+)
+
+func testConfig() *sifen.SifenConfig {
+	config := sifen.NewSifenConfig()
+	config.RucEmisor = "80069563"
+	config.DvEmisor = "1"
+	config.TipoContribuyente = types.TiTipCont_PersonaJuridica
+	return config
+}
+
+func testEmisor() models.TgEmis {
+	return models.TgEmis{DNomEmi: "Empresa Test SA", CDepEmi: types.TDepartamento_Capital}
+}
+
+func testReceptor() models.TgDatRec {
+	return models.TgDatRec{INatRec: types.TiNatRec_NoContribuyente, CPaisRec: types.PaisType_PRY, DNumIDRec: "1234567"}
+}
+
+func testItem() models.TgCamItem {
+	return models.TgCamItem{DCodInt: "001", DDesProSer: "Producto", CUniMed: types.TcUniMed_Unidad, DCantProSer: types.DecimalFromInt(1)}
+}
+
+func TestNewFactura(t *testing.T) {
+	fecha := time.Date(2024, 12, 30, 17, 59, 57, 0, time.Local)
+	de, err := NewFactura().
+		DesdeConfig(testConfig()).
+		Emisor(testEmisor()).
+		Receptor(testReceptor()).
+		Timbrado(12345678, "2024-01-01").
+		Numero("1", "1", "61").
+		Fecha(fecha).
+		CodigoSeguridad("123456789").
+		Item(testItem()).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	want, _ := util.GenerateCDC(util.CDCParams{
+		TipoDocumento: 1, RUC: "80069563", DigitoVerificador: "1",
+		Establecimiento: "001", PuntoExpedicion: "001", NumeroDocumento: "0000061",
+		TipoContribuyente: 2, FechaEmision: fecha, TipoEmision: 1, CodigoSeguridad: "123456789",
+	})
+	if de.DE.Id != want || de.DE.DDVId != want[43:] {
+		t.Errorf("Id = %s, DDVId = %s; want %s", de.DE.Id, de.DE.DDVId, want)
+	}
+	if de.DE.GDatGralOpe.DFeEmiDE != "2024-12-30T17:59:57" {
+		t.Errorf("DFeEmiDE = %s", de.DE.GDatGralOpe.DFeEmiDE)
+	}
+	dtip := de.DE.GDtipDE
+	if dtip.GCamFE == nil || dtip.GCamFE.DDesIndPres == "" || dtip.GCamCond == nil || dtip.GCamNCDE != nil {
+		t.Errorf("GDtipDE = %+v; want gCamFE and gCamCond", dtip)
+	}
+	if ope := de.DE.GDatGralOpe.GOpeCom; ope == nil || ope.ITipTra == nil || ope.DDesTImp == "" || ope.DDesMoneOpe != "Guarani" {
+		t.Errorf("GOpeCom = %+v", ope)
+	}
+	if de.DE.GDatGralOpe.GEmis.DDesDepEmi != "CAPITAL" || de.DE.GDatGralOpe.GDatRec.DDesPaisRe != "Paraguay" {
+		t.Errorf("GEmis.DDesDepEmi = %q, GDatRec.DDesPaisRe = %q", de.DE.GDatGralOpe.GEmis.DDesDepEmi, de.DE.GDatGralOpe.GDatRec.DDesPaisRe)
+	}
+	if item := de.DE.GDtipDE.GCamItemList[0]; item.DDesUniMed != "UNI" {
+		t.Errorf("DDesUniMed = %q", item.DDesUniMed)
+	}
+}
+
+func TestBuildPerDocumentType(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *Builder
+		tipo    types.TTiDE
+		check   func(models.TgDtipDE) bool
+	}{
+		{"nota de crédito", NewNotaCredito(types.TiMotEmiNC_Devolucion), types.TTiDE_NotaCreditoElectronica,
+			func(d models.TgDtipDE) bool { return d.GCamNCDE != nil && d.GCamNCDE.DDesMotEmi != "" }},
+		{"nota de débito", NewNotaDebito(types.TiMotEmiNC_AjustePrecio), types.TTiDE_NotaDebitoElectronica,
+			func(d models.TgDtipDE) bool { return d.GCamNCDE != nil }},
+		{"nota de remisión", NewNotaRemision(types.TiMotEmiNR_TrasladoVentas, types.TiRespFlete_EmisorFactura), types.TTiDE_NotaRemisionElectronica,
+			func(d models.TgDtipDE) bool { return d.GCamNRE != nil && d.GCamCond == nil }},
+		{"autofactura", NewAutofactura(models.TgCamAE{INatVen: types.TiNatVendedorAF_NoContribuyente, ITipIDVen: types.TTipDocRec_CedulaParaguaya, CDepVen: types.TDepartamento_Central, CDepProv: types.TDepartamento_Central}), types.TTiDE_AutofacturaElectronica,
+			func(d models.TgDtipDE) bool { return d.GCamAE != nil && d.GCamCond != nil }},
+	}
+
+	for _, tt := range tests {
+		de, err := tt.builder.DesdeConfig(testConfig()).Emisor(testEmisor()).Receptor(testReceptor()).Timbrado(12345678, "2024-01-01").
+			Numero("001", "001", "0000001").Item(testItem()).Build()
+		if err != nil {
+			t.Errorf("%s: Build() error = %v", tt.name, err)
+			continue
+		}
+		if de.DE.GTimb.ITiDE != tt.tipo || de.DE.Id[:2] != fmt.Sprintf("%02d", tt.tipo) {
+			t.Errorf("%s: iTiDE = %d, CDC = %s", tt.name, de.DE.GTimb.ITiDE, de.DE.Id)
+		}
+		if !tt.check(de.DE.GDtipDE) {
+			t.Errorf("%s: GDtipDE = %+v", tt.name, de.DE.GDtipDE)
+		}
+	}
+}
+
+func TestBuildMissingData(t *testing.T) {
+	noTipo := testConfig()
+	noTipo.TipoContribuyente = 0
+	unidad := testItem()
+	unidad.CUniMed = 1
+
+	tests := []struct {
+		name    string
+		builder *Builder
+		want    *errors.SifenError
+	}{
+		{"sin emisor", NewFactura().Timbrado(1, "2024-01-01").Numero("1", "1", "1").Item(testItem()), errors.ErrRUCInvalido},
+		{"sin tipo de contribuyente", NewFactura().DesdeConfig(noTipo).Timbrado(1, "2024-01-01").Numero("1", "1", "1").Item(testItem()), errors.ErrTipoContribuyenteRequerido},
+		{"sin timbrado", NewFactura().DesdeConfig(testConfig()).Numero("1", "1", "1").Item(testItem()), errors.ErrTimbradoInvalido},
+		{"sin ítems", NewFactura().DesdeConfig(testConfig()).Timbrado(1, "2024-01-01").Numero("1", "1", "1"), errors.ErrDocumentoVacio},
+		{"unidad desconocida", NewFactura().DesdeConfig(testConfig()).Emisor(testEmisor()).Receptor(testReceptor()).Timbrado(1, "2024-01-01").Numero("1", "1", "1").Item(unidad), errors.ErrDescripcionDesconocida},
+	}
+
+	for _, tt := range tests {
+		if _, err := tt.builder.Build(); !stderrors.Is(err, tt.want) {
+			t.Errorf("%s: Build() error = %v; want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestBuildTwice(t *testing.T) {
+	b := NewFactura().DesdeConfig(testConfig()).Emisor(testEmisor()).Receptor(testReceptor()).
+		Timbrado(12345678, "2024-01-01").Numero("001", "001", "0000001").Item(testItem())
+	de, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	cdc, fecha := de.DE.Id, de.DE.GDatGralOpe.DFeEmiDE
+
+	b.Numero("001", "001", "0000002").Fecha(time.Now().Add(time.Hour)).Item(testItem())
+	if _, err := b.Build(); !stderrors.Is(err, errors.ErrBuilderUsado) {
+		t.Errorf("second Build() error = %v; want ErrBuilderUsado", err)
+	}
+	if de.DE.Id != cdc || de.DE.GDatGralOpe.DFeEmiDE != fecha || de.DE.GTimb.DNumDoc != "0000001" || len(de.DE.GDtipDE.GCamItemList) != 1 {
+		t.Errorf("the built DE changed after reusing the builder: Id = %s, DNumDoc = %s, items = %d",
+			de.DE.Id, de.DE.GTimb.DNumDoc, len(de.DE.GDtipDE.GCamItemList))
+	}
+}
+
+func TestBuildCalcularTotales(t *testing.T) {
+	item := testItem()
+	item.DCantProSer = types.DecimalFromInt(2)
+	item.GValorItem.DPUniProSer = types.DecimalFromInt(55000)
+	item.GCamIVA = &models.TgCamIVA{IAfecIVA: types.TiAfecIVA_GravadoIVA, DTasaIVA: types.DecimalFromInt(10)}
+
+	de, err := NewFactura().DesdeConfig(testConfig()).Emisor(testEmisor()).Receptor(testReceptor()).
+		Timbrado(12345678, "2024-01-01").Numero("001", "001", "0000001").Item(item).
+		Totales(models.TgTotSub{DPorcDescTotal: types.DecimalFromInt(10)}).CalcularTotales(models.TotalsOptions{}).Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	iva := de.DE.GDtipDE.GCamItemList[0].GCamIVA
+	if iva.DDesAfecIVA == "" || iva.DBasGravIVA.String() != "90000" || iva.DLiqIVAItem.String() != "9000" {
+		t.Errorf("GCamIVA = %+v", iva)
+	}
+	tot := de.DE.GTotSub
+	got := fmt.Sprint(tot.DTotOpe, tot.DTotDescGlotem, tot.DIVA10, tot.DTotIVA, tot.DTotGralOpe)
+	if want := "99000 11000 9000 9000 99000"; got != want {
+		t.Errorf("dTotOpe, dTotDescGlotem, dIVA10, dTotIVA, dTotGralOpe = %s; want %s", got, want)
+	}
+
+	// Un monto que no cabe en un Decimal es un error, no un pánico
+	item.DCantProSer = types.DecimalFromInt(9e18)
+	_, err = NewFactura().DesdeConfig(testConfig()).Emisor(testEmisor()).Receptor(testReceptor()).
+		Timbrado(12345678, "2024-01-01").Numero("001", "001", "0000002").Item(item).
+		CalcularTotales(models.TotalsOptions{}).Build()
+	if !stderrors.Is(err, errors.ErrMontoInvalido) {
+		t.Errorf("Build() error = %v; want ErrMontoInvalido", err)
+	}
+}
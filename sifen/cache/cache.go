@@ -64,6 +64,7 @@ type Cache struct {
 	mu      sync.RWMutex
 	stats   CacheStats
 	stopCh  chan struct{}
+	stopped sync.Once
 }
 
 // CacheStats contiene estadísticas del caché
@@ -113,29 +114,25 @@ func (c *Cache) Set(key string, value interface{}, ttl time.Duration) {
 
 // Get obtiene un valor del caché
 func (c *Cache) Get(key string) (interface{}, bool) {
-	c.mu.RLock()
-	entry, exists := c.entries[key]
-	c.mu.RUnlock()
+	// Lock exclusivo: la lectura actualiza estadísticas y puede borrar la
+	// entrada expirada sin que otra goroutine la reemplace en el medio
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
+	entry, exists := c.entries[key]
 	if !exists {
-		c.mu.Lock()
 		c.stats.Misses++
-		c.mu.Unlock()
 		return nil, false
 	}
 
 	if entry.isExpired() {
-		c.mu.Lock()
 		delete(c.entries, key)
 		c.stats.Misses++
-		c.mu.Unlock()
 		return nil, false
 	}
 
-	c.mu.Lock()
 	entry.hitCount++
 	c.stats.Hits++
-	c.mu.Unlock()
 
 	return entry.value, true
 }
@@ -164,9 +161,9 @@ func (c *Cache) Stats() CacheStats {
 	return stats
 }
 
-// Close detiene el cleanup loop y libera recursos
+// Close detiene el cleanup loop y libera recursos; puede llamarse más de una vez
 func (c *Cache) Close() {
-	close(c.stopCh)
+	c.stopped.Do(func() { close(c.stopCh) })
 }
 
 // ============================================================================
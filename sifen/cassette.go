@@ -0,0 +1,273 @@
+package sifen
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rodascaar/sifen-go-py/internal/soap"
+	"github.com/rodascaar/sifen-go-py/sifen/errors"
+)
+
+// ============================================================================
+// Cassettes: Grabación y Reproducción de Intercambios SIFEN
+// ============================================================================
+
+// CassetteMode indica si un Cassette graba, reproduce o ambas cosas
+type CassetteMode int
+
+const (
+	// CassetteReplay responde solo con intercambios grabados; una solicitud
+	// sin intercambio grabado falla sin contactar a SIFEN
+	CassetteReplay CassetteMode = iota
+	// CassetteRecord envía todas las solicitudes a SIFEN y graba cada intercambio
+	CassetteRecord
+	// CassetteAuto reproduce si hay un intercambio grabado y si no, envía y graba
+	CassetteAuto
+)
+
+// CassetteInteraction es un intercambio SOAP grabado
+type CassetteInteraction struct {
+	Operation  Operacion   `json:"operation"`
+	URL        string      `json:"url"`
+	Request    string      `json:"request"`
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Response   string      `json:"response"`
+	RecordedAt time.Time   `json:"recordedAt"`
+}
+
+type cassetteFile struct {
+	Interactions []CassetteInteraction `json:"interactions"`
+}
+
+// Cassette graba intercambios reales con SIFEN en un archivo JSON y luego los
+// reproduce en forma determinista, para tests de regresión contra respuestas reales.
+//
+// Las solicitudes se comparan por operación y por cuerpo normalizado: se ignoran
+// dId, fechas y horas, y los valores derivados de ellas (DigestValue,
+// SignatureValue y dCarQR). Los lotes se comparan por el contenido del zip.
+// Si la misma solicitud se grabó varias veces (ej. ConsultaLoteDE en proceso y
+// luego concluido), las grabaciones se reproducen en orden y la última se repite.
+type Cassette struct {
+	Path string
+	Mode CassetteMode
+
+	mu           sync.Mutex
+	interactions []CassetteInteraction
+	keys         []string
+	used         []bool
+}
+
+// LoadCassette abre el cassette en path. En modo CassetteReplay el archivo debe
+// existir; en los demás modos se crea al grabar el primer intercambio.
+func LoadCassette(path string, mode CassetteMode) (*Cassette, error) {
+	c := &Cassette{Path: path, Mode: mode}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) && mode != CassetteReplay {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer el cassette %s: %w", path, err)
+	}
+
+	var file cassetteFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("cassette %s inválido: %w", path, err)
+	}
+	for _, it := range file.Interactions {
+		c.add(it)
+	}
+	return c, nil
+}
+
+// Interactions retorna una copia de los intercambios del cassette
+func (c *Cassette) Interactions() []CassetteInteraction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]CassetteInteraction(nil), c.interactions...)
+}
+
+// Middleware retorna el middleware que graba o reproduce los intercambios.
+// NewSifenClient lo instala como middleware más interno cuando SifenConfig.Cassette
+// está configurado; también puede agregarse manualmente a SifenConfig.Middlewares.
+func (c *Cassette) Middleware() Middleware {
+	return func(next soap.Handler) soap.Handler {
+		return func(ctx context.Context, req *soap.Request) (*soap.Response, error) {
+			op, _ := OperacionFromContext(ctx)
+			key := cassetteKey(op, req.Envelope)
+
+			if c.Mode != CassetteRecord {
+				if it, ok := c.match(key); ok {
+					return &soap.Response{
+						StatusCode: it.StatusCode,
+						Header:     it.Header,
+						Body:       []byte(it.Response),
+					}, nil
+				}
+				if c.Mode == CassetteReplay {
+					return nil, errors.NewInternalError(
+						fmt.Sprintf("cassette %s: no hay intercambio grabado para %s", c.Path, op), nil).
+						WithContext("url", req.URL)
+				}
+			}
+
+			resp, err := next(ctx, req)
+			if err != nil || resp == nil {
+				return resp, err
+			}
+
+			it := CassetteInteraction{
+				Operation:  op,
+				URL:        req.URL,
+				Request:    string(req.Envelope),
+				StatusCode: resp.StatusCode,
+				Header:     resp.Header,
+				Response:   string(resp.Body),
+				RecordedAt: time.Now(),
+			}
+			if saveErr := c.record(it); saveErr != nil {
+				return resp, errors.NewInternalError("no se pudo grabar el cassette", saveErr)
+			}
+			return resp, nil
+		}
+	}
+}
+
+// match busca la primera grabación no usada con key; si todas fueron usadas,
+// repite la última
+func (c *Cassette) match(key string) (CassetteInteraction, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	last := -1
+	for i, k := range c.keys {
+		if k != key {
+			continue
+		}
+		if !c.used[i] {
+			c.used[i] = true
+			return c.interactions[i], true
+		}
+		last = i
+	}
+	if last >= 0 {
+		return c.interactions[last], true
+	}
+	return CassetteInteraction{}, false
+}
+
+// record agrega el intercambio y reescribe el archivo en forma atómica
+func (c *Cassette) record(it CassetteInteraction) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.add(it)
+	c.used[len(c.used)-1] = true
+
+	data, err := json.MarshalIndent(cassetteFile{Interactions: c.interactions}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(c.Path, data)
+}
+
+func (c *Cassette) add(it CassetteInteraction) {
+	c.interactions = append(c.interactions, it)
+	c.keys = append(c.keys, cassetteKey(it.Operation, []byte(it.Request)))
+	c.used = append(c.used, false)
+}
+
+// ============================================================================
+// Normalización de Solicitudes
+// ============================================================================
+
+var (
+	cassetteDIdRe      = regexp.MustCompile(`<((?:\w+:)?dId)>[^<]*</(?:\w+:)?dId>`)
+	cassetteDerivedRe  = regexp.MustCompile(`<((?:\w+:)?(?:DigestValue|SignatureValue|dCarQR))>[^<]*</(?:\w+:)?(?:DigestValue|SignatureValue|dCarQR)>`)
+	cassetteDateTimeRe = regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:\d{2})?`)
+	cassetteXDERe      = regexp.MustCompile(`<((?:\w+:)?xDE)>([A-Za-z0-9+/=\s]+)</(?:\w+:)?xDE>`)
+	cassetteEventoIdRe = regexp.MustCompile(`(<(?:\w+:)?rEve\b[^>]*\sId=")([^"]*)(")`)
+	cassetteSpaceRe    = regexp.MustCompile(`>\s+<`)
+)
+
+// cassetteKey identifica una solicitud por operación y cuerpo normalizado
+func cassetteKey(op Operacion, envelope []byte) string {
+	return string(op) + "\n" + normalizeCassetteBody(string(envelope))
+}
+
+// normalizeCassetteBody elimina del sobre los valores que cambian entre
+// ejecuciones de una misma solicitud
+func normalizeCassetteBody(body string) string {
+	// Lotes: comparar el XML contenido en el zip, no el Base64
+	body = cassetteXDERe.ReplaceAllStringFunc(body, func(m string) string {
+		sub := cassetteXDERe.FindStringSubmatch(m)
+		content, err := unzipBase64(sub[2])
+		if err != nil {
+			return m
+		}
+		return "<" + sub[1] + ">" + content + "</" + sub[1] + ">"
+	})
+
+	// Eventos: el Id de rEve sale de EventoIdGenerator y lo referencia la firma
+	for _, m := range cassetteEventoIdRe.FindAllStringSubmatch(body, -1) {
+		body = strings.ReplaceAll(body, `URI="#`+m[2]+`"`, `URI="#"`)
+	}
+	body = cassetteEventoIdRe.ReplaceAllString(body, "$1$3")
+
+	body = cassetteDIdRe.ReplaceAllString(body, "<$1/>")
+	body = cassetteDerivedRe.ReplaceAllString(body, "<$1/>")
+	body = cassetteDateTimeRe.ReplaceAllString(body, "*")
+	return strings.TrimSpace(cassetteSpaceRe.ReplaceAllString(body, "><"))
+}
+
+// unzipBase64 retorna el contenido concatenado de los archivos de un zip en Base64
+func unzipBase64(content string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(content), ""))
+	if err != nil {
+		return "", err
+	}
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	for _, file := range reader.File {
+		f, err := file.Open()
+		if err != nil {
+			return "", err
+		}
+		b, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+		out.Write(b)
+	}
+	return out.String(), nil
+}
+
+// ============================================================================
+// Operación en el Contexto
+// ============================================================================
+
+type operacionKey struct{}
+
+// OperacionFromContext retorna la operación del cliente que originó un
+// intercambio; disponible para los middlewares
+func OperacionFromContext(ctx context.Context) (Operacion, bool) {
+	op, ok := ctx.Value(operacionKey{}).(Operacion)
+	return op, ok
+}
@@ -0,0 +1,178 @@
+package sifen
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rodascaar/sifen-go-py/sifen/models"
+	"github.com/rodascaar/sifen-go-py/sifen/types"
+)
+
+func newCassetteClient(t *testing.T, url string, cassette *Cassette) *SifenClient {
+	t.Helper()
+	config := NewSifenConfig()
+	config.UrlBase = url
+	config.UsarCertificadoCliente = false
+	config.RetryPolicy = fastPolicy()
+	config.Cassette = cassette
+
+	client, err := NewSifenClient(config)
+	if err != nil {
+		t.Fatalf("NewSifenClient() error = %v", err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	var loteCalls int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "evento.wsdl"):
+			fmt.Fprintf(w, soapEnvelopeFmt, "<rRetEnviEventoDe><dCodRes>0600</dCodRes></rRetEnviEventoDe>")
+		case strings.HasSuffix(r.URL.Path, "recibe-lote.wsdl"):
+			fmt.Fprintf(w, soapEnvelopeFmt, "<rRetEnviLoteDe><dCodRes>0300</dCodRes><dProtConsLot>42</dProtConsLot></rRetEnviLoteDe>")
+		case strings.HasSuffix(r.URL.Path, "consulta-lote.wsdl"):
+			code := "0362"
+			if atomic.AddInt32(&loteCalls, 1) == 1 {
+				code = "0361"
+			}
+			fmt.Fprintf(w, soapEnvelopeFmt, "<rResEnviConsLoteDe><dCodRes>"+code+"</dCodRes></rResEnviConsLoteDe>")
+		default:
+			fmt.Fprintf(w, soapEnvelopeFmt, "<rRetEnviDe><dCodRes>0260</dCodRes><rProtDe><dEstRes>Aprobado</dEstRes></rProtDe></rRetEnviDe>")
+		}
+	})
+
+	path := filepath.Join(t.TempDir(), "session.json")
+	recording, err := LoadCassette(path, CassetteRecord)
+	if err != nil {
+		t.Fatalf("LoadCassette() error = %v", err)
+	}
+
+	base := newTestClient(t, handler, fastPolicy())
+	recorder := newCassetteClient(t, base.GetConfig().UrlBase, recording)
+
+	newDoc := func() *models.DocumentoElectronico {
+		de := models.NewDE(strings.Repeat("1", 44))
+		de.DE.GTimb.ITiDE = types.TTiDE_FacturaElectronica
+		return de
+	}
+
+	if _, err := recorder.RecepcionDE(newDoc()); err != nil {
+		t.Fatalf("RecepcionDE() error = %v", err)
+	}
+	if _, err := recorder.EnviarLoteDE(LoteParams{
+		Documentos:    []*models.DocumentoElectronico{newDoc()},
+		TipoDocumento: types.TTiDE_FacturaElectronica,
+	}); err != nil {
+		t.Fatalf("EnviarLoteDE() error = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := recorder.ConsultaLoteDE("42"); err != nil {
+			t.Fatalf("ConsultaLoteDE() error = %v", err)
+		}
+	}
+	if _, err := recorder.CancelarDE(strings.Repeat("1", 44), "Error en los datos"); err != nil {
+		t.Fatalf("CancelarDE() error = %v", err)
+	}
+
+	// Reproducir sin servidor, con otros dId, Id de evento y fecha de firma
+	replaying, err := LoadCassette(path, CassetteReplay)
+	if err != nil {
+		t.Fatalf("LoadCassette() error = %v", err)
+	}
+	if got := len(replaying.Interactions()); got != 5 {
+		t.Fatalf("Interactions() = %d; want 5", got)
+	}
+	player := newCassetteClient(t, "http://127.0.0.1:1", replaying)
+	player.dIds = NewSequenceDIdGenerator(1000)
+	player.eventoIds = NewSequenceDIdGenerator(500)
+
+	later := newDoc()
+	later.DE.DFecFirma = time.Now().Add(time.Hour).Format("2006-01-02T15:04:05")
+	resp, err := player.RecepcionDE(later)
+	if err != nil {
+		t.Fatalf("replay RecepcionDE() error = %v", err)
+	}
+	if !resp.IsApproved() {
+		t.Errorf("replay RecepcionDE() = %+v; want approved", resp)
+	}
+
+	result, err := player.EnviarLoteDE(LoteParams{
+		Documentos:    []*models.DocumentoElectronico{later},
+		TipoDocumento: types.TTiDE_FacturaElectronica,
+	})
+	if err != nil {
+		t.Fatalf("replay EnviarLoteDE() error = %v", err)
+	}
+	if result.NumeroLote != "42" {
+		t.Errorf("replay NumeroLote = %q; want 42", result.NumeroLote)
+	}
+
+	// Las grabaciones repetidas se reproducen en orden y la última se repite
+	for _, want := range []string{"0361", "0362", "0362"} {
+		consulta, err := player.ConsultaLoteDE("42")
+		if err != nil {
+			t.Fatalf("replay ConsultaLoteDE() error = %v", err)
+		}
+		if consulta.DCodRes != want {
+			t.Errorf("replay ConsultaLoteDE() DCodRes = %q; want %s", consulta.DCodRes, want)
+		}
+	}
+
+	evento, err := player.CancelarDE(strings.Repeat("1", 44), "Error en los datos")
+	if err != nil {
+		t.Fatalf("replay CancelarDE() error = %v", err)
+	}
+	if evento.DCodRes != "0600" {
+		t.Errorf("replay CancelarDE() DCodRes = %q; want 0600", evento.DCodRes)
+	}
+
+	if _, err := player.ConsultaLoteDE("43"); err == nil {
+		t.Error("replay of an unrecorded request succeeded; want error")
+	}
+}
+
+func TestNormalizeCassetteBodyIgnoresEventoId(t *testing.T) {
+	evento := func(id string) string {
+		return `<rGesEve><rEve Id="` + id + `"><dVerFor>150</dVerFor></rEve>` +
+			`<Signature><SignedInfo><Reference URI="#` + id + `"></Reference></SignedInfo></Signature></rGesEve>`
+	}
+	if a, b := normalizeCassetteBody(evento("1234567890")), normalizeCassetteBody(evento("500")); a != b {
+		t.Errorf("normalizeCassetteBody() differs by event Id:\n%s\n%s", a, b)
+	}
+}
+
+// TestCassetteFixtures decodifica los cassettes grabados contra SIFEN en
+// testdata/cassettes, para verificar el paquete response con payloads reales
+func TestCassetteFixtures(t *testing.T) {
+	paths, _ := filepath.Glob(filepath.Join("testdata", "cassettes", "*.json"))
+	if len(paths) == 0 {
+		t.Skip("no recorded cassettes in testdata/cassettes")
+	}
+
+	for _, path := range paths {
+		cassette, err := LoadCassette(path, CassetteReplay)
+		if err != nil {
+			t.Fatalf("LoadCassette(%s) error = %v", path, err)
+		}
+		for i, it := range cassette.Interactions() {
+			if it.StatusCode != http.StatusOK {
+				continue
+			}
+			body, err := decodeResponse([]byte(it.Response))
+			if err != nil {
+				t.Errorf("%s interaction %d (%s): %v", path, i, it.Operation, err)
+				continue
+			}
+			if body.Code() == "" {
+				t.Errorf("%s interaction %d (%s): no dCodRes decoded", path, i, it.Operation)
+			}
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package sifen
+
+import (
+	"crypto/x509"
+	"time"
+
+	"github.com/rodascaar/sifen-go-py/internal/certificate"
+	"github.com/rodascaar/sifen-go-py/sifen/errors"
+)
+
+// CertificadoInfo describe el certificado del cliente: sujeto, emisor, número
+// de serie, vigencia y el RUC tomado del serialNumber del sujeto
+type CertificadoInfo = certificate.Info
+
+// Certificado retorna los datos del certificado cargado; false si el cliente no usa certificado
+func (c *SifenClient) Certificado() (CertificadoInfo, bool) {
+	if c.certInfo == nil {
+		return CertificadoInfo{}, false
+	}
+	return *c.certInfo, true
+}
+
+// checkCertificado verifica la vigencia del certificado y que pertenezca al
+// emisor configurado, y avisa si está por vencer
+func checkCertificado(config *SifenConfig, leaf *x509.Certificate, now time.Time) (CertificadoInfo, error) {
+	info := certificate.Inspect(leaf)
+	if err := info.CheckValidity(now); err != nil {
+		return info, err
+	}
+
+	// Solo se compara cuando ambos RUC son conocidos; el DV solo si el certificado lo trae
+	rucEmisor, dvEmisor := config.emisor()
+	if info.RUC != "" && rucEmisor != "" &&
+		(info.RUC != rucEmisor || (info.DV != "" && dvEmisor != "" && info.DV != dvEmisor)) {
+		ruc := info.RUC
+		if info.DV != "" {
+			ruc += "-" + info.DV
+		}
+		return info, errors.ErrCertificadoRUCNoCoincide.WithCause(nil).
+			WithContext("ruc_certificado", ruc).
+			WithContext("ruc_emisor", config.RUCEmisor())
+	}
+
+	if days := info.DaysLeft(now); days <= config.DiasAvisoVencimientoCertificado && config.AvisoVencimientoCertificado != nil {
+		config.AvisoVencimientoCertificado(info, days)
+	}
+	return info, nil
+}
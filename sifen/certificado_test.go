@@ -0,0 +1,95 @@
+package sifen
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	stderrors "errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rodascaar/sifen-go-py/sifen/errors"
+)
+
+var testKey = sync.OnceValue(func() *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	return key
+})
+
+// testCertificatePEM genera un certificado autofirmado con su clave en PEM
+func testCertificatePEM(t *testing.T, serialNumber string, notAfter time.Time) string {
+	t.Helper()
+	key := testKey()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "EMPRESA DE PRUEBA S.A.", SerialNumber: serialNumber},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})) +
+		string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}))
+}
+
+func TestNewSifenClientChecksCertificate(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		serial   string
+		notAfter time.Time
+		want     *errors.SifenError
+		warning  bool
+	}{
+		{"valid", "RUC80069563-1", now.AddDate(1, 0, 0), nil, false},
+		{"expiring", "RUC80069563-1", now.AddDate(0, 0, 10), nil, true},
+		{"expired", "RUC80069563-1", now.Add(-time.Minute), errors.ErrCertificadoExpirado, false},
+		{"other RUC", "RUC80012345-0", now.AddDate(1, 0, 0), errors.ErrCertificadoRUCNoCoincide, false},
+		{"without RUC", "", now.AddDate(1, 0, 0), nil, false},
+	}
+
+	for _, tt := range tests {
+		warnedDays := -1
+		config := NewSifenConfig()
+		config.CertificadoCliente = testCertificatePEM(t, tt.serial, tt.notAfter)
+		config.RucEmisor = "80069563-1"
+		config.AvisoVencimientoCertificado = func(info CertificadoInfo, days int) { warnedDays = days }
+
+		client, err := NewSifenClient(config)
+		if tt.want != nil {
+			if !stderrors.Is(err, tt.want) {
+				t.Errorf("%s: NewSifenClient() error = %v; want %v", tt.name, err, tt.want)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: NewSifenClient() error = %v", tt.name, err)
+			continue
+		}
+
+		info, ok := client.Certificado()
+		if !ok || info.SerialNumber != "2A" || !info.NotAfter.Equal(tt.notAfter.Truncate(time.Second)) {
+			t.Errorf("%s: Certificado() = %+v, %v", tt.name, info, ok)
+		}
+		if tt.warning && (warnedDays < 9 || warnedDays > 10) {
+			t.Errorf("%s: expiry warning days = %d; want 9 or 10", tt.name, warnedDays)
+		}
+		if !tt.warning && warnedDays != -1 {
+			t.Errorf("%s: unexpected expiry warning (%d days)", tt.name, warnedDays)
+		}
+		client.Close()
+	}
+}
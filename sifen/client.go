@@ -1,9 +1,14 @@
 package sifen
 
 import (
+	"context"
+	"crypto/tls"
 	"encoding/xml"
 	"fmt"
+	"sync"
+	"time"
 
+	"github.com/rodascaar/sifen-go-py/internal/certificate"
 	"github.com/rodascaar/sifen-go-py/internal/signature"
 	"github.com/rodascaar/sifen-go-py/internal/soap"
 	"github.com/rodascaar/sifen-go-py/sifen/cache"
@@ -14,41 +19,122 @@ import (
 	"github.com/rodascaar/sifen-go-py/sifen/response"
 )
 
-// SifenClient provides methods to interact with the SIFEN API
+// SifenClient provides methods to interact with the SIFEN API.
+//
+// A SifenClient is safe for concurrent use by multiple goroutines and should be
+// shared rather than created per request. Its SifenConfig must not be modified
+// after NewSifenClient. Responses served from the cache are shared between
+// callers and must be treated as read-only.
 type SifenClient struct {
 	config     *SifenConfig
 	soapClient *soap.Client
-	requestID  int64
+	signer     *signature.Signer // nil when documents are sent unsigned
+	certInfo   *CertificadoInfo  // nil without client certificate
+	dIds       DIdGenerator
+	eventoIds  DIdGenerator
 	cache      *cache.SifenCache
+	closeOnce  sync.Once
 }
 
 // NewSifenClient creates a new SIFEN client with the given configuration
 func NewSifenClient(config *SifenConfig) (*SifenClient, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
 	soapConfig := &soap.ClientConfig{
-		TimeoutMs:          config.HttpConnectTimeout,
-		UseClientCert:      config.UsarCertificadoCliente,
-		ClientCertPath:     config.CertificadoCliente,
-		ClientCertPassword: config.ContrasenaCertificadoCliente,
-		UserAgent:          config.UserAgent,
+		TimeoutMs:             config.HttpConnectTimeout,
+		TLSHandshakeTimeoutMs: config.HttpTLSHandshakeTimeout,
+		ReadTimeoutMs:         config.HttpReadTimeout,
+		UseClientCert:         config.UsarCertificadoCliente,
+		ClientCertFormat:      certificate.Format(config.TipoCertificadoCliente),
+		ClientCertPath:        config.CertificadoCliente,
+		ClientKeyPath:         config.ClavePrivadaCliente,
+		ClientCertPassword:    config.ContrasenaCertificadoCliente,
+		UserAgent:             config.UserAgent,
+		Transport:             config.HttpTransport,
+		Middlewares:           config.Middlewares,
+	}
+
+	if config.UsarCertificadoCliente && config.CertificadoCliente == "" && config.ClaveFirma != nil {
+		// mTLS with the external key: only PKCS#1 v1.5, the one scheme DigestSigner supports
+		cert := &tls.Certificate{
+			PrivateKey:                   config.ClaveFirma,
+			Leaf:                         config.CertificadosFirma[0],
+			SupportedSignatureAlgorithms: []tls.SignatureScheme{tls.PKCS1WithSHA256},
+		}
+		for _, c := range config.CertificadosFirma {
+			cert.Certificate = append(cert.Certificate, c.Raw)
+		}
+		soapConfig.ClientCertificate = cert
+	}
+
+	if config.AuditSink != nil || config.Cassette != nil {
+		soapConfig.Middlewares = append([]soap.Middleware{}, config.Middlewares...)
+	}
+	if config.AuditSink != nil {
+		// Innermost middleware, so it records the envelope exactly as sent
+		soapConfig.Middlewares = append(soapConfig.Middlewares, auditMiddleware)
+	}
+	if config.Cassette != nil {
+		// Replaces the network, so it goes after the audit capture
+		soapConfig.Middlewares = append(soapConfig.Middlewares, config.Cassette.Middleware())
 	}
 
 	sc, err := soap.NewClient(soapConfig)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to initialize SOAP client")
 	}
+
+	// The signer is immutable and built once, so concurrent calls can share it
+	var signer *signature.Signer
+	var certInfo *CertificadoInfo
+	switch {
+	case config.ClaveFirma != nil:
+		signer, err = signature.NewKeySigner(config.ClaveFirma, config.CertificadosFirma)
+	case config.UsarCertificadoCliente:
+		if cert := sc.GetCertificate(); cert.PrivateKey != nil {
+			signer, err = signature.NewSigner(cert)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if signer != nil {
+		info, err := checkCertificado(config, signer.Certificate(), time.Now())
+		if err != nil {
+			return nil, err
+		}
+		certInfo = &info
+	}
+
+	dIds := config.DIdGenerator
+	if dIds == nil {
+		dIds = NewSequenceDIdGenerator(1)
+	}
+	eventoIds := config.EventoIdGenerator
+	if eventoIds == nil {
+		eventoIds = NewClockIdGenerator()
+	}
+
 	return &SifenClient{
 		config:     config,
 		soapClient: sc,
-		requestID:  1,
+		signer:     signer,
+		certInfo:   certInfo,
+		dIds:       dIds,
+		eventoIds:  eventoIds,
 		cache:      cache.NewSifenCacheWithConfig(config.CacheConfig),
 	}, nil
 }
 
 // Close libera recursos del cliente
 func (c *SifenClient) Close() {
-	if c.cache != nil {
-		c.cache.Close()
-	}
+	c.closeOnce.Do(func() {
+		if c.cache != nil {
+			c.cache.Close()
+		}
+	})
 }
 
 // GetConfig returns the client configuration
@@ -62,34 +148,43 @@ func (c *SifenClient) GetConfig() *SifenConfig {
 
 // ConsultaRUC queries information about a RUC (tax ID)
 func (c *SifenClient) ConsultaRUC(ruc string) (*response.RespuestaConsultaRUC, error) {
+	return c.ConsultaRUCContext(context.Background(), ruc)
+}
+
+// ConsultaRUCContext is like ConsultaRUC but honors ctx cancellation and deadline
+func (c *SifenClient) ConsultaRUCContext(ctx context.Context, ruc string) (*response.RespuestaConsultaRUC, error) {
 	// 1. Check Cache
 	if resp, found := c.cache.RUC.GetRUC(ruc); found {
 		return resp, nil
 	}
 
-	req := request.REnviConsRUC{
-		DId:      c.nextID(),
-		DRUCCons: ruc,
-	}
-
-	url := c.getURL(c.config.PathConsultaRUC)
+	var resp *response.RespuestaConsultaRUC
+	err := c.withRetry(ctx, OpConsultaRUC, func() (string, error) {
+		dId, err := c.nextID()
+		if err != nil {
+			return "", err
+		}
+		req := request.REnviConsRUC{
+			DId:      dId,
+			DRUCCons: ruc,
+		}
 
-	rawResp, err := c.soapClient.Send(url, req)
-	if err != nil {
-		return nil, errors.Wrap(err, "ConsultaRUC failed")
-	}
+		body, err := c.exchange(ctx, OpConsultaRUC, c.config.PathConsultaRUC, req, auditMeta{dId: req.DId})
+		if err != nil {
+			return "", err
+		}
 
-	var env response.EnvelopeRefResponse
-	if err := xml.Unmarshal(rawResp, &env); err != nil {
-		return nil, errors.NewSifenResponseError("XML_ERROR", fmt.Sprintf("failed to unmarshal response: %v", err))
-	}
+		if body.RResEnviConsRuc == nil {
+			return "", errors.NewSifenResponseError("EMPTY_RESPONSE", "response body is empty or invalid type")
+		}
 
-	if env.Body.RResEnviConsRuc == nil {
-		return nil, errors.NewSifenResponseError("EMPTY_RESPONSE", "response body is empty or invalid type")
+		resp = body.RResEnviConsRuc
+		return resp.DCodRes, nil
+	}, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	resp := env.Body.RResEnviConsRuc
-
 	// 2. Update Cache if successful
 	if resp.DCodRes == "0500" || resp.DCodRes == "0501" {
 		// Don't cache service errors
@@ -106,58 +201,83 @@ func (c *SifenClient) ConsultaRUC(ruc string) (*response.RespuestaConsultaRUC, e
 
 // RecepcionDE sends a single electronic document for processing
 func (c *SifenClient) RecepcionDE(de *models.DocumentoElectronico) (*response.RespuestaRecepcionDE, error) {
-	// 1. Marshal DE to XML
-	deBytes, err := xml.Marshal(de)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to marshal DE")
-	}
-
-	// 2. Sign DE if configured
-	signedBytes := deBytes
-	if c.config.UsarCertificadoCliente {
-		cert := c.soapClient.GetCertificate()
-		if cert.PrivateKey != nil {
-			signer := signature.NewSigner(cert)
-			signedBytes, err = signer.Sign(deBytes, de.DE.Id)
-			if err != nil {
-				return nil, errors.NewCryptoError("failed to sign DE", err)
-			}
-		}
-	}
+	return c.RecepcionDEContext(context.Background(), de)
+}
 
-	// 3. Wrap in Request
-	req := request.REnviDe{
-		DId: c.nextID(),
-		XDE: request.XDE{
-			RawRDE: signedBytes,
-		},
+// RecepcionDEContext is like RecepcionDE but honors ctx cancellation and deadline.
+// When OpRecepcionDE is enabled in the retry policy, the CDC is looked up with
+// ConsultaDE before every retry and the document is only resent if SIFEN did
+// not receive it.
+func (c *SifenClient) RecepcionDEContext(ctx context.Context, de *models.DocumentoElectronico) (*response.RespuestaRecepcionDE, error) {
+	// 1. Sign DE if configured and fill in the QR
+	signedBytes, err := c.signDE(de)
+	if err != nil {
+		return nil, errors.NewCryptoError("failed to sign DE", err)
 	}
 
-	url := c.getURL(c.config.PathRecibe)
+	// 2. Send, retrying only when SIFEN confirms it has not seen the CDC
+	var resp *response.RespuestaRecepcionDE
+	err = c.withRetry(ctx, OpRecepcionDE, func() (string, error) {
+		dId, err := c.nextID()
+		if err != nil {
+			return "", err
+		}
+		req := request.REnviDe{
+			DId: dId,
+			XDE: request.XDE{
+				RawRDE: signedBytes,
+			},
+		}
 
-	rawResp, err := c.soapClient.Send(url, req)
-	if err != nil {
-		return nil, errors.Wrap(err, "RecepcionDE failed")
-	}
+		body, err := c.exchange(ctx, OpRecepcionDE, c.config.PathRecibe, req, auditMeta{dId: req.DId, cdc: de.DE.Id})
+		if err != nil {
+			return "", err
+		}
 
-	var env response.EnvelopeRefResponse
-	if err := xml.Unmarshal(rawResp, &env); err != nil {
-		return nil, errors.NewSifenResponseError("XML_ERROR", fmt.Sprintf("failed to unmarshal response: %v", err))
-	}
+		if body.RRetEnviDe == nil {
+			return "", errors.NewSifenResponseError("EMPTY_RESPONSE", "response body is empty or invalid type")
+		}
 
-	if env.Body.RRetEnviDe == nil {
-		return nil, errors.NewSifenResponseError("EMPTY_RESPONSE", "response body is empty or invalid type")
+		resp = body.RRetEnviDe
+		return resp.DCodRes, nil
+	}, func(lastErr error) (bool, error) {
+		consulta, err := c.ConsultaDEContext(ctx, de.DE.Id)
+		if err != nil {
+			return false, lastErr
+		}
+		switch consulta.DCodRes {
+		case response.CodeConsultaDENoExiste:
+			return true, nil
+		case response.CodeConsultaDEEncontrado:
+			if consulta.RProtDe != nil {
+				resp = &response.RespuestaRecepcionDE{
+					BaseResponse: consulta.BaseResponse,
+					RProtDe:      *consulta.RProtDe,
+				}
+				return false, nil
+			}
+		}
+		return false, lastErr
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return env.Body.RRetEnviDe, nil
+	return resp, nil
 }
 
 // ============================================================================
 // Document Reception (Batch)
 // ============================================================================
 
-// RecepcionLoteDE sends multiple electronic documents for batch processing
+// RecepcionLoteDE sends multiple electronic documents for batch processing,
+// zipped into rEnvioLote as EnviarLoteDE does but without the same-type check
 func (c *SifenClient) RecepcionLoteDE(docs []*models.DocumentoElectronico) (*response.RespuestaRecepcionLoteDE, error) {
+	return c.RecepcionLoteDEContext(context.Background(), docs)
+}
+
+// RecepcionLoteDEContext is like RecepcionLoteDE but honors ctx cancellation and deadline
+func (c *SifenClient) RecepcionLoteDEContext(ctx context.Context, docs []*models.DocumentoElectronico) (*response.RespuestaRecepcionLoteDE, error) {
 	if len(docs) == 0 {
 		return nil, errors.ErrLoteVacio
 	}
@@ -165,51 +285,43 @@ func (c *SifenClient) RecepcionLoteDE(docs []*models.DocumentoElectronico) (*res
 		return nil, errors.ErrLoteExcedeMaximo
 	}
 
-	var xdeList []request.XDE
+	var cdcs []string
 	for _, de := range docs {
-		deBytes, err := xml.Marshal(de)
-		if err != nil {
-			return nil, errors.Wrap(err, fmt.Sprintf("failed to marshal DE %s", de.DE.Id))
-		}
-
-		// Sign if configured
-		signedBytes := deBytes
-		if c.config.UsarCertificadoCliente {
-			cert := c.soapClient.GetCertificate()
-			if cert.PrivateKey != nil {
-				signer := signature.NewSigner(cert)
-				signedBytes, err = signer.Sign(deBytes, de.DE.Id)
-				if err != nil {
-					return nil, errors.NewCryptoError(fmt.Sprintf("failed to sign DE %s", de.DE.Id), err)
-				}
-			}
-		}
-
-		xdeList = append(xdeList, request.XDE{RawRDE: signedBytes})
+		cdcs = append(cdcs, de.DE.Id)
 	}
-
-	req := request.REnviLoteDe{
-		DId:     c.nextID(),
-		XDEList: xdeList,
+	xde, err := c.comprimirLote(docs)
+	if err != nil {
+		return nil, err
 	}
 
-	url := c.getURL(c.config.PathRecibeLote)
+	var resp *response.RespuestaRecepcionLoteDE
+	err = c.withRetry(ctx, OpRecepcionLoteDE, func() (string, error) {
+		dId, err := c.nextID()
+		if err != nil {
+			return "", err
+		}
+		req := request.REnvioLote{
+			DId: dId,
+			XDE: xde,
+		}
 
-	rawResp, err := c.soapClient.Send(url, req)
-	if err != nil {
-		return nil, errors.Wrap(err, "RecepcionLoteDE failed")
-	}
+		body, err := c.exchange(ctx, OpRecepcionLoteDE, c.config.PathRecibeLote, req, auditMeta{dId: req.DId, cdcs: cdcs})
+		if err != nil {
+			return "", err
+		}
 
-	var env response.EnvelopeRefResponse
-	if err := xml.Unmarshal(rawResp, &env); err != nil {
-		return nil, errors.NewSifenResponseError("XML_ERROR", fmt.Sprintf("failed to unmarshal response: %v", err))
-	}
+		if body.RRetEnviLoteDe == nil {
+			return "", errors.NewSifenResponseError("EMPTY_RESPONSE", "response body is empty or invalid type")
+		}
 
-	if env.Body.RRetEnviLoteDe == nil {
-		return nil, errors.NewSifenResponseError("EMPTY_RESPONSE", "response body is empty or invalid type")
+		resp = body.RRetEnviLoteDe
+		return resp.DCodRes, nil
+	}, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	return env.Body.RRetEnviLoteDe, nil
+	return resp, nil
 }
 
 // ============================================================================
@@ -218,6 +330,11 @@ func (c *SifenClient) RecepcionLoteDE(docs []*models.DocumentoElectronico) (*res
 
 // ConsultaDE queries the status of a single electronic document by CDC
 func (c *SifenClient) ConsultaDE(cdc string) (*response.RespuestaConsultaDE, error) {
+	return c.ConsultaDEContext(context.Background(), cdc)
+}
+
+// ConsultaDEContext is like ConsultaDE but honors ctx cancellation and deadline
+func (c *SifenClient) ConsultaDEContext(ctx context.Context, cdc string) (*response.RespuestaConsultaDE, error) {
 	if len(cdc) != 44 {
 		return nil, errors.ErrCDCInvalido
 	}
@@ -227,29 +344,33 @@ func (c *SifenClient) ConsultaDE(cdc string) (*response.RespuestaConsultaDE, err
 		return resp, nil
 	}
 
-	req := request.REnviConsDE{
-		DId:    c.nextID(),
-		DCdCDE: cdc,
-	}
-
-	url := c.getURL(c.config.PathConsulta)
+	var resp *response.RespuestaConsultaDE
+	err := c.withRetry(ctx, OpConsultaDE, func() (string, error) {
+		dId, err := c.nextID()
+		if err != nil {
+			return "", err
+		}
+		req := request.REnviConsDE{
+			DId:    dId,
+			DCdCDE: cdc,
+		}
 
-	rawResp, err := c.soapClient.Send(url, req)
-	if err != nil {
-		return nil, errors.Wrap(err, "ConsultaDE failed")
-	}
+		body, err := c.exchange(ctx, OpConsultaDE, c.config.PathConsulta, req, auditMeta{dId: req.DId, cdc: cdc})
+		if err != nil {
+			return "", err
+		}
 
-	var env response.EnvelopeRefResponse
-	if err := xml.Unmarshal(rawResp, &env); err != nil {
-		return nil, errors.NewSifenResponseError("XML_ERROR", fmt.Sprintf("failed to unmarshal response: %v", err))
-	}
+		if body.RResEnviConsDe == nil {
+			return "", errors.NewSifenResponseError("EMPTY_RESPONSE", "response body is empty or invalid type")
+		}
 
-	if env.Body.RResEnviConsDe == nil {
-		return nil, errors.NewSifenResponseError("EMPTY_RESPONSE", "response body is empty or invalid type")
+		resp = body.RResEnviConsDe
+		return resp.DCodRes, nil
+	}, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	resp := env.Body.RResEnviConsDe
-
 	// 2. Cache if final state
 	if resp.DCodRes == "0260" || resp.DCodRes == "0530" { // Aprobado o Rechazado (estados finales)
 		c.cache.DE.SetDE(cdc, resp)
@@ -260,28 +381,39 @@ func (c *SifenClient) ConsultaDE(cdc string) (*response.RespuestaConsultaDE, err
 
 // ConsultaLoteDE queries the status of a batch of documents
 func (c *SifenClient) ConsultaLoteDE(protocoloLote string) (*response.RespuestaConsultaLoteDE, error) {
-	req := request.REnviConsLoteDe{
-		DId:           c.nextID(),
-		DProtConsLote: protocoloLote,
-	}
+	return c.ConsultaLoteDEContext(context.Background(), protocoloLote)
+}
 
-	url := c.getURL(c.config.PathConsultaLote)
+// ConsultaLoteDEContext is like ConsultaLoteDE but honors ctx cancellation and deadline
+func (c *SifenClient) ConsultaLoteDEContext(ctx context.Context, protocoloLote string) (*response.RespuestaConsultaLoteDE, error) {
+	var resp *response.RespuestaConsultaLoteDE
+	err := c.withRetry(ctx, OpConsultaLoteDE, func() (string, error) {
+		dId, err := c.nextID()
+		if err != nil {
+			return "", err
+		}
+		req := request.REnviConsLoteDe{
+			DId:           dId,
+			DProtConsLote: protocoloLote,
+		}
 
-	rawResp, err := c.soapClient.Send(url, req)
-	if err != nil {
-		return nil, err
-	}
+		body, err := c.exchange(ctx, OpConsultaLoteDE, c.config.PathConsultaLote, req, auditMeta{dId: req.DId, lote: protocoloLote})
+		if err != nil {
+			return "", err
+		}
 
-	var env response.EnvelopeRefResponse
-	if err := xml.Unmarshal(rawResp, &env); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
-	}
+		if body.RResEnviConsLoteDe == nil {
+			return "", errors.NewSifenResponseError("EMPTY_RESPONSE", "response body is empty or invalid type")
+		}
 
-	if env.Body.RResEnviConsLoteDe == nil {
-		return nil, fmt.Errorf("response body is empty or invalid type")
+		resp = body.RResEnviConsLoteDe
+		return resp.DCodRes, nil
+	}, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	return env.Body.RResEnviConsLoteDe, nil
+	return resp, nil
 }
 
 // ============================================================================
@@ -290,47 +422,51 @@ func (c *SifenClient) ConsultaLoteDE(protocoloLote string) (*response.RespuestaC
 
 // EnviarEvento sends an event to SIFEN
 func (c *SifenClient) EnviarEvento(evento *events.REvento) (*response.RespuestaEvento, error) {
-	// Marshal event to XML
-	eventoBytes, err := xml.Marshal(evento)
+	return c.EnviarEventoContext(context.Background(), evento)
+}
+
+// EnviarEventoContext is like EnviarEvento but honors ctx cancellation and deadline
+func (c *SifenClient) EnviarEventoContext(ctx context.Context, evento *events.REvento) (*response.RespuestaEvento, error) {
+	// Marshal the event inside rGesEve, where the Signature goes after rEve
+	eventoBytes, err := xml.Marshal(events.RGesEve{REve: evento})
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal event: %w", err)
 	}
 
 	// Sign event if configured
-	signedBytes := eventoBytes
-	if c.config.UsarCertificadoCliente {
-		cert := c.soapClient.GetCertificate()
-		if cert.PrivateKey != nil {
-			signer := signature.NewSigner(cert)
-			signedBytes, err = signer.Sign(eventoBytes, evento.GEvento.Id)
-			if err != nil {
-				return nil, fmt.Errorf("failed to sign event: %w", err)
-			}
-		}
+	signedBytes, err := c.sign(eventoBytes, evento.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign event: %w", err)
 	}
 
-	req := request.REnviEventoDe{
-		DId:    c.nextID(),
-		DEvReg: string(signedBytes),
-	}
+	var resp *response.RespuestaEvento
+	err = c.withRetry(ctx, OpEnviarEvento, func() (string, error) {
+		dId, err := c.nextID()
+		if err != nil {
+			return "", err
+		}
+		req := request.REnviEventoDe{
+			DId:    dId,
+			DEvReg: request.DEvReg{GGroupGesEve: request.GGroupGesEve{RawRGesEve: signedBytes}},
+		}
 
-	url := c.getURL(c.config.PathEvento)
+		body, err := c.exchange(ctx, OpEnviarEvento, c.config.PathEvento, req, auditMeta{dId: req.DId, cdc: evento.CDC()})
+		if err != nil {
+			return "", err
+		}
+
+		if body.RRetEnviEventoDe == nil {
+			return "", errors.NewSifenResponseError("EMPTY_RESPONSE", "response body is empty or invalid type")
+		}
 
-	rawResp, err := c.soapClient.Send(url, req)
+		resp = body.RRetEnviEventoDe
+		return resp.DCodRes, nil
+	}, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	var env response.EnvelopeRefResponse
-	if err := xml.Unmarshal(rawResp, &env); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
-	}
-
-	if env.Body.RRetEnviEventoDe == nil {
-		return nil, fmt.Errorf("response body is empty or invalid type")
-	}
-
-	return env.Body.RRetEnviEventoDe, nil
+	return resp, nil
 }
 
 // ============================================================================
@@ -339,8 +475,16 @@ func (c *SifenClient) EnviarEvento(evento *events.REvento) (*response.RespuestaE
 
 // CancelarDE cancels an electronic document
 func (c *SifenClient) CancelarDE(cdc, motivo string) (*response.RespuestaEvento, error) {
-	ruc, dv := c.splitRUC()
-	builder := events.NewEventBuilder(c.nextID(), ruc, dv)
+	return c.CancelarDEContext(context.Background(), cdc, motivo)
+}
+
+// CancelarDEContext is like CancelarDE but honors ctx cancellation and deadline
+func (c *SifenClient) CancelarDEContext(ctx context.Context, cdc, motivo string) (*response.RespuestaEvento, error) {
+	id, err := c.nextEventoID()
+	if err != nil {
+		return nil, err
+	}
+	builder := events.NewEventBuilder(id)
 
 	evento, err := builder.BuildCancelacion(events.EventoCancelacion{
 		CDC:    cdc,
@@ -350,39 +494,63 @@ func (c *SifenClient) CancelarDE(cdc, motivo string) (*response.RespuestaEvento,
 		return nil, err
 	}
 
-	return c.EnviarEvento(evento)
+	return c.EnviarEventoContext(ctx, evento)
 }
 
 // InutilizarNumeracion invalidates a range of document numbers
 func (c *SifenClient) InutilizarNumeracion(data events.EventoInutilizacionData) (*response.RespuestaEvento, error) {
-	ruc, dv := c.splitRUC()
-	builder := events.NewEventBuilder(c.nextID(), ruc, dv)
+	return c.InutilizarNumeracionContext(context.Background(), data)
+}
+
+// InutilizarNumeracionContext is like InutilizarNumeracion but honors ctx cancellation and deadline
+func (c *SifenClient) InutilizarNumeracionContext(ctx context.Context, data events.EventoInutilizacionData) (*response.RespuestaEvento, error) {
+	id, err := c.nextEventoID()
+	if err != nil {
+		return nil, err
+	}
+	builder := events.NewEventBuilder(id)
 
 	evento, err := builder.BuildInutilizacion(data)
 	if err != nil {
 		return nil, err
 	}
 
-	return c.EnviarEvento(evento)
+	return c.EnviarEventoContext(ctx, evento)
 }
 
 // ConfirmarRecepcion confirms receipt of a document
 func (c *SifenClient) ConfirmarRecepcion(data events.EventoConformidadData) (*response.RespuestaEvento, error) {
-	ruc, dv := c.splitRUC()
-	builder := events.NewEventBuilder(c.nextID(), ruc, dv)
+	return c.ConfirmarRecepcionContext(context.Background(), data)
+}
+
+// ConfirmarRecepcionContext is like ConfirmarRecepcion but honors ctx cancellation and deadline
+func (c *SifenClient) ConfirmarRecepcionContext(ctx context.Context, data events.EventoConformidadData) (*response.RespuestaEvento, error) {
+	id, err := c.nextEventoID()
+	if err != nil {
+		return nil, err
+	}
+	builder := events.NewEventBuilder(id)
 
 	evento, err := builder.BuildConformidad(data)
 	if err != nil {
 		return nil, err
 	}
 
-	return c.EnviarEvento(evento)
+	return c.EnviarEventoContext(ctx, evento)
 }
 
 // ReportarDisconformidad reports non-conformity of a document
 func (c *SifenClient) ReportarDisconformidad(cdc, motivo string) (*response.RespuestaEvento, error) {
-	ruc, dv := c.splitRUC()
-	builder := events.NewEventBuilder(c.nextID(), ruc, dv)
+	return c.ReportarDisconformidadContext(context.Background(), cdc, motivo)
+}
+
+// ReportarDisconformidadContext is like ReportarDisconformidad but honors ctx cancellation and deadline
+func (c *SifenClient) ReportarDisconformidadContext(ctx context.Context, cdc, motivo string) (*response.RespuestaEvento, error) {
+	id, err := c.nextEventoID()
+	if err != nil {
+		return nil, err
+	}
+	builder := events.NewEventBuilder(id)
 
 	evento, err := builder.BuildDisconformidad(events.EventoDisconformidadData{
 		CDC:    cdc,
@@ -392,16 +560,140 @@ func (c *SifenClient) ReportarDisconformidad(cdc, motivo string) (*response.Resp
 		return nil, err
 	}
 
-	return c.EnviarEvento(evento)
+	return c.EnviarEventoContext(ctx, evento)
 }
 
 // ============================================================================
 // Helper Methods
 // ============================================================================
 
-func (c *SifenClient) nextID() int64 {
-	c.requestID++
-	return c.requestID
+// exchange sends req to the given endpoint path and decodes the SOAP response body.
+// Every call is reported to the configured AuditSink, and op is available to
+// middlewares through OperacionFromContext.
+func (c *SifenClient) exchange(ctx context.Context, op Operacion, path string, req interface{}, meta auditMeta) (*response.BodyRefResponse, error) {
+	ctx = context.WithValue(ctx, operacionKey{}, op)
+	if t, ok := c.config.OperationTimeouts[op]; ok {
+		ctx = soap.WithTimeouts(ctx, t)
+	}
+
+	var capture *auditCapture
+	if c.config.AuditSink != nil {
+		capture = &auditCapture{}
+		ctx = context.WithValue(ctx, auditCaptureKey{}, capture)
+	}
+
+	start := time.Now()
+	body, err := c.decodeExchange(ctx, op, path, req)
+
+	if capture != nil {
+		c.audit(op, meta, capture, start, body, err)
+	}
+	return body, err
+}
+
+func (c *SifenClient) decodeExchange(ctx context.Context, op Operacion, path string, req interface{}) (*response.BodyRefResponse, error) {
+	rawResp, err := c.soapClient.SendContext(ctx, c.getURL(path), req)
+	if err != nil {
+		// SIFEN answers faults with HTTP 500; report the fault rather than the status
+		if fault := response.ParseFault(rawResp); fault != nil {
+			return nil, newFaultError(fault).WithContext("http_error", err)
+		}
+		return nil, errors.Wrap(err, string(op)+" failed")
+	}
+	return decodeResponse(rawResp)
+}
+
+// decodeResponse decodes a SOAP response envelope, turning faults into errors
+func decodeResponse(rawResp []byte) (*response.BodyRefResponse, error) {
+	var env response.EnvelopeRefResponse
+	if err := xml.Unmarshal(rawResp, &env); err != nil {
+		return nil, errors.NewSifenResponseError("XML_ERROR", fmt.Sprintf("failed to unmarshal response: %v", err))
+	}
+
+	if env.Body.Fault != nil {
+		return nil, newFaultError(env.Body.Fault)
+	}
+
+	return &env.Body, nil
+}
+
+// audit builds the AuditRecord of one exchange and hands it to the AuditSink
+func (c *SifenClient) audit(op Operacion, meta auditMeta, capture *auditCapture, start time.Time, body *response.BodyRefResponse, err error) {
+	rec := &AuditRecord{
+		Operation:  op,
+		DId:        meta.dId,
+		CDC:        meta.cdc,
+		CDCs:       meta.cdcs,
+		Lote:       meta.lote,
+		Timestamp:  start,
+		Duration:   time.Since(start),
+		Outcome:    AuditOutcomeOK,
+		URL:        capture.url,
+		HTTPStatus: capture.status,
+		Request:    capture.envelope,
+		Response:   capture.body,
+	}
+
+	if body != nil {
+		rec.ResponseCode = body.Code()
+		if rec.Lote == "" && body.RRetEnviLoteDe != nil {
+			rec.Lote = body.RRetEnviLoteDe.DProtConsLot
+		}
+	}
+	if err != nil {
+		rec.Outcome = AuditOutcomeError
+		rec.Error = err.Error()
+		if se, ok := errors.AsSifenError(err); ok && se.Context["fault_code"] != nil {
+			rec.Outcome = AuditOutcomeFault
+			rec.ResponseCode = se.Code
+		}
+	}
+
+	// Audit failures must not turn a successful SIFEN call into an error
+	_ = c.config.AuditSink.Record(rec)
+}
+
+func newFaultError(fault *response.Fault) *errors.SifenError {
+	return errors.NewSoapFaultError(fault.MostSpecificCode(), fault.ReasonText(), fault.DetailXML()).
+		WithContext("fault_codes", fault.Codes())
+}
+
+// nextID returns the dId of a new request
+func (c *SifenClient) nextID() (int64, error) {
+	id, err := c.dIds.NextDId()
+	if err != nil {
+		return 0, errors.NewInternalError("failed to generate dId", err)
+	}
+	return id, nil
+}
+
+// nextEventoID returns the Id of a new event. It does not come from the dId
+// sequence: SIFEN rejects a repeated event Id, and the default dId sequence
+// starts over at 1 on every restart
+func (c *SifenClient) nextEventoID() (int64, error) {
+	id, err := c.eventoIds.NextDId()
+	if err != nil {
+		return 0, errors.NewInternalError("failed to generate event Id", err)
+	}
+	if id < 1 || id > MaxEventoId {
+		return 0, errors.NewInternalError("failed to generate event Id", fmt.Errorf("event Id %d exceeds 10 digits", id))
+	}
+	return id, nil
+}
+
+// sign signs the element with the given Id when a client certificate is configured
+func (c *SifenClient) sign(xmlBytes []byte, id string) ([]byte, error) {
+	if c.signer == nil {
+		return xmlBytes, nil
+	}
+	signed, err := c.signer.Sign(xmlBytes, id)
+	if err != nil || !c.config.VerificarFirma {
+		return signed, err
+	}
+	if _, err := signature.Verify(signed); err != nil {
+		return nil, err
+	}
+	return signed, nil
 }
 
 func (c *SifenClient) getURL(path string) string {
@@ -410,10 +702,3 @@ func (c *SifenClient) getURL(path string) string {
 	}
 	return c.config.UrlBaseLocal + path
 }
-
-func (c *SifenClient) splitRUC() (ruc, dv string) {
-	// Extract RUC and DV from config if available
-	// This is a simplified version - in production would need proper extraction
-	// Assuming RUC is stored in CSC or needs to be passed via config
-	return c.config.IdCSC, "0" // Placeholder - should be properly configured
-}
@@ -0,0 +1,164 @@
+package sifen
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/rodascaar/sifen-go-py/sifen/errors"
+	"github.com/rodascaar/sifen-go-py/sifen/models"
+	"github.com/rodascaar/sifen-go-py/sifen/types"
+)
+
+const testFault = `<env:Fault>
+<env:Code><env:Value>env:Sender</env:Value><env:Subcode><env:Value>ns1:0160</env:Value></env:Subcode></env:Code>
+<env:Reason><env:Text xml:lang="es">CDC duplicado</env:Text></env:Reason>
+<env:Detail><ns1:info xmlns:ns1="urn:test">dup</ns1:info></env:Detail>
+</env:Fault>`
+
+func TestSoapFaultIsReported(t *testing.T) {
+	for _, status := range []int{http.StatusOK, http.StatusInternalServerError} {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			fmt.Fprintf(w, soapEnvelopeFmt, testFault)
+		})
+		client := newTestClient(t, handler, fastPolicy())
+
+		_, err := client.ConsultaDE(strings.Repeat("1", 44))
+		se, ok := errors.AsSifenError(err)
+		if !ok {
+			t.Fatalf("status %d: error = %v; want *SifenError", status, err)
+		}
+		if se.Type != errors.ErrorTypeSIFEN || se.Code != "0160" || se.Message != "CDC duplicado" {
+			t.Errorf("status %d: got type=%s code=%s message=%q", status, se.Type, se.Code, se.Message)
+		}
+		if detail, _ := se.Context["fault_detail"].(string); !strings.Contains(detail, "<ns1:info") {
+			t.Errorf("status %d: fault_detail = %q", status, detail)
+		}
+	}
+}
+
+func TestEmptyResponseIsTyped(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, soapEnvelopeFmt, "")
+	})
+	client := newTestClient(t, handler, fastPolicy())
+	client.config.RucEmisor = "80069563"
+	client.config.DvEmisor = "1"
+
+	de := models.NewDE(strings.Repeat("1", 44))
+	de.DE.GTimb.ITiDE = types.TTiDE_FacturaElectronica
+	calls := map[string]func() error{
+		"ConsultaLoteDE": func() error { _, err := client.ConsultaLoteDE("123"); return err },
+		"ConsultarResultadoLote": func() error {
+			_, err := client.ConsultarResultadoLote("123")
+			return err
+		},
+		"EnviarLoteDE": func() error {
+			_, err := client.EnviarLoteDE(LoteParams{Documentos: []*models.DocumentoElectronico{de}, TipoDocumento: types.TTiDE_FacturaElectronica})
+			return err
+		},
+		"CancelarDE": func() error { _, err := client.CancelarDE(strings.Repeat("1", 44), "Error de carga"); return err },
+	}
+	for name, call := range calls {
+		se, ok := errors.AsSifenError(call())
+		if !ok || se.Code != "EMPTY_RESPONSE" || se.Type != errors.ErrorTypeSIFEN {
+			t.Errorf("%s: error = %v; want EMPTY_RESPONSE", name, se)
+		}
+	}
+}
+
+func TestNewSifenClientValidatesEmisor(t *testing.T) {
+	tests := []struct {
+		name    string
+		ruc, dv string
+		want    *errors.SifenError
+	}{
+		{"valid", "80069563", "1", nil},
+		{"combined", "80069563-1", "", nil},
+		{"wrong dv", "80069563", "2", errors.ErrRUCDigitoVerificador},
+		{"not numeric", "8006956A", "1", errors.ErrRUCInvalido},
+	}
+
+	for _, tt := range tests {
+		config := NewSifenConfig()
+		config.UsarCertificadoCliente = false
+		config.RucEmisor = tt.ruc
+		config.DvEmisor = tt.dv
+
+		client, err := NewSifenClient(config)
+		if tt.want == nil {
+			if err != nil {
+				t.Errorf("%s: NewSifenClient() error = %v", tt.name, err)
+				continue
+			}
+			client.Close()
+			if config.RUCEmisor() != "80069563-1" {
+				t.Errorf("%s: RUCEmisor() = %q; want 80069563-1", tt.name, config.RUCEmisor())
+			}
+			continue
+		}
+		if !stderrors.Is(err, tt.want) {
+			t.Errorf("%s: NewSifenClient() error = %v; want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestCancelarDESendsRGesEve(t *testing.T) {
+	var body string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		fmt.Fprintf(w, soapEnvelopeFmt, "<rRetEnviEventoDe><dCodRes>0600</dCodRes></rRetEnviEventoDe>")
+	})
+
+	// El evento no lleva RUC, así que no requiere RucEmisor
+	client := newTestClient(t, handler, fastPolicy())
+	if _, err := client.CancelarDE(strings.Repeat("1", 44), "Error de carga"); err != nil {
+		t.Fatalf("CancelarDE() error = %v", err)
+	}
+	// dEvReg lleva rGesEve como XML; el emisor se identifica por la firma
+	want := "<dEvReg><gGroupGesEve><rGesEve xmlns=\"http://ekuatia.set.gov.py/sifen/xsd\"><rEve"
+	if !strings.Contains(body, want) || !strings.Contains(body, "<rGeVeCan><Id>"+strings.Repeat("1", 44)+"</Id><mOtEve>Error de carga</mOtEve></rGeVeCan>") {
+		t.Errorf("event is not sent as rGesEve: %s", body)
+	}
+}
+
+func TestNewSifenClientLoadsPEMCertificate(t *testing.T) {
+	chain, err := os.ReadFile("../internal/certificate/testdata/chain.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		password string
+		want     *errors.SifenError
+	}{
+		{"secreto", nil},
+		{"incorrecta", errors.ErrContrasenaCertificado},
+	} {
+		config := NewSifenConfig()
+		config.CertificadoCliente = string(chain)
+		config.ClavePrivadaCliente = "../internal/certificate/testdata/key-encrypted.pem"
+		config.ContrasenaCertificadoCliente = tt.password
+
+		client, err := NewSifenClient(config)
+		if tt.want != nil {
+			if !stderrors.Is(err, tt.want) {
+				t.Errorf("password %q: NewSifenClient() error = %v; want %v", tt.password, err, tt.want)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("NewSifenClient() error = %v", err)
+		}
+		if client.signer == nil || len(client.signer.Chain()) != 2 {
+			t.Errorf("signer does not hold the PEM certificate chain")
+		}
+		client.Close()
+	}
+}
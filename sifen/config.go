@@ -1,10 +1,21 @@
 package sifen
 
 import (
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
 	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/rodascaar/sifen-go-py/internal/util"
 	"github.com/rodascaar/sifen-go-py/sifen/cache"
+	"github.com/rodascaar/sifen-go-py/sifen/errors"
+	"github.com/rodascaar/sifen-go-py/sifen/models"
+	"github.com/rodascaar/sifen-go-py/sifen/types"
 )
 
 type TipoAmbiente string
@@ -18,6 +29,7 @@ type TipoCertificadoCliente string
 
 const (
 	TipoCertificadoClientePFX TipoCertificadoCliente = "PFX"
+	TipoCertificadoClientePEM TipoCertificadoCliente = "PEM"
 )
 
 const (
@@ -43,20 +55,78 @@ type SifenConfig struct {
 	PathConsultaRUC  string
 	PathConsulta     string
 
-	UsarCertificadoCliente       bool
-	TipoCertificadoCliente       TipoCertificadoCliente
-	CertificadoCliente           string
+	UsarCertificadoCliente bool
+	// TipoCertificadoCliente vacío detecta PEM por sus marcadores "-----BEGIN"
+	TipoCertificadoCliente TipoCertificadoCliente
+	// CertificadoCliente es una ruta, contenido PEM o contenido Base64 del PFX.
+	// En PEM puede incluir la cadena completa y la clave privada.
+	CertificadoCliente string
+	// ClavePrivadaCliente es la clave PEM (ruta o contenido) cuando no está en CertificadoCliente
+	ClavePrivadaCliente string
+	// ContrasenaCertificadoCliente descifra el PFX o la clave PEM cifrada (PKCS#8)
 	ContrasenaCertificadoCliente string
 
+	// Firma con una clave externa (HSM, PKCS#11, KMS) en lugar de la clave del
+	// certificado cliente. CertificadosFirma lleva el certificado del firmante
+	// primero y luego la cadena. Si CertificadoCliente está vacío, la misma clave
+	// se usa para la conexión TLS.
+	ClaveFirma        crypto.Signer
+	CertificadosFirma []*x509.Certificate
+
+	// VerificarFirma verifica cada documento firmado antes de enviarlo
+	VerificarFirma bool
+
+	// Aviso de vencimiento: NewSifenClient llama a AvisoVencimientoCertificado
+	// cuando faltan DiasAvisoVencimientoCertificado días o menos para que venza
+	DiasAvisoVencimientoCertificado int
+	AvisoVencimientoCertificado     func(info CertificadoInfo, diasRestantes int)
+
 	IdCSC string
 	CSC   string
 
-	HttpConnectTimeout int // Milliseconds
-	HttpReadTimeout    int // Milliseconds
-	UserAgent          string
+	// Identidad del emisor, usada al completar documentos y para comprobar el
+	// RUC del certificado. Los eventos no la llevan: SIFEN los asocia al
+	// emisor por la firma.
+	// RucEmisor va sin DV; también se acepta "80069563-1" dejando DvEmisor vacío.
+	RucEmisor              string
+	DvEmisor               string
+	TipoContribuyente      types.TiTipCont
+	EstablecimientoDefecto string // Código de 3 dígitos, ej. "001"
+
+	HttpConnectTimeout      int // Milliseconds
+	HttpTLSHandshakeTimeout int // Milliseconds (0 = HttpConnectTimeout)
+	HttpReadTimeout         int // Milliseconds, desde el envío hasta leer toda la respuesta
+	UserAgent               string
+
+	// Timeouts por operación; los valores en cero usan los generales.
+	// Por defecto los lotes (hasta 10 MB) tienen más tiempo de lectura.
+	OperationTimeouts map[Operacion]OperationTimeouts
+
+	// Transporte HTTP opcional (ej. proxy corporativo) y middlewares SOAP. Con
+	// UsarCertificadoCliente debe ser un *http.Transport, al que se agrega el
+	// certificado; otro RoundTripper es un error de configuración.
+	HttpTransport http.RoundTripper
+	Middlewares   []Middleware
 
 	// Configuración de Caché
 	CacheConfig cache.CacheConfig
+
+	// Política de reintentos ante errores recuperables
+	RetryPolicy RetryPolicy
+
+	// Registro de auditoría de cada intercambio con SIFEN (opcional)
+	AuditSink AuditSink
+
+	// Generador de dId (opcional, por defecto una secuencia en memoria desde 1)
+	DIdGenerator DIdGenerator
+
+	// Generador del Id de los eventos (opcional, por defecto ClockIdGenerator).
+	// SIFEN no admite Id repetidos del mismo emisor; con varios procesos
+	// usar un generador persistente y distinto por proceso.
+	EventoIdGenerator DIdGenerator
+
+	// Grabación/reproducción de intercambios para tests de regresión (opcional)
+	Cassette *Cassette
 }
 
 func NewSifenConfig() *SifenConfig {
@@ -79,8 +149,15 @@ func NewSifenConfig() *SifenConfig {
 		HttpConnectTimeout: 15 * 1000,
 		HttpReadTimeout:    45 * 1000,
 		UserAgent:          "rshk-jsifenlib/" + SDK_CURRENT_VERSION + " (GoPort)",
+		OperationTimeouts: map[Operacion]OperationTimeouts{
+			OpRecepcionLoteDE: {Read: 2 * time.Minute},
+			OpEnviarLoteDE:    {Read: 2 * time.Minute},
+		},
 
 		CacheConfig: cache.DefaultCacheConfig(),
+		RetryPolicy: DefaultRetryPolicy(),
+
+		DiasAvisoVencimientoCertificado: 30,
 	}
 	return cfg
 }
@@ -101,6 +178,222 @@ func (c *SifenConfig) SetIdCSC(id string) {
 	c.IdCSC = util.LeftPad(id, '0', 4)
 }
 
+// CSC de prueba publicados por la SET para el ambiente de test
+var testCSCs = map[string]bool{
+	"ABCD0000000000000000000000000000": true,
+	"EFGH0000000000000000000000000000": true,
+}
+
+// Validate verifica la configuración antes de crear el cliente: rutas de servicio,
+// formato del CSC e IdCSC, identidad del emisor y que el certificado sea legible.
+// En producción rechaza el CSC de prueba y las URLs del ambiente de test.
+// Acepta RucEmisor con DV ("80069563-1") y DvEmisor vacío. No modifica la
+// configuración.
+func (c *SifenConfig) Validate() error {
+	paths := []struct{ name, value string }{
+		{"PathRecibe", c.PathRecibe},
+		{"PathRecibeLote", c.PathRecibeLote},
+		{"PathEvento", c.PathEvento},
+		{"PathConsultaLote", c.PathConsultaLote},
+		{"PathConsultaRUC", c.PathConsultaRUC},
+		{"PathConsulta", c.PathConsulta},
+	}
+	for _, p := range paths {
+		if !strings.HasPrefix(p.value, "/") {
+			return errors.ErrConfigPathRequerido.WithCause(nil).WithContext("campo", p.name)
+		}
+	}
+	if c.UrlBase == "" && c.UrlBaseLocal == "" {
+		return errors.ErrConfigInvalida.WithCause(fmt.Errorf("UrlBase o UrlBaseLocal requerida"))
+	}
+
+	if !isDigits(c.IdCSC, 4) {
+		return errors.ErrConfigIdCSCInvalido.WithCause(nil).WithContext("idCSC", c.IdCSC)
+	}
+	if len(c.CSC) != 32 || !isAlphanumeric(c.CSC) {
+		return errors.ErrConfigCSCInvalido.WithCause(nil)
+	}
+
+	if c.HttpConnectTimeout < 0 || c.HttpTLSHandshakeTimeout < 0 || c.HttpReadTimeout < 0 {
+		return errors.ErrConfigInvalida.WithCause(fmt.Errorf("los timeouts no pueden ser negativos"))
+	}
+	if c.ClaveFirma != nil && len(c.CertificadosFirma) == 0 {
+		return errors.ErrCertificadoNoEncontrado.WithCause(fmt.Errorf("ClaveFirma requiere CertificadosFirma"))
+	}
+	if c.DiasAvisoVencimientoCertificado < 0 {
+		return errors.ErrConfigInvalida.WithCause(fmt.Errorf("DiasAvisoVencimientoCertificado no puede ser negativo"))
+	}
+	for op, t := range c.OperationTimeouts {
+		if t.Connect < 0 || t.Read < 0 {
+			return errors.ErrConfigInvalida.WithCause(fmt.Errorf("los timeouts de %s no pueden ser negativos", op))
+		}
+	}
+
+	if c.Ambiente == TipoAmbienteProd {
+		if testCSCs[c.CSC] {
+			return errors.ErrConfigProdCSCPrueba.WithCause(nil)
+		}
+		for _, rawURL := range []string{c.UrlBase, c.UrlBaseLocal, c.UrlConsultaQr} {
+			if isTestURL(rawURL) {
+				return errors.ErrConfigProdURLPrueba.WithCause(nil).WithContext("url", rawURL)
+			}
+		}
+	}
+
+	if err := c.validateEmisor(); err != nil {
+		return err
+	}
+
+	if c.UsarCertificadoCliente {
+		switch c.TipoCertificadoCliente {
+		case "", TipoCertificadoClientePFX, TipoCertificadoClientePEM:
+		default:
+			return errors.ErrConfigInvalida.WithCause(
+				fmt.Errorf("TipoCertificadoCliente desconocido: %q", c.TipoCertificadoCliente))
+		}
+		if c.CertificadoCliente != "" || c.ClaveFirma == nil {
+			if err := checkCertificateReadable(c.CertificadoCliente); err != nil {
+				return err
+			}
+		}
+		if c.ClavePrivadaCliente != "" {
+			if err := checkCertificateReadable(c.ClavePrivadaCliente); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isTestURL indica si rawURL apunta a los servicios de test de la SET: el host
+// de URL_BASE_DEV o la ruta de consulta QR de URL_CONSULTA_QR_DEV
+func isTestURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	dev, _ := url.Parse(URL_BASE_DEV)
+	qr, _ := url.Parse(URL_CONSULTA_QR_DEV)
+	switch strings.ToLower(u.Hostname()) {
+	case dev.Hostname():
+		return true
+	case qr.Hostname():
+		return strings.HasPrefix(u.Path, qr.Path)
+	}
+	return false
+}
+
+// checkCertificateReadable verifica que el certificado sea un archivo legible, contenido PEM o Base64 válido
+func checkCertificateReadable(pathOrBase64 string) error {
+	if pathOrBase64 == "" {
+		return errors.ErrCertificadoNoEncontrado.WithCause(fmt.Errorf("CertificadoCliente no configurado"))
+	}
+	if strings.Contains(pathOrBase64, "-----BEGIN ") {
+		return nil
+	}
+
+	if _, err := os.Stat(pathOrBase64); err == nil {
+		f, err := os.Open(pathOrBase64)
+		if err != nil {
+			return errors.ErrCertificadoNoEncontrado.WithCause(err).WithContext("path", pathOrBase64)
+		}
+		return f.Close()
+	}
+
+	if _, err := base64.StdEncoding.DecodeString(pathOrBase64); err != nil {
+		return errors.ErrCertificadoNoEncontrado.WithCause(
+			fmt.Errorf("CertificadoCliente no es un archivo existente ni contenido PEM o Base64"))
+	}
+	return nil
+}
+
+// RUCEmisor retorna el RUC del emisor con su DV (formato "80069563-1")
+func (c *SifenConfig) RUCEmisor() string {
+	ruc, dv := c.emisor()
+	if ruc == "" {
+		return ""
+	}
+	return ruc + "-" + dv
+}
+
+// emisor retorna el RUC y el DV del emisor. RucEmisor puede traer el DV
+// ("80069563-1") cuando DvEmisor está vacío; la configuración no se modifica.
+func (c *SifenConfig) emisor() (ruc, dv string) {
+	if c.DvEmisor == "" && strings.Contains(c.RucEmisor, "-") {
+		if base, dv, err := util.SplitRUC(c.RucEmisor); err == nil {
+			return base, dv
+		}
+	}
+	return c.RucEmisor, c.DvEmisor
+}
+
+// validateEmisor valida la identidad del emisor si fue configurada
+func (c *SifenConfig) validateEmisor() error {
+	if c.DvEmisor == "" && strings.Contains(c.RucEmisor, "-") {
+		if _, _, err := util.SplitRUC(c.RucEmisor); err != nil {
+			return errors.ErrRUCInvalido.WithCause(err).WithContext("ruc", c.RucEmisor)
+		}
+	}
+
+	if c.RucEmisor != "" || c.DvEmisor != "" {
+		ok, err := util.ValidateRUC(c.RUCEmisor())
+		if err != nil {
+			return errors.ErrRUCInvalido.WithCause(err).WithContext("ruc", c.RUCEmisor())
+		}
+		if !ok {
+			return errors.ErrRUCDigitoVerificador.WithCause(nil).WithContext("ruc", c.RUCEmisor())
+		}
+	}
+
+	switch c.TipoContribuyente {
+	case 0, types.TiTipCont_PersonaFisica, types.TiTipCont_PersonaJuridica:
+	default:
+		return errors.NewValidationError("VAL_002",
+			fmt.Sprintf("Tipo de contribuyente inválido: %d", c.TipoContribuyente))
+	}
+
+	if c.EstablecimientoDefecto != "" && !isDigits(c.EstablecimientoDefecto, 3) {
+		return errors.ErrEstablecimientoInvalido.WithCause(nil).WithContext("establecimiento", c.EstablecimientoDefecto)
+	}
+	return nil
+}
+
+// ApplyEmisor completa los datos del emisor configurados que estén vacíos en de:
+// RUC, DV y tipo de contribuyente del emisor, y el establecimiento del timbrado
+func (c *SifenConfig) ApplyEmisor(de *models.DocumentoElectronico) {
+	emis := &de.DE.GDatGralOpe.GEmis
+	if emis.DRucEm == "" {
+		emis.DRucEm, emis.DDVEmi = c.emisor()
+	}
+	if emis.ITipCont == 0 {
+		emis.ITipCont = c.TipoContribuyente
+	}
+	if de.DE.GTimb.DEst == "" {
+		de.DE.GTimb.DEst = c.EstablecimientoDefecto
+	}
+}
+
+func isAlphanumeric(s string) bool {
+	for _, r := range s {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigits(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
 func (c *SifenConfig) String() string {
 	return fmt.Sprintf("SifenConfig{Ambiente=%s, UrlBase=%s, ...}", c.Ambiente, c.UrlBase)
 }
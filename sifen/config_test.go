@@ -0,0 +1,91 @@
+package sifen
+
+import (
+	stderrors "errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rodascaar/sifen-go-py/sifen/errors"
+)
+
+func TestSifenConfigValidate(t *testing.T) {
+	certPath := filepath.Join(t.TempDir(), "cert.pfx")
+	if err := os.WriteFile(certPath, []byte("pfx"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	prod := func(c *SifenConfig) {
+		c.SetAmbiente(TipoAmbienteProd)
+		c.CSC = "A1B2C3D4E5F6A1B2C3D4E5F6A1B2C3D4"
+	}
+
+	tests := []struct {
+		name   string
+		modify func(c *SifenConfig)
+		want   *errors.SifenError
+	}{
+		{"defaults", func(c *SifenConfig) {}, nil},
+		{"production", prod, nil},
+		{"missing path", func(c *SifenConfig) { c.PathConsulta = "" }, errors.ErrConfigPathRequerido},
+		{"short CSC", func(c *SifenConfig) { c.CSC = "ABCD" }, errors.ErrConfigCSCInvalido},
+		{"CSC with symbols", func(c *SifenConfig) { c.CSC = "ABCD000000000000000000000000000-" }, errors.ErrConfigCSCInvalido},
+		{"IdCSC not padded", func(c *SifenConfig) { c.IdCSC = "2" }, errors.ErrConfigIdCSCInvalido},
+		{"production with test CSC", func(c *SifenConfig) {
+			prod(c)
+			c.CSC = "EFGH0000000000000000000000000000"
+		}, errors.ErrConfigProdCSCPrueba},
+		{"production with test URL", func(c *SifenConfig) {
+			prod(c)
+			c.UrlBase = URL_BASE_DEV
+		}, errors.ErrConfigProdURLPrueba},
+		{"production with test QR URL", func(c *SifenConfig) {
+			prod(c)
+			c.UrlConsultaQr = URL_CONSULTA_QR_DEV
+		}, errors.ErrConfigProdURLPrueba},
+		{"production with test host in other case", func(c *SifenConfig) {
+			prod(c)
+			c.UrlBase = "https://SIFEN-TEST.set.gov.py/de/ws"
+		}, errors.ErrConfigProdURLPrueba},
+		{"production behind a proxy named -test", func(c *SifenConfig) {
+			prod(c)
+			c.UrlBase = "https://sifen-proxy.empresa.com.py/sifen-testigo"
+		}, nil},
+		{"missing certificate", func(c *SifenConfig) {
+			c.UsarCertificadoCliente = true
+			c.CertificadoCliente = filepath.Join(t.TempDir(), "missing.pfx")
+		}, errors.ErrCertificadoNoEncontrado},
+	}
+
+	for _, tt := range tests {
+		config := NewSifenConfig()
+		config.CertificadoCliente = certPath
+		tt.modify(config)
+
+		err := config.Validate()
+		if tt.want == nil {
+			if err != nil {
+				t.Errorf("%s: Validate() error = %v", tt.name, err)
+			}
+			continue
+		}
+		if !stderrors.Is(err, tt.want) {
+			t.Errorf("%s: Validate() error = %v; want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestSifenConfigValidateHasNoSideEffects(t *testing.T) {
+	config := NewSifenConfig()
+	config.UsarCertificadoCliente = false
+	config.RucEmisor = "80069563-1"
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if config.RucEmisor != "80069563-1" || config.DvEmisor != "" {
+		t.Errorf("Validate() modified the config: RucEmisor=%q DvEmisor=%q", config.RucEmisor, config.DvEmisor)
+	}
+	if got := config.RUCEmisor(); got != "80069563-1" {
+		t.Errorf("RUCEmisor() = %q; want 80069563-1", got)
+	}
+}
@@ -0,0 +1,190 @@
+package sifen
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ============================================================================
+// Generación de dId
+// ============================================================================
+
+// MaxDId es el mayor dId admitido por SIFEN (hasta 15 dígitos)
+const MaxDId int64 = 999999999999999
+
+// DIdGenerator genera el dId de cada solicitud enviada a SIFEN.
+// Las implementaciones deben ser seguras para uso concurrente.
+type DIdGenerator interface {
+	NextDId() (int64, error)
+}
+
+// SequenceDIdGenerator es una secuencia en memoria; es el generador por defecto
+type SequenceDIdGenerator struct {
+	last int64
+}
+
+// NewSequenceDIdGenerator crea una secuencia cuyo primer valor es start
+func NewSequenceDIdGenerator(start int64) *SequenceDIdGenerator {
+	return &SequenceDIdGenerator{last: start - 1}
+}
+
+// NextDId implementa DIdGenerator
+func (g *SequenceDIdGenerator) NextDId() (int64, error) {
+	next := atomic.AddInt64(&g.last, 1)
+	if next > MaxDId {
+		return 0, fmt.Errorf("secuencia de dId agotada (%d)", next)
+	}
+	return next, nil
+}
+
+// PrefixDIdGenerator antepone un prefijo fijo (ej. número de nodo o proceso) a
+// los valores de otro generador, para que varios procesos generen dId distintos:
+// dId = Prefix * 10^Digits + secuencia
+type PrefixDIdGenerator struct {
+	Prefix   int64
+	Digits   int
+	Sequence DIdGenerator
+}
+
+// NewPrefixDIdGenerator valida que prefix seguido de digits dígitos quepa en un dId
+func NewPrefixDIdGenerator(prefix int64, digits int, sequence DIdGenerator) (*PrefixDIdGenerator, error) {
+	if prefix < 0 || digits < 1 || digits >= 15 {
+		return nil, fmt.Errorf("prefijo %d con %d dígitos de secuencia inválido", prefix, digits)
+	}
+	if prefix > MaxDId/pow10(digits) {
+		return nil, fmt.Errorf("prefijo %d con %d dígitos de secuencia excede los 15 dígitos del dId", prefix, digits)
+	}
+	return &PrefixDIdGenerator{Prefix: prefix, Digits: digits, Sequence: sequence}, nil
+}
+
+// NextDId implementa DIdGenerator
+func (g *PrefixDIdGenerator) NextDId() (int64, error) {
+	seq, err := g.Sequence.NextDId()
+	if err != nil {
+		return 0, err
+	}
+	limit := pow10(g.Digits)
+	if seq >= limit {
+		return 0, fmt.Errorf("secuencia de dId %d excede %d dígitos", seq, g.Digits)
+	}
+	return g.Prefix*limit + seq, nil
+}
+
+// fileDIdBlock es la cantidad de dId reservados por cada escritura del archivo
+const fileDIdBlock = 100
+
+// FileDIdGenerator es una secuencia persistida en un archivo, de modo que un
+// proceso reiniciado no repite dId. Reserva bloques de valores para no escribir
+// el archivo en cada solicitud; los valores no usados de un bloque se descartan
+// al reiniciar. Cada proceso debe usar su propio archivo.
+type FileDIdGenerator struct {
+	path string
+
+	mu    sync.Mutex
+	next  int64
+	limit int64 // último valor reservado en el archivo
+}
+
+// NewFileDIdGenerator abre la secuencia guardada en path; si no existe comienza en 1
+func NewFileDIdGenerator(path string) (*FileDIdGenerator, error) {
+	g := &FileDIdGenerator{path: path}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return nil, fmt.Errorf("no se pudo leer la secuencia de dId: %w", err)
+	default:
+		last, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("secuencia de dId inválida en %s: %w", path, err)
+		}
+		g.limit = last
+	}
+	g.next = g.limit + 1
+	return g, nil
+}
+
+// NextDId implementa DIdGenerator
+func (g *FileDIdGenerator) NextDId() (int64, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.next > MaxDId {
+		return 0, fmt.Errorf("secuencia de dId agotada (%d)", g.next)
+	}
+	if g.next > g.limit {
+		limit := g.next + fileDIdBlock - 1
+		if limit > MaxDId {
+			limit = MaxDId
+		}
+		if err := writeFileAtomic(g.path, []byte(strconv.FormatInt(limit, 10))); err != nil {
+			return 0, fmt.Errorf("no se pudo persistir la secuencia de dId: %w", err)
+		}
+		g.limit = limit
+	}
+
+	id := g.next
+	g.next++
+	return id, nil
+}
+
+// ============================================================================
+// Generación del Id de eventos
+// ============================================================================
+
+// MaxEventoId es el mayor Id de evento admitido por SIFEN (tIdEvento, hasta
+// 10 dígitos)
+const MaxEventoId int64 = 9999999999
+
+// clockIdEpoch es el origen de ClockIdGenerator; con décimas de segundo los
+// 10 dígitos del Id de evento alcanzan hasta 2055
+var clockIdEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// ClockIdGenerator genera las décimas de segundo transcurridas desde el
+// 2024-01-01 UTC. Es el generador por defecto del Id de los eventos: a
+// diferencia de una secuencia en memoria, un proceso reiniciado no vuelve a
+// empezar desde 1. Dentro del proceso nunca repite un valor; si se piden más
+// de diez por segundo, se adelanta al reloj, y un reinicio inmediato después
+// de una ráfaga así podría repetir alguno. Varios procesos que envían eventos
+// del mismo emisor deben usar en su lugar un generador persistente y distinto
+// por proceso (ver PrefixDIdGenerator y FileDIdGenerator).
+type ClockIdGenerator struct {
+	mu   sync.Mutex
+	last int64
+	now  func() time.Time
+}
+
+// NewClockIdGenerator crea un generador basado en el reloj del sistema
+func NewClockIdGenerator() *ClockIdGenerator {
+	return &ClockIdGenerator{now: time.Now}
+}
+
+// NextDId implementa DIdGenerator
+func (g *ClockIdGenerator) NextDId() (int64, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	id := int64(g.now().Sub(clockIdEpoch) / (100 * time.Millisecond))
+	if id <= g.last {
+		id = g.last + 1
+	}
+	if id < 1 {
+		return 0, fmt.Errorf("reloj del sistema anterior a %s", clockIdEpoch.Format("2006-01-02"))
+	}
+	g.last = id
+	return id, nil
+}
+
+func pow10(n int) int64 {
+	p := int64(1)
+	for i := 0; i < n; i++ {
+		p *= 10
+	}
+	return p
+}
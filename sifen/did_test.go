@@ -0,0 +1,119 @@
+package sifen
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rodascaar/sifen-go-py/sifen/models"
+)
+
+func TestConcurrentRequestsUseUniqueDIds(t *testing.T) {
+	dIdRe := regexp.MustCompile(`<dId>(\d+)</dId>`)
+
+	var mu sync.Mutex
+	seen := map[string]int{}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if m := dIdRe.FindSubmatch(body); m != nil {
+			mu.Lock()
+			seen[string(m[1])]++
+			mu.Unlock()
+		}
+		fmt.Fprintf(w, soapEnvelopeFmt, "<rRetEnviDe><dCodRes>0260</dCodRes></rRetEnviDe>")
+	})
+
+	client := newTestClient(t, handler, fastPolicy())
+
+	const workers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			de := models.NewDE(fmt.Sprintf("%044d", i))
+			if _, err := client.RecepcionDE(de); err != nil {
+				t.Errorf("RecepcionDE() error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if len(seen) != workers {
+		t.Errorf("got %d distinct dIds for %d requests: %v", len(seen), workers, seen)
+	}
+}
+
+func TestPrefixDIdGenerator(t *testing.T) {
+	gen, err := NewPrefixDIdGenerator(7, 12, NewSequenceDIdGenerator(1))
+	if err != nil {
+		t.Fatalf("NewPrefixDIdGenerator() error = %v", err)
+	}
+	id, err := gen.NextDId()
+	if err != nil {
+		t.Fatalf("NextDId() error = %v", err)
+	}
+	if id != 7000000000001 {
+		t.Errorf("NextDId() = %d; want 7000000000001", id)
+	}
+
+	if _, err := NewPrefixDIdGenerator(1000, 12, NewSequenceDIdGenerator(1)); err == nil {
+		t.Error("NewPrefixDIdGenerator() accepted a prefix exceeding 15 digits")
+	}
+}
+
+func TestFileDIdGeneratorSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "did.seq")
+
+	first, err := NewFileDIdGenerator(path)
+	if err != nil {
+		t.Fatalf("NewFileDIdGenerator() error = %v", err)
+	}
+	var last int64
+	for i := 0; i < 3; i++ {
+		if last, err = first.NextDId(); err != nil {
+			t.Fatalf("NextDId() error = %v", err)
+		}
+	}
+
+	restarted, err := NewFileDIdGenerator(path)
+	if err != nil {
+		t.Fatalf("NewFileDIdGenerator() error = %v", err)
+	}
+	next, err := restarted.NextDId()
+	if err != nil {
+		t.Fatalf("NextDId() error = %v", err)
+	}
+	if next <= last {
+		t.Errorf("NextDId() after restart = %d; want greater than %d", next, last)
+	}
+}
+
+func TestClockIdGeneratorDoesNotRepeat(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	gen := &ClockIdGenerator{now: func() time.Time { return now }}
+	first, err := gen.NextDId()
+	if err != nil {
+		t.Fatalf("NextDId() error = %v", err)
+	}
+	second, _ := gen.NextDId()
+	if second != first+1 {
+		t.Errorf("NextDId() in the same instant = %d, %d; want consecutive values", first, second)
+	}
+
+	// A restarted process does not start over, unlike NewSequenceDIdGenerator(1)
+	restarted := &ClockIdGenerator{now: func() time.Time { return now.Add(time.Second) }}
+	if next, _ := restarted.NextDId(); next <= second {
+		t.Errorf("NextDId() after restart = %d; want greater than %d", next, second)
+	}
+
+	until := &ClockIdGenerator{now: func() time.Time { return time.Date(2055, 1, 1, 0, 0, 0, 0, time.UTC) }}
+	if id, _ := until.NextDId(); id > MaxEventoId {
+		t.Errorf("NextDId() in 2055 = %d; want at most %d", id, MaxEventoId)
+	}
+}
@@ -1,6 +1,7 @@
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
 	"strings"
 )
@@ -77,6 +78,18 @@ func (e *SifenError) WithContext(key string, value interface{}) *SifenError {
 	return e
 }
 
+// WithCause retorna una copia del error con la causa indicada.
+// Usar con los errores predefinidos para no modificar la variable compartida.
+func (e *SifenError) WithCause(cause error) *SifenError {
+	clone := *e
+	clone.Cause = cause
+	clone.Context = nil
+	for k, v := range e.Context {
+		clone.WithContext(k, v)
+	}
+	return &clone
+}
+
 // ============================================================================
 // Constructores de Errores
 // ============================================================================
@@ -123,6 +136,17 @@ func NewSifenResponseError(code, message string) *SifenError {
 	}
 }
 
+// NewSoapFaultError crea un error desde un SOAP Fault retornado por SIFEN.
+// El detalle XML crudo queda en Context["fault_detail"].
+func NewSoapFaultError(code, reason, detail string) *SifenError {
+	err := NewSifenResponseError(code, reason)
+	err.WithContext("fault_code", code)
+	if detail != "" {
+		err.WithContext("fault_detail", detail)
+	}
+	return err
+}
+
 // NewBusinessError crea un error de negocio
 func NewBusinessError(code, message string) *SifenError {
 	return &SifenError{
@@ -178,6 +202,45 @@ var (
 
 	// ErrMotivoCancelacionRequerido indica que falta el motivo de cancelación
 	ErrMotivoCancelacionRequerido = NewValidationError("VAL_010", "Motivo de cancelación es requerido")
+
+	// ErrTipoContribuyenteRequerido indica que falta el tipo de contribuyente del emisor
+	ErrTipoContribuyenteRequerido = NewValidationError("VAL_011", "Tipo de contribuyente del emisor requerido")
+
+	// ErrDescripcionDesconocida indica códigos del DE sin descripción oficial conocida
+	ErrDescripcionDesconocida = NewValidationError("VAL_012", "Código sin descripción conocida")
+
+	// ErrDEInvalido indica que el DE no cumple las reglas del Manual Técnico
+	ErrDEInvalido = NewValidationError("VAL_013", "El DE no cumple las reglas del Manual Técnico v150")
+
+	// ErrEsquemaInvalido indica que el XML no cumple los esquemas XSD v150
+	ErrEsquemaInvalido = NewValidationError("VAL_014", "El XML no cumple el esquema XSD v150")
+
+	// ErrBuilderUsado indica que el builder ya construyó un DE
+	ErrBuilderUsado = NewValidationError("VAL_015", "El builder ya construyó un DE; cree uno nuevo")
+)
+
+// ============================================================================
+// Errores Predefinidos - Configuración
+// ============================================================================
+
+var (
+	// ErrConfigPathRequerido indica que falta la ruta de un servicio SIFEN
+	ErrConfigPathRequerido = NewValidationError("CFG_001", "Ruta de servicio SIFEN requerida")
+
+	// ErrConfigCSCInvalido indica un CSC vacío o con formato incorrecto
+	ErrConfigCSCInvalido = NewValidationError("CFG_002", "CSC debe tener 32 caracteres alfanuméricos")
+
+	// ErrConfigIdCSCInvalido indica un IdCSC que no tiene 4 dígitos
+	ErrConfigIdCSCInvalido = NewValidationError("CFG_003", "IdCSC debe tener 4 dígitos")
+
+	// ErrConfigProdCSCPrueba indica ambiente de producción con el CSC de prueba
+	ErrConfigProdCSCPrueba = NewValidationError("CFG_004", "Ambiente de producción configurado con el CSC de prueba")
+
+	// ErrConfigProdURLPrueba indica ambiente de producción con URLs de prueba
+	ErrConfigProdURLPrueba = NewValidationError("CFG_005", "Ambiente de producción configurado con URLs de prueba")
+
+	// ErrConfigInvalida indica otro valor de configuración inválido
+	ErrConfigInvalida = NewValidationError("CFG_006", "Configuración inválida")
 )
 
 // ============================================================================
@@ -219,6 +282,27 @@ var (
 		Code:    "CRYPTO_005",
 		Message: "Clave privada no es RSA",
 	}
+
+	// ErrCertificadoRUCNoCoincide indica que el certificado pertenece a otro RUC que el emisor
+	ErrCertificadoRUCNoCoincide = &SifenError{
+		Type:    ErrorTypeCryptography,
+		Code:    "CRYPTO_006",
+		Message: "El RUC del certificado no coincide con el RUC del emisor",
+	}
+
+	// ErrFirmaNoEncontrada indica un documento sin elemento Signature
+	ErrFirmaNoEncontrada = &SifenError{
+		Type:    ErrorTypeCryptography,
+		Code:    "CRYPTO_007",
+		Message: "Documento sin firma digital",
+	}
+
+	// ErrFirmaNoValida indica que el digest o el valor de la firma no verifican
+	ErrFirmaNoValida = &SifenError{
+		Type:    ErrorTypeCryptography,
+		Code:    "CRYPTO_008",
+		Message: "Firma digital no válida",
+	}
 )
 
 // ============================================================================
@@ -257,6 +341,22 @@ var (
 		Message:     "Error en handshake TLS con SIFEN",
 		Recoverable: false,
 	}
+
+	// ErrServidorSifen indica que SIFEN respondió con un estado HTTP 5xx
+	ErrServidorSifen = &SifenError{
+		Type:        ErrorTypeNetwork,
+		Code:        "NET_005",
+		Message:     "SIFEN respondió con error de servidor",
+		Recoverable: true,
+	}
+
+	// ErrEstadoHTTP indica que SIFEN respondió con un estado HTTP inesperado (no 5xx)
+	ErrEstadoHTTP = &SifenError{
+		Type:        ErrorTypeNetwork,
+		Code:        "NET_006",
+		Message:     "SIFEN respondió con estado HTTP inesperado",
+		Recoverable: false,
+	}
 )
 
 // ============================================================================
@@ -360,6 +460,12 @@ func isRecoverableSifenCode(code string) bool {
 	return recoverableCodes[code]
 }
 
+// IsRecoverableCode indica si un código de respuesta SIFEN corresponde a una
+// condición transitoria que puede reintentarse
+func IsRecoverableCode(code string) bool {
+	return isRecoverableSifenCode(code)
+}
+
 // ============================================================================
 // Helper Functions
 // ============================================================================
@@ -376,9 +482,11 @@ func AsSifenError(err error) (*SifenError, bool) {
 	return se, ok
 }
 
-// IsRecoverable verifica si un error es recuperable
+// IsRecoverable verifica si un error es recuperable.
+// Revisa toda la cadena de errores envueltos con %w.
 func IsRecoverable(err error) bool {
-	if se, ok := err.(*SifenError); ok {
+	var se *SifenError
+	if stderrors.As(err, &se) {
 		return se.Recoverable
 	}
 	return false
@@ -3,47 +3,59 @@ package events
 import (
 	"encoding/xml"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/rodascaar/sifen-go-py/sifen/types"
 )
 
 // ============================================================================
-// Base Event Structure
+// Estructura del Evento según siRecepEvento_v150.xsd
 // ============================================================================
 
-// REvento: Envelope para eventos SIFEN
+// REvento es el evento (rEve). EnviarEvento lo firma dentro de rGesEve y lo
+// envía en rEnviEventoDe/dEvReg/gGroupGesEve.
 type REvento struct {
-	XMLName  xml.Name `xml:"rEnviEventoDe"`
-	XmlnsXsi string   `xml:"xmlns:xsi,attr"`
-	DId      int64    `xml:"dId"`
-	DDTE     string   `xml:"dFecFirma"`
-	GEvento  GEvento  `xml:"gEvento"`
+	XMLName     xml.Name    `xml:"http://ekuatia.set.gov.py/sifen/xsd rEve"`
+	Id          string      `xml:"Id,attr"`
+	DFecFirma   string      `xml:"dFecFirma"`
+	DVerFor     int16       `xml:"dVerFor"`
+	GGroupTiEvt GGroupTiEvt `xml:"gGroupTiEvt"`
 }
 
-// GEvento: Grupo principal del evento
-type GEvento struct {
-	Id           string        `xml:"Id,attr"`
-	DFecFirma    string        `xml:"dFecFirma"`
-	DVerFor      int16         `xml:"dVerFor"`
-	GGroupGesEvc *GGroupGesEve `xml:"gGroupGesEve,omitempty"` // Eventos del emisor
-	GGroupTiEvt  *GGroupTiEvt  `xml:"gGroupTiEvt,omitempty"`  // Eventos del receptor
+// RGesEve agrupa un evento con su firma, que se inserta después de rEve
+type RGesEve struct {
+	XMLName xml.Name `xml:"http://ekuatia.set.gov.py/sifen/xsd rGesEve"`
+	REve    *REvento `xml:"rEve"`
 }
 
-// GGroupGesEve: Grupo para eventos del emisor (Cancelación, Inutilización, Nominación, etc.)
-type GGroupGesEve struct {
-	RGesEveDE  *RGesEveDE           `xml:"rGeVeCanworking,omitempty"` // Cancelación
-	RGesEveInu *EventoInutilizacion `xml:"rGeVeInu,omitempty"`        // Inutilización
-	RGesEveNom *EventoNominacion    `xml:"rGeVeNom,omitempty"`        // Nominación
-	RGesEveTra *EventoActTransporte `xml:"rGeVeTra,omitempty"`        // Actualización transporte
-}
-
-// GGroupTiEvt: Grupo para eventos del receptor
+// GGroupTiEvt contiene exactamente uno de los tipos de evento
 type GGroupTiEvt struct {
-	RGeTrReceptorConf *EventoConformidad     `xml:"rGeTrReConf,omitempty"` // Conformidad
-	RGeTrReceptorDisc *EventoDisconformidad  `xml:"rGeTrReDisc,omitempty"` // Disconformidad
-	RGeTrReceptorDesc *EventoDesconocimiento `xml:"rGeTrReDesc,omitempty"` // Desconocimiento
-	RGeTrReceptorNot  *EventoNotificacion    `xml:"rGeTrReNot,omitempty"`  // Notificación
+	RGeVeCan     *EventoCancelacionDE   `xml:"rGeVeCan,omitempty"`     // Cancelación
+	RGeVeInu     *EventoInutilizacion   `xml:"rGeVeInu,omitempty"`     // Inutilización
+	RGeVeNotRec  *EventoNotificacion    `xml:"rGeVeNotRec,omitempty"`  // Notificación de recepción
+	RGeVeConf    *EventoConformidad     `xml:"rGeVeConf,omitempty"`    // Conformidad
+	RGeVeDisconf *EventoDisconformidad  `xml:"rGeVeDisconf,omitempty"` // Disconformidad
+	RGeVeDescon  *EventoDesconocimiento `xml:"rGeVeDescon,omitempty"`  // Desconocimiento
+}
+
+// CDC retorna el CDC del documento al que se refiere el evento,
+// o "" si el evento no referencia un CDC (ej. inutilización)
+func (e *REvento) CDC() string {
+	g := e.GGroupTiEvt
+	switch {
+	case g.RGeVeCan != nil:
+		return g.RGeVeCan.Id
+	case g.RGeVeNotRec != nil:
+		return g.RGeVeNotRec.Id
+	case g.RGeVeConf != nil:
+		return g.RGeVeConf.Id
+	case g.RGeVeDisconf != nil:
+		return g.RGeVeDisconf.Id
+	case g.RGeVeDescon != nil:
+		return g.RGeVeDescon.Id
+	}
+	return ""
 }
 
 // ============================================================================
@@ -51,23 +63,41 @@ type GGroupTiEvt struct {
 // ============================================================================
 
 type EventBuilder struct {
-	dId       int64
-	version   int16
-	rucEmisor string
-	dvEmisor  string
+	id      int64
+	version int16
 }
 
-func NewEventBuilder(requestId int64, ruc, dv string) *EventBuilder {
+// NewEventBuilder crea un constructor de eventos cuyo Id es id (1 a 10
+// dígitos). SIFEN rechaza un Id repetido del mismo emisor, así que id debe
+// salir de una fuente que no se repita entre reinicios; SifenClient usa
+// config.EventoIdGenerator. El evento no lleva el RUC de quien lo genera:
+// SIFEN lo identifica por el certificado de la firma.
+func NewEventBuilder(id int64) *EventBuilder {
 	return &EventBuilder{
-		dId:       requestId,
-		version:   150,
-		rucEmisor: ruc,
-		dvEmisor:  dv,
+		id:      id,
+		version: 150,
 	}
 }
 
 func (b *EventBuilder) generateEventId() string {
-	return fmt.Sprintf("%s%s%d", b.rucEmisor, b.dvEmisor, time.Now().UnixNano())
+	return strconv.FormatInt(b.id, 10)
+}
+
+func (b *EventBuilder) evento(grupo GGroupTiEvt) *REvento {
+	return &REvento{
+		Id:          b.generateEventId(),
+		DFecFirma:   time.Now().Format("2006-01-02T15:04:05"),
+		DVerFor:     b.version,
+		GGroupTiEvt: grupo,
+	}
+}
+
+// validarMotivo verifica la longitud de mOtEve (5 a 500 caracteres)
+func validarMotivo(motivo string) error {
+	if n := len([]rune(motivo)); n < 5 || n > 500 {
+		return fmt.Errorf("motivo debe tener entre 5 y 500 caracteres")
+	}
+	return nil
 }
 
 // ============================================================================
@@ -80,61 +110,26 @@ type EventoCancelacion struct {
 	Motivo string
 }
 
-// RGesEveDE: Estructura de cancelación de DE
-type RGesEveDE struct {
-	DId      int64    `xml:"dId"`
-	MEvento  string   `xml:"mEvento"`
-	GEvEmiDE GEvEmiDE `xml:"gEvEmiDE"`
-}
-
-// GEvEmiDE: Grupo evento emisor - Cancelación
-type GEvEmiDE struct {
-	DRucEmi string     `xml:"dRucEmi"`
-	DDVEmi  string     `xml:"dDVEmi"`
-	ITipEvt int16      `xml:"iTipEvt"` // 1 = Cancelación
-	MEvento string     `xml:"mMotEve"` // Motivo del evento
-	GCamEve GCamEveCan `xml:"gGroupGesEve"`
-}
-
-type GCamEveCan struct {
-	DCDC string `xml:"dCDC"` // CDC a cancelar
+// EventoCancelacionDE: Estructura de cancelación de DE (rGeVeCan)
+type EventoCancelacionDE struct {
+	Id     string `xml:"Id"`     // CDC a cancelar
+	MOtEve string `xml:"mOtEve"` // Motivo del evento
 }
 
 func (b *EventBuilder) BuildCancelacion(data EventoCancelacion) (*REvento, error) {
 	if len(data.CDC) != 44 {
 		return nil, fmt.Errorf("CDC debe tener 44 caracteres")
 	}
-	if data.Motivo == "" {
-		return nil, fmt.Errorf("motivo es requerido")
+	if err := validarMotivo(data.Motivo); err != nil {
+		return nil, err
 	}
 
-	evento := &REvento{
-		XmlnsXsi: "http://www.w3.org/2001/XMLSchema-instance",
-		DId:      b.dId,
-		DDTE:     time.Now().Format("2006-01-02T15:04:05"),
-		GEvento: GEvento{
-			Id:        b.generateEventId(),
-			DFecFirma: time.Now().Format("2006-01-02T15:04:05"),
-			DVerFor:   b.version,
-			GGroupGesEvc: &GGroupGesEve{
-				RGesEveDE: &RGesEveDE{
-					DId:     b.dId,
-					MEvento: data.Motivo,
-					GEvEmiDE: GEvEmiDE{
-						DRucEmi: b.rucEmisor,
-						DDVEmi:  b.dvEmisor,
-						ITipEvt: 1,
-						MEvento: data.Motivo,
-						GCamEve: GCamEveCan{
-							DCDC: data.CDC,
-						},
-					},
-				},
-			},
+	return b.evento(GGroupTiEvt{
+		RGeVeCan: &EventoCancelacionDE{
+			Id:     data.CDC,
+			MOtEve: data.Motivo,
 		},
-	}
-
-	return evento, nil
+	}), nil
 }
 
 // ============================================================================
@@ -143,6 +138,7 @@ func (b *EventBuilder) BuildCancelacion(data EventoCancelacion) (*REvento, error
 
 // EventoInutilizacionData: Datos para evento de inutilización
 type EventoInutilizacionData struct {
+	Timbrado        int32
 	TipoDocumento   types.TTiDE
 	Establecimiento string
 	Punto           string
@@ -151,66 +147,48 @@ type EventoInutilizacionData struct {
 	Motivo          string
 }
 
-// EventoInutilizacion: Estructura de inutilización
+// EventoInutilizacion: Estructura de inutilización (rGeVeInu)
 type EventoInutilizacion struct {
-	DRucEmi    string `xml:"dRucEmi"`
-	DDVEmi     string `xml:"dDVEmi"`
-	ITipEvt    int16  `xml:"iTipEvt"`   // 2 = Inutilización
-	MEventoInu string `xml:"mMotEvInu"` // Motivo
-	DCoEvInu   GEvInu `xml:"gGroupGesEve"`
-}
-
-type GEvInu struct {
-	ITiDE    types.TTiDE `xml:"iTiDE"`        // Tipo de documento
-	DDesTiDE string      `xml:"dDesTiDE"`     // Descripción tipo doc
-	DEst     string      `xml:"dEst"`         // Establecimiento
-	DPunExp  string      `xml:"dPunExp"`      // Punto de expedición
-	DNumDocI int32       `xml:"dNumDocDesde"` // Número desde
-	DNumDocF int32       `xml:"dNumDocHasta"` // Número hasta
+	DNumTim int32       `xml:"dNumTim"` // Timbrado
+	DEst    string      `xml:"dEst"`    // Establecimiento
+	DPunExp string      `xml:"dPunExp"` // Punto de expedición
+	DNumIn  string      `xml:"dNumIn"`  // Número desde
+	DNumFin string      `xml:"dNumFin"` // Número hasta
+	ITiDE   types.TTiDE `xml:"iTiDE"`   // Tipo de documento
+	MOtEve  string      `xml:"mOtEve"`  // Motivo
 }
 
 func (b *EventBuilder) BuildInutilizacion(data EventoInutilizacionData) (*REvento, error) {
+	if data.Timbrado < 10000000 || data.Timbrado > 99999999 {
+		return nil, fmt.Errorf("timbrado debe tener 8 dígitos")
+	}
 	if data.Establecimiento == "" || len(data.Establecimiento) != 3 {
 		return nil, fmt.Errorf("establecimiento debe tener 3 caracteres")
 	}
 	if data.Punto == "" || len(data.Punto) != 3 {
 		return nil, fmt.Errorf("punto debe tener 3 caracteres")
 	}
+	if data.Desde < 1 || data.Hasta > 9999999 {
+		return nil, fmt.Errorf("rango inválido: los números deben estar entre 1 y 9999999")
+	}
 	if data.Desde > data.Hasta {
 		return nil, fmt.Errorf("rango inválido: desde debe ser menor o igual a hasta")
 	}
-	if data.Motivo == "" {
-		return nil, fmt.Errorf("motivo es requerido")
+	if err := validarMotivo(data.Motivo); err != nil {
+		return nil, err
 	}
 
-	evento := &REvento{
-		XmlnsXsi: "http://www.w3.org/2001/XMLSchema-instance",
-		DId:      b.dId,
-		DDTE:     time.Now().Format("2006-01-02T15:04:05"),
-		GEvento: GEvento{
-			Id:        b.generateEventId(),
-			DFecFirma: time.Now().Format("2006-01-02T15:04:05"),
-			DVerFor:   b.version,
-			GGroupGesEvc: &GGroupGesEve{
-				RGesEveInu: &EventoInutilizacion{
-					DRucEmi:    b.rucEmisor,
-					DDVEmi:     b.dvEmisor,
-					ITipEvt:    2,
-					MEventoInu: data.Motivo,
-					DCoEvInu: GEvInu{
-						ITiDE:    data.TipoDocumento,
-						DDesTiDE: data.TipoDocumento.String(),
-						DEst:     data.Establecimiento,
-						DPunExp:  data.Punto,
-						DNumDocI: data.Desde,
-						DNumDocF: data.Hasta,
-					},
-				},
-			},
+	return b.evento(GGroupTiEvt{
+		RGeVeInu: &EventoInutilizacion{
+			DNumTim: data.Timbrado,
+			DEst:    data.Establecimiento,
+			DPunExp: data.Punto,
+			DNumIn:  fmt.Sprintf("%07d", data.Desde),
+			DNumFin: fmt.Sprintf("%07d", data.Hasta),
+			ITiDE:   data.TipoDocumento,
+			MOtEve:  data.Motivo,
 		},
-	}
-
-	return evento, nil
+	}), nil
 }
 
 // ============================================================================
@@ -220,19 +198,14 @@ func (b *EventBuilder) BuildInutilizacion(data EventoInutilizacionData) (*REvent
 type EventoConformidadData struct {
 	CDC             string
 	TipoConformidad types.TiTipoConformidad
-	FechaRecepcion  time.Time
+	FechaRecepcion  time.Time // Opcional
 }
 
-// EventoConformidad: Estructura de conformidad del receptor
+// EventoConformidad: Estructura de conformidad del receptor (rGeVeConf)
 type EventoConformidad struct {
-	DRucRec     string                  `xml:"dRucRec"`
-	DDVRec      string                  `xml:"dDVRec"`
-	ITipEvt     int16                   `xml:"iTipEvt"` // 11 = Conformidad
-	DCDC        string                  `xml:"dCDC"`
-	ITiConf     types.TiTipoConformidad `xml:"iTiConf"`
-	DDesTiConf  string                  `xml:"dDesTiConf"`
-	DFecRecep   string                  `xml:"dFecRecep"` // Fecha recepción
-	DFecEmiConf string                  `xml:"dFecEmiConf"`
+	Id        string                  `xml:"Id"` // CDC
+	ITipConf  types.TiTipoConformidad `xml:"iTipConf"`
+	DFecRecep string                  `xml:"dFecRecep,omitempty"` // Fecha recepción
 }
 
 func (b *EventBuilder) BuildConformidad(data EventoConformidadData) (*REvento, error) {
@@ -240,35 +213,15 @@ func (b *EventBuilder) BuildConformidad(data EventoConformidadData) (*REvento, e
 		return nil, fmt.Errorf("CDC debe tener 44 caracteres")
 	}
 
-	fechaRecep := data.FechaRecepcion
-	if fechaRecep.IsZero() {
-		fechaRecep = time.Now()
+	conf := &EventoConformidad{
+		Id:       data.CDC,
+		ITipConf: data.TipoConformidad,
 	}
-
-	evento := &REvento{
-		XmlnsXsi: "http://www.w3.org/2001/XMLSchema-instance",
-		DId:      b.dId,
-		DDTE:     time.Now().Format("2006-01-02T15:04:05"),
-		GEvento: GEvento{
-			Id:        b.generateEventId(),
-			DFecFirma: time.Now().Format("2006-01-02T15:04:05"),
-			DVerFor:   b.version,
-			GGroupTiEvt: &GGroupTiEvt{
-				RGeTrReceptorConf: &EventoConformidad{
-					DRucRec:     b.rucEmisor,
-					DDVRec:      b.dvEmisor,
-					ITipEvt:     11,
-					DCDC:        data.CDC,
-					ITiConf:     data.TipoConformidad,
-					DDesTiConf:  data.TipoConformidad.String(),
-					DFecRecep:   fechaRecep.Format("2006-01-02T15:04:05"),
-					DFecEmiConf: time.Now().Format("2006-01-02T15:04:05"),
-				},
-			},
-		},
+	if !data.FechaRecepcion.IsZero() {
+		conf.DFecRecep = data.FechaRecepcion.Format("2006-01-02T15:04:05")
 	}
 
-	return evento, nil
+	return b.evento(GGroupTiEvt{RGeVeConf: conf}), nil
 }
 
 // ============================================================================
@@ -280,46 +233,53 @@ type EventoDisconformidadData struct {
 	Motivo string
 }
 
-// EventoDisconformidad: Estructura de disconformidad del receptor
+// EventoDisconformidad: Estructura de disconformidad del receptor (rGeVeDisconf)
 type EventoDisconformidad struct {
-	DRucRec     string `xml:"dRucRec"`
-	DDVRec      string `xml:"dDVRec"`
-	ITipEvt     int16  `xml:"iTipEvt"` // 12 = Disconformidad
-	DCDC        string `xml:"dCDC"`
-	MMotDisc    string `xml:"mMotDisc"`
-	DFecEmiDisc string `xml:"dFecEmiDisc"`
+	Id     string `xml:"Id"` // CDC
+	MOtEve string `xml:"mOtEve"`
 }
 
 func (b *EventBuilder) BuildDisconformidad(data EventoDisconformidadData) (*REvento, error) {
 	if len(data.CDC) != 44 {
 		return nil, fmt.Errorf("CDC debe tener 44 caracteres")
 	}
-	if data.Motivo == "" {
-		return nil, fmt.Errorf("motivo es requerido")
+	if err := validarMotivo(data.Motivo); err != nil {
+		return nil, err
 	}
 
-	evento := &REvento{
-		XmlnsXsi: "http://www.w3.org/2001/XMLSchema-instance",
-		DId:      b.dId,
-		DDTE:     time.Now().Format("2006-01-02T15:04:05"),
-		GEvento: GEvento{
-			Id:        b.generateEventId(),
-			DFecFirma: time.Now().Format("2006-01-02T15:04:05"),
-			DVerFor:   b.version,
-			GGroupTiEvt: &GGroupTiEvt{
-				RGeTrReceptorDisc: &EventoDisconformidad{
-					DRucRec:     b.rucEmisor,
-					DDVRec:      b.dvEmisor,
-					ITipEvt:     12,
-					DCDC:        data.CDC,
-					MMotDisc:    data.Motivo,
-					DFecEmiDisc: time.Now().Format("2006-01-02T15:04:05"),
-				},
-			},
+	return b.evento(GGroupTiEvt{
+		RGeVeDisconf: &EventoDisconformidad{
+			Id:     data.CDC,
+			MOtEve: data.Motivo,
 		},
-	}
+	}), nil
+}
 
-	return evento, nil
+// ============================================================================
+// Datos del receptor en Desconocimiento y Notificación
+// ============================================================================
+
+// ReceptorEvento identifica al receptor del DE en los eventos de
+// desconocimiento y de notificación de recepción: RUC y DV si es
+// contribuyente, tipo y número de documento si no lo es
+type ReceptorEvento struct {
+	DRucRec   string           `xml:"dRucRec,omitempty"`
+	DDVRec    string           `xml:"dDVRec,omitempty"`
+	DTipIDRec types.TTipDocRec `xml:"dTipIDRec,omitempty"`
+	DNumID    string           `xml:"dNumID,omitempty"`
+}
+
+func receptorEvento(tipo types.TiNatRec, ruc, dv string, tipoDoc types.TTipDocRec, numDoc string) (ReceptorEvento, error) {
+	if tipo == types.TiNatRec_Contribuyente {
+		if ruc == "" || dv == "" {
+			return ReceptorEvento{}, fmt.Errorf("RUC y DV del receptor son requeridos para contribuyentes")
+		}
+		return ReceptorEvento{DRucRec: ruc, DDVRec: dv}, nil
+	}
+	if tipoDoc == 0 || numDoc == "" {
+		return ReceptorEvento{}, fmt.Errorf("tipo y número de documento del receptor son requeridos para no contribuyentes")
+	}
+	return ReceptorEvento{DTipIDRec: tipoDoc, DNumID: numDoc}, nil
 }
 
 // ============================================================================
@@ -332,39 +292,38 @@ type EventoDesconocimientoData struct {
 	FechaRecepcion time.Time
 	TipoReceptor   types.TiNatRec
 	Nombre         string
-	RUC            string
-	TipoDocumento  types.TTipDocRec
+	RUC            string // Si es contribuyente
+	DV             string
+	TipoDocumento  types.TTipDocRec // Si no es contribuyente
 	NumeroDoc      string
 	Motivo         string
 }
 
-// EventoDesconocimiento: Estructura de desconocimiento del DE
+// EventoDesconocimiento: Estructura de desconocimiento del DE (rGeVeDescon)
 type EventoDesconocimiento struct {
-	DRucRec     string           `xml:"dRucRec,omitempty"`
-	DDVRec      string           `xml:"dDVRec,omitempty"`
-	ITipEvt     int16            `xml:"iTipEvt"` // 13 = Desconocimiento
-	DCDC        string           `xml:"dCDC"`
-	DFecEmi     string           `xml:"dFecEmi"`
-	DFecRecep   string           `xml:"dFecRecep"`
-	ITiRec      types.TiNatRec   `xml:"iTiRec"`
-	DNomRec     string           `xml:"dNomRec"`
-	DRucRecDes  string           `xml:"dRucRecDes,omitempty"`
-	ITipDocRec  types.TTipDocRec `xml:"iTipIDRec,omitempty"`
-	DNumDocRec  string           `xml:"dNumIDRec,omitempty"`
-	MMotDesc    string           `xml:"mMotDesc"`
-	DFecEmiDesc string           `xml:"dFecEmiDesc"`
+	Id        string         `xml:"Id"` // CDC
+	DFecEmi   string         `xml:"dFecEmi"`
+	DFecRecep string         `xml:"dFecRecep"`
+	ITipRec   types.TiNatRec `xml:"iTipRec"`
+	DNomRec   string         `xml:"dNomRec"`
+	ReceptorEvento
+	MOtEve string `xml:"mOtEve"`
 }
 
 func (b *EventBuilder) BuildDesconocimiento(data EventoDesconocimientoData) (*REvento, error) {
 	if len(data.CDC) != 44 {
 		return nil, fmt.Errorf("CDC debe tener 44 caracteres")
 	}
-	if data.Motivo == "" {
-		return nil, fmt.Errorf("motivo es requerido")
+	if err := validarMotivo(data.Motivo); err != nil {
+		return nil, err
 	}
 	if data.Nombre == "" {
 		return nil, fmt.Errorf("nombre es requerido")
 	}
+	receptor, err := receptorEvento(data.TipoReceptor, data.RUC, data.DV, data.TipoDocumento, data.NumeroDoc)
+	if err != nil {
+		return nil, err
+	}
 
 	fechaEmi := data.FechaEmision
 	if fechaEmi.IsZero() {
@@ -375,35 +334,17 @@ func (b *EventBuilder) BuildDesconocimiento(data EventoDesconocimientoData) (*RE
 		fechaRecep = time.Now()
 	}
 
-	evento := &REvento{
-		XmlnsXsi: "http://www.w3.org/2001/XMLSchema-instance",
-		DId:      b.dId,
-		DDTE:     time.Now().Format("2006-01-02T15:04:05"),
-		GEvento: GEvento{
-			Id:        b.generateEventId(),
-			DFecFirma: time.Now().Format("2006-01-02T15:04:05"),
-			DVerFor:   b.version,
-			GGroupTiEvt: &GGroupTiEvt{
-				RGeTrReceptorDesc: &EventoDesconocimiento{
-					DRucRec:     b.rucEmisor,
-					DDVRec:      b.dvEmisor,
-					ITipEvt:     13,
-					DCDC:        data.CDC,
-					DFecEmi:     fechaEmi.Format("2006-01-02T15:04:05"),
-					DFecRecep:   fechaRecep.Format("2006-01-02T15:04:05"),
-					ITiRec:      data.TipoReceptor,
-					DNomRec:     data.Nombre,
-					DRucRecDes:  data.RUC,
-					ITipDocRec:  data.TipoDocumento,
-					DNumDocRec:  data.NumeroDoc,
-					MMotDesc:    data.Motivo,
-					DFecEmiDesc: time.Now().Format("2006-01-02T15:04:05"),
-				},
-			},
+	return b.evento(GGroupTiEvt{
+		RGeVeDescon: &EventoDesconocimiento{
+			Id:             data.CDC,
+			DFecEmi:        fechaEmi.Format("2006-01-02T15:04:05"),
+			DFecRecep:      fechaRecep.Format("2006-01-02T15:04:05"),
+			ITipRec:        data.TipoReceptor,
+			DNomRec:        data.Nombre,
+			ReceptorEvento: receptor,
+			MOtEve:         data.Motivo,
 		},
-	}
-
-	return evento, nil
+	}), nil
 }
 
 // ============================================================================
@@ -416,27 +357,22 @@ type EventoNotificacionData struct {
 	FechaRecepcion time.Time
 	TipoReceptor   types.TiNatRec
 	Nombre         string
-	RUC            string
-	TipoDocumento  types.TTipDocRec
+	RUC            string // Si es contribuyente
+	DV             string
+	TipoDocumento  types.TTipDocRec // Si no es contribuyente
 	NumeroDoc      string
-	TotalPYG       float64
+	TotalPYG       types.Decimal
 }
 
-// EventoNotificacion: Estructura de notificación de recepción
+// EventoNotificacion: Estructura de notificación de recepción (rGeVeNotRec)
 type EventoNotificacion struct {
-	DRucRec    string           `xml:"dRucRec,omitempty"`
-	DDVRec     string           `xml:"dDVRec,omitempty"`
-	ITipEvt    int16            `xml:"iTipEvt"` // 14 = Notificación
-	DCDC       string           `xml:"dCDC"`
-	DFecEmi    string           `xml:"dFecEmi"`
-	DFecRecep  string           `xml:"dFecRecep"`
-	ITiRec     types.TiNatRec   `xml:"iTiRec"`
-	DNomRec    string           `xml:"dNomRec"`
-	DRucRecNot string           `xml:"dRucRecNot,omitempty"`
-	ITipDocRec types.TTipDocRec `xml:"iTipIDRec,omitempty"`
-	DNumDocRec string           `xml:"dNumIDRec,omitempty"`
-	DTotPyg    float64          `xml:"dTotPyg"`
-	DFecEmiNot string           `xml:"dFecEmiNot"`
+	Id        string         `xml:"Id"` // CDC
+	DFecEmi   string         `xml:"dFecEmi"`
+	DFecRecep string         `xml:"dFecRecep"`
+	ITipRec   types.TiNatRec `xml:"iTipRec"`
+	DNomRec   string         `xml:"dNomRec"`
+	ReceptorEvento
+	DTotalGs types.Decimal `xml:"dTotalGs"`
 }
 
 func (b *EventBuilder) BuildNotificacion(data EventoNotificacionData) (*REvento, error) {
@@ -446,6 +382,10 @@ func (b *EventBuilder) BuildNotificacion(data EventoNotificacionData) (*REvento,
 	if data.Nombre == "" {
 		return nil, fmt.Errorf("nombre es requerido")
 	}
+	receptor, err := receptorEvento(data.TipoReceptor, data.RUC, data.DV, data.TipoDocumento, data.NumeroDoc)
+	if err != nil {
+		return nil, err
+	}
 
 	fechaEmi := data.FechaEmision
 	if fechaEmi.IsZero() {
@@ -456,313 +396,15 @@ func (b *EventBuilder) BuildNotificacion(data EventoNotificacionData) (*REvento,
 		fechaRecep = time.Now()
 	}
 
-	evento := &REvento{
-		XmlnsXsi: "http://www.w3.org/2001/XMLSchema-instance",
-		DId:      b.dId,
-		DDTE:     time.Now().Format("2006-01-02T15:04:05"),
-		GEvento: GEvento{
-			Id:        b.generateEventId(),
-			DFecFirma: time.Now().Format("2006-01-02T15:04:05"),
-			DVerFor:   b.version,
-			GGroupTiEvt: &GGroupTiEvt{
-				RGeTrReceptorNot: &EventoNotificacion{
-					DRucRec:    b.rucEmisor,
-					DDVRec:     b.dvEmisor,
-					ITipEvt:    14,
-					DCDC:       data.CDC,
-					DFecEmi:    fechaEmi.Format("2006-01-02T15:04:05"),
-					DFecRecep:  fechaRecep.Format("2006-01-02T15:04:05"),
-					ITiRec:     data.TipoReceptor,
-					DNomRec:    data.Nombre,
-					DRucRecNot: data.RUC,
-					ITipDocRec: data.TipoDocumento,
-					DNumDocRec: data.NumeroDoc,
-					DTotPyg:    data.TotalPYG,
-					DFecEmiNot: time.Now().Format("2006-01-02T15:04:05"),
-				},
-			},
-		},
-	}
-
-	return evento, nil
-}
-
-// ============================================================================
-// Evento Nominación
-// ============================================================================
-
-type EventoNominacionData struct {
-	CDC            string
-	RUCNominado    string
-	DVNominado     string
-	NombreNominado string
-}
-
-type EventoNominacion struct {
-	DRucEmi    string `xml:"dRucEmi"`
-	DDVEmi     string `xml:"dDVEmi"`
-	ITipEvt    int16  `xml:"iTipEvt"` // 20 = Nominación
-	DCDC       string `xml:"dCDC"`
-	DRucNom    string `xml:"dRucNom"`    // RUC del nominado
-	DDVNom     string `xml:"dDVNom"`     // DV del nominado
-	DNomNom    string `xml:"dNomNom"`    // Nombre del nominado
-	DFecEmiNom string `xml:"dFecEmiNom"` // Fecha emisión nominación
-}
-
-func (b *EventBuilder) BuildNominacion(data EventoNominacionData) (*REvento, error) {
-	if len(data.CDC) != 44 {
-		return nil, fmt.Errorf("CDC debe tener 44 caracteres")
-	}
-	if data.RUCNominado == "" {
-		return nil, fmt.Errorf("RUC del nominado es requerido")
-	}
-	if data.NombreNominado == "" {
-		return nil, fmt.Errorf("nombre del nominado es requerido")
-	}
-
-	evento := &REvento{
-		XmlnsXsi: "http://www.w3.org/2001/XMLSchema-instance",
-		DId:      b.dId,
-		DDTE:     time.Now().Format("2006-01-02T15:04:05"),
-		GEvento: GEvento{
-			Id:        b.generateEventId(),
-			DFecFirma: time.Now().Format("2006-01-02T15:04:05"),
-			DVerFor:   b.version,
-			GGroupGesEvc: &GGroupGesEve{
-				RGesEveNom: &EventoNominacion{
-					DRucEmi:    b.rucEmisor,
-					DDVEmi:     b.dvEmisor,
-					ITipEvt:    20,
-					DCDC:       data.CDC,
-					DRucNom:    data.RUCNominado,
-					DDVNom:     data.DVNominado,
-					DNomNom:    data.NombreNominado,
-					DFecEmiNom: time.Now().Format("2006-01-02T15:04:05"),
-				},
-			},
+	return b.evento(GGroupTiEvt{
+		RGeVeNotRec: &EventoNotificacion{
+			Id:             data.CDC,
+			DFecEmi:        fechaEmi.Format("2006-01-02T15:04:05"),
+			DFecRecep:      fechaRecep.Format("2006-01-02T15:04:05"),
+			ITipRec:        data.TipoReceptor,
+			DNomRec:        data.Nombre,
+			ReceptorEvento: receptor,
+			DTotalGs:       data.TotalPYG,
 		},
-	}
-
-	return evento, nil
-}
-
-// ============================================================================
-// Evento Actualización de Datos del Transporte (rGeVeTr)
-// ============================================================================
-
-// MotivoActualizacionTransporte: Códigos de motivo para actualización de transporte
-type MotivoActualizacionTransporte int16
-
-const (
-	// MotivoActTransp_CambioLocalEntrega: Cambio del local de entrega
-	// Requiere: nueva dirección, departamento, distrito y ciudad
-	MotivoActTransp_CambioLocalEntrega MotivoActualizacionTransporte = 1
-
-	// MotivoActTransp_CambioChofer: Cambio del chofer
-	// Requiere: nombre y número de documento del nuevo conductor
-	MotivoActTransp_CambioChofer MotivoActualizacionTransporte = 2
-
-	// MotivoActTransp_CambioTransportista: Cambio del transportista
-	// Requiere: RUC (si contribuyente) o documento de identidad, y nombre
-	MotivoActTransp_CambioTransportista MotivoActualizacionTransporte = 3
-
-	// MotivoActTransp_CambioVehiculo: Cambio de vehículo
-	// Requiere: tipo de vehículo, marca y número de placa o chasis
-	MotivoActTransp_CambioVehiculo MotivoActualizacionTransporte = 4
-)
-
-func (m MotivoActualizacionTransporte) String() string {
-	switch m {
-	case MotivoActTransp_CambioLocalEntrega:
-		return "Cambio del local de entrega"
-	case MotivoActTransp_CambioChofer:
-		return "Cambio del chofer"
-	case MotivoActTransp_CambioTransportista:
-		return "Cambio del transportista"
-	case MotivoActTransp_CambioVehiculo:
-		return "Cambio de vehículo"
-	default:
-		return fmt.Sprintf("Motivo %d", m)
-	}
-}
-
-// EventoActTransporteData: Datos para evento de actualización de transporte
-type EventoActTransporteData struct {
-	CDC    string                        // CDC del documento afectado (ej: Nota de Remisión)
-	Motivo MotivoActualizacionTransporte // Motivo de la actualización (1-4)
-
-	// Datos para Cambio de Local de Entrega (Motivo 1)
-	NuevaDireccion    string
-	NuevoDepartamento types.TDepartamento
-	NuevoDistrito     int16
-	NuevaCiudad       int32
-	DescDepartamento  string
-	DescDistrito      string
-	DescCiudad        string
-
-	// Datos para Cambio de Chofer (Motivo 2)
-	NombreChofer    string
-	DocumentoChofer string
-
-	// Datos para Cambio de Transportista (Motivo 3)
-	RUCTransportista     string // Si es contribuyente
-	DVTransportista      string
-	DocTransportista     string // Si no es contribuyente
-	TipoDocTransportista types.TTipDocRec
-	NombreTransportista  string
-	EsContribuyente      bool
-
-	// Datos para Cambio de Vehículo (Motivo 4)
-	TipoVehiculo  string
-	MarcaVehiculo string
-	PlacaVehiculo string // Número de placa o chasis
-}
-
-// EventoActTransporte: Estructura de actualización de datos del transporte
-type EventoActTransporte struct {
-	DRucEmi    string                        `xml:"dRucEmi"`
-	DDVEmi     string                        `xml:"dDVEmi"`
-	ITipEvt    int16                         `xml:"iTipEvt"`    // 21 = Actualización transporte
-	DCDC       string                        `xml:"dCDC"`       // CDC del documento afectado
-	DMotEv     MotivoActualizacionTransporte `xml:"dMotEv"`     // Motivo de la actualización
-	DDesMotEv  string                        `xml:"dDesMotEv"`  // Descripción del motivo
-	DFecEmiEvt string                        `xml:"dFecEmiEvt"` // Fecha emisión del evento
-
-	// Grupo para cambio de local de entrega
-	GCamLocEnt *GCamLocEntrega `xml:"gCamLocEnt,omitempty"`
-
-	// Grupo para cambio de chofer
-	GCamChof *GCamChofer `xml:"gCamChof,omitempty"`
-
-	// Grupo para cambio de transportista
-	GCamTrans *GCamTransportista `xml:"gCamTrans,omitempty"`
-
-	// Grupo para cambio de vehículo
-	GCamVeh *GCamVehiculo `xml:"gCamVeh,omitempty"`
-}
-
-// GCamLocEntrega: Grupo para cambio de local de entrega
-type GCamLocEntrega struct {
-	DDirLocEnt string              `xml:"dDirLocEnt"`           // Nueva dirección
-	CDepEnt    types.TDepartamento `xml:"cDepEnt"`              // Código departamento
-	DDesDepEnt string              `xml:"dDesDepEnt"`           // Descripción departamento
-	CDisEnt    int16               `xml:"cDisEnt,omitempty"`    // Código distrito
-	DDesDisEnt string              `xml:"dDesDisEnt,omitempty"` // Descripción distrito
-	CCiuEnt    int32               `xml:"cCiuEnt"`              // Código ciudad
-	DDesCiuEnt string              `xml:"dDesCiuEnt"`           // Descripción ciudad
-}
-
-// GCamChofer: Grupo para cambio de chofer
-type GCamChofer struct {
-	DNomChof   string `xml:"dNomChof"`   // Nombre del nuevo chofer
-	DNumIDChof string `xml:"dNumIDChof"` // Número de documento del chofer
-}
-
-// GCamTransportista: Grupo para cambio de transportista
-type GCamTransportista struct {
-	INatTrans    types.TiNatRec   `xml:"iNatTrans"`              // 1=Contribuyente, 2=No contribuyente
-	DNomTrans    string           `xml:"dNomTrans"`              // Nombre del transportista
-	DRucTrans    string           `xml:"dRucTrans,omitempty"`    // RUC (si contribuyente)
-	DDVTrans     string           `xml:"dDVTrans,omitempty"`     // DV del RUC
-	ITipIDTrans  types.TTipDocRec `xml:"iTipIDTrans,omitempty"`  // Tipo documento (si no contribuyente)
-	DDTipIDTrans string           `xml:"dDTipIDTrans,omitempty"` // Descripción tipo documento
-	DNumIDTrans  string           `xml:"dNumIDTrans,omitempty"`  // Número documento
-}
-
-// GCamVehiculo: Grupo para cambio de vehículo
-type GCamVehiculo struct {
-	DTipVeh   string `xml:"dTipVeh"`             // Tipo de vehículo
-	DMarVeh   string `xml:"dMarVeh,omitempty"`   // Marca del vehículo
-	DNumPlaca string `xml:"dNumPlaca,omitempty"` // Número de placa o chasis
-}
-
-func (b *EventBuilder) BuildActualizacionTransporte(data EventoActTransporteData) (*REvento, error) {
-	if len(data.CDC) != 44 {
-		return nil, fmt.Errorf("CDC debe tener 44 caracteres")
-	}
-	if data.Motivo < 1 || data.Motivo > 4 {
-		return nil, fmt.Errorf("motivo debe ser 1, 2, 3 o 4")
-	}
-
-	// Construir estructura base del evento
-	evtTransp := &EventoActTransporte{
-		DRucEmi:    b.rucEmisor,
-		DDVEmi:     b.dvEmisor,
-		ITipEvt:    21,
-		DCDC:       data.CDC,
-		DMotEv:     data.Motivo,
-		DDesMotEv:  data.Motivo.String(),
-		DFecEmiEvt: time.Now().Format("2006-01-02T15:04:05"),
-	}
-
-	// Agregar datos según el motivo
-	switch data.Motivo {
-	case MotivoActTransp_CambioLocalEntrega:
-		if data.NuevaDireccion == "" {
-			return nil, fmt.Errorf("nueva dirección es requerida para cambio de local")
-		}
-		evtTransp.GCamLocEnt = &GCamLocEntrega{
-			DDirLocEnt: data.NuevaDireccion,
-			CDepEnt:    data.NuevoDepartamento,
-			DDesDepEnt: data.DescDepartamento,
-			CDisEnt:    data.NuevoDistrito,
-			DDesDisEnt: data.DescDistrito,
-			CCiuEnt:    data.NuevaCiudad,
-			DDesCiuEnt: data.DescCiudad,
-		}
-
-	case MotivoActTransp_CambioChofer:
-		if data.NombreChofer == "" || data.DocumentoChofer == "" {
-			return nil, fmt.Errorf("nombre y documento del chofer son requeridos")
-		}
-		evtTransp.GCamChof = &GCamChofer{
-			DNomChof:   data.NombreChofer,
-			DNumIDChof: data.DocumentoChofer,
-		}
-
-	case MotivoActTransp_CambioTransportista:
-		if data.NombreTransportista == "" {
-			return nil, fmt.Errorf("nombre del transportista es requerido")
-		}
-		camTrans := &GCamTransportista{
-			DNomTrans: data.NombreTransportista,
-		}
-		if data.EsContribuyente {
-			camTrans.INatTrans = types.TiNatRec_Contribuyente
-			camTrans.DRucTrans = data.RUCTransportista
-			camTrans.DDVTrans = data.DVTransportista
-		} else {
-			camTrans.INatTrans = types.TiNatRec_NoContribuyente
-			camTrans.ITipIDTrans = data.TipoDocTransportista
-			camTrans.DDTipIDTrans = data.TipoDocTransportista.String()
-			camTrans.DNumIDTrans = data.DocTransportista
-		}
-		evtTransp.GCamTrans = camTrans
-
-	case MotivoActTransp_CambioVehiculo:
-		if data.TipoVehiculo == "" || data.PlacaVehiculo == "" {
-			return nil, fmt.Errorf("tipo y placa del vehículo son requeridos")
-		}
-		evtTransp.GCamVeh = &GCamVehiculo{
-			DTipVeh:   data.TipoVehiculo,
-			DMarVeh:   data.MarcaVehiculo,
-			DNumPlaca: data.PlacaVehiculo,
-		}
-	}
-
-	evento := &REvento{
-		XmlnsXsi: "http://www.w3.org/2001/XMLSchema-instance",
-		DId:      b.dId,
-		DDTE:     time.Now().Format("2006-01-02T15:04:05"),
-		GEvento: GEvento{
-			Id:        b.generateEventId(),
-			DFecFirma: time.Now().Format("2006-01-02T15:04:05"),
-			DVerFor:   b.version,
-			GGroupGesEvc: &GGroupGesEve{
-				RGesEveTra: evtTransp,
-			},
-		},
-	}
-
-	return evento, nil
+	}), nil
 }
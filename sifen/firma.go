@@ -0,0 +1,98 @@
+package sifen
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/xml"
+	"fmt"
+
+	"github.com/beevik/etree"
+	"github.com/rodascaar/sifen-go-py/internal/certificate"
+	"github.com/rodascaar/sifen-go-py/internal/signature"
+	"github.com/rodascaar/sifen-go-py/sifen/errors"
+	"github.com/rodascaar/sifen-go-py/sifen/models"
+	"github.com/rodascaar/sifen-go-py/sifen/qr"
+)
+
+// DigestSigner firma el digest SHA-256 del SignedInfo con RSASSA-PKCS1-v1_5.
+// Es la interfaz a implementar para claves que no salen de un token PKCS#11
+// o de un KMS en la nube; se usa con NewDigestCryptoSigner y ClaveFirma.
+type DigestSigner = signature.DigestSigner
+
+// NewDigestCryptoSigner adapta un DigestSigner a crypto.Signer para SifenConfig.ClaveFirma
+func NewDigestCryptoSigner(ds DigestSigner) crypto.Signer {
+	return signature.FromDigestSigner(ds)
+}
+
+// ResultadoFirma es el resultado de verificar un elemento Signature: el Id y
+// la etiqueta del elemento firmado, el digest recalculado, el certificado del
+// firmante y el motivo de la falla (Err nil si la firma es válida)
+type ResultadoFirma = signature.Result
+
+// VerificarFirma verifica las firmas de un DE o evento recibido: recalcula el
+// digest C14N exclusivo del elemento referenciado y valida el SignatureValue
+// RSA-SHA256 con el X509Certificate incluido. Retorna errors.ErrFirmaNoEncontrada
+// si no hay firma y errors.ErrFirmaNoValida si alguna no verifica. El certificado
+// no se valida contra una cadena de confianza; InspeccionarCertificado permite
+// comparar su RUC con el del proveedor.
+func VerificarFirma(xml []byte) ([]ResultadoFirma, error) {
+	return signature.Verify(xml)
+}
+
+// InspeccionarCertificado retorna sujeto, emisor, serie, vigencia y RUC de cert
+func InspeccionarCertificado(cert *x509.Certificate) CertificadoInfo {
+	return certificate.Inspect(cert)
+}
+
+// FirmarDE firma el DE y completa gCamFuFD con el QR calculado a partir del
+// DigestValue de la firma, el IdCSC y el CSC de la configuración. Retorna el
+// rDE firmado tal como se envía a SIFEN; de.GCamFuFD queda con el mismo QR
+// para generar el KuDE.
+func (c *SifenClient) FirmarDE(de *models.DocumentoElectronico) ([]byte, error) {
+	if c.signer == nil {
+		return nil, errors.ErrCertificadoNoEncontrado.WithCause(fmt.Errorf("no hay certificado de firma configurado"))
+	}
+	return c.signDE(de)
+}
+
+// signDE signs the DE and fills gCamFuFD; without a signer it only marshals it
+func (c *SifenClient) signDE(de *models.DocumentoElectronico) ([]byte, error) {
+	// gCamFuFD depends on the signature, so it is never part of the input
+	de.GCamFuFD = nil
+	deBytes, err := xml.Marshal(de)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal DE")
+	}
+	if c.signer == nil {
+		return deBytes, nil
+	}
+
+	signed, err := c.sign(deBytes, de.DE.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(signed); err != nil {
+		return nil, fmt.Errorf("failed to parse signed DE: %w", err)
+	}
+	// The URI is compared directly; the Id is caller data and must not end up
+	// inside an etree path
+	var digest *etree.Element
+	for _, ref := range doc.FindElements("//Reference") {
+		if ref.SelectAttrValue("URI", "") == "#"+de.DE.Id {
+			digest = ref.SelectElement("DigestValue")
+			break
+		}
+	}
+	if digest == nil {
+		return nil, errors.ErrFirmaInvalida.WithCause(fmt.Errorf("DigestValue for Id='%s' not found", de.DE.Id))
+	}
+
+	url := qr.FromDE(de, digest.Text(), c.config.IdCSC).URL(c.config.UrlConsultaQr, c.config.CSC)
+	de.GCamFuFD = &models.GCamFuFD{DCarQR: url}
+
+	// gCamFuFD goes last in rDE, after the Signature
+	doc.Root().CreateElement("gCamFuFD").CreateElement("dCarQR").SetText(url)
+	return doc.WriteToBytes()
+}
@@ -2,9 +2,7 @@ package kude
 
 import (
 	"bytes"
-	"crypto/sha256"
 	"encoding/base64"
-	"encoding/hex"
 	"fmt"
 	"html/template"
 	"time"
@@ -19,9 +17,6 @@ import (
 
 // KuDEConfig contiene la configuración para generar el KuDE
 type KuDEConfig struct {
-	BaseURLQR        string // URL base para QR (ej: https://ekuatia.set.gov.py/consultas/qr?)
-	CSC              string // Código de Seguridad del Contribuyente
-	IdCSC            string // ID del CSC
 	LogoEmisorPath   string // Ruta opcional al logo del emisor
 	LogoEmisorBase64 string // O logo en Base64
 }
@@ -77,7 +72,7 @@ type KuDEData struct {
 	InformacionInteres string // Campo J003
 	ActividadEconomica string // Descripción de actividad económica
 
-	// QR generado
+	// QR: contenido de gCamFuFD.dCarQR, completado al firmar el DE
 	URLCompleta  string
 	QRCodeBase64 string // QR como imagen Base64 (si se genera externamente)
 }
@@ -105,34 +100,6 @@ func NewKuDEGenerator(config KuDEConfig) *KuDEGenerator {
 	return &KuDEGenerator{config: config}
 }
 
-// GenerateQRURL genera la URL completa para el código QR según especificación SIFEN
-func (g *KuDEGenerator) GenerateQRURL(data KuDEData) string {
-	// Construir cadena para hash
-	// Formato: nVersion=150&Id=CDC&dFeEmiDE=fecha&dRucRec=RUC&dTotGralOpe=total&dTotIVA=iva&cItems=items&DigestValue=digest&IdCSC=idcsc&cHashQR=hash
-
-	fechaEmision := data.FechaEmision.Format("2006-01-02T15:04:05")
-
-	// Concatenar para generar hash
-	// El hash se calcula sobre los parámetros + CSC
-	paramsForHash := fmt.Sprintf("nVersion=150&Id=%s&dFeEmiDE=%s&dRucRec=%s&dTotGralOpe=%.2f&dTotIVA=%.2f&cItems=%d&DigestValue=&IdCSC=%s",
-		data.CDC,
-		fechaEmision,
-		data.RUCReceptor,
-		data.TotalGeneral,
-		data.TotalIVA,
-		len(data.Items),
-		g.config.IdCSC,
-	)
-
-	// Calcular SHA-256 del string + CSC
-	hashInput := paramsForHash + g.config.CSC
-	hash := sha256.Sum256([]byte(hashInput))
-	hashHex := hex.EncodeToString(hash[:])
-
-	// URL completa
-	return fmt.Sprintf("%s%s&cHashQR=%s", g.config.BaseURLQR, paramsForHash, hashHex)
-}
-
 // GenerateFromDE genera KuDEData desde un DocumentoElectronico
 func (g *KuDEGenerator) GenerateFromDE(de *models.DocumentoElectronico) KuDEData {
 	data := KuDEData{
@@ -176,21 +143,21 @@ func (g *KuDEGenerator) GenerateFromDE(de *models.DocumentoElectronico) KuDEData
 		kudeItem := KuDEItem{
 			Codigo:      item.DCodInt,
 			Descripcion: item.DDesProSer,
-			Cantidad:    item.DCantProSer,
+			Cantidad:    item.DCantProSer.Float64(),
 			Unidad:      item.DDesUniMed,
-			PrecioUnit:  item.GValorItem.DPUniProSer,
+			PrecioUnit:  item.GValorItem.DPUniProSer.Float64(),
 		}
 
 		// Calcular por afectación IVA
 		if item.GCamIVA != nil {
 			switch item.GCamIVA.IAfecIVA {
 			case types.TiAfecIVA_Exento, types.TiAfecIVA_Exonerado:
-				kudeItem.Exenta = item.GValorItem.GValorRestaItem.DTotOpeItem
+				kudeItem.Exenta = item.GValorItem.GValorRestaItem.DTotOpeItem.Float64()
 			default:
-				if item.GCamIVA.DTasaIVA == 5 {
-					kudeItem.IVA5 = item.GValorItem.GValorRestaItem.DTotOpeItem
+				if item.GCamIVA.DTasaIVA == types.DecimalFromInt(5) {
+					kudeItem.IVA5 = item.GValorItem.GValorRestaItem.DTotOpeItem.Float64()
 				} else {
-					kudeItem.IVA10 = item.GValorItem.GValorRestaItem.DTotOpeItem
+					kudeItem.IVA10 = item.GValorItem.GValorRestaItem.DTotOpeItem.Float64()
 				}
 			}
 		}
@@ -199,21 +166,23 @@ func (g *KuDEGenerator) GenerateFromDE(de *models.DocumentoElectronico) KuDEData
 	}
 
 	// Totales
-	if de.DE.GTotSub != nil {
-		data.SubtotalExentas = de.DE.GTotSub.DSubExe
-		data.SubtotalIVA5 = de.DE.GTotSub.DSub5
-		data.SubtotalIVA10 = de.DE.GTotSub.DSub10
-		data.TotalIVA5 = de.DE.GTotSub.DLiqTotIVA5
-		data.TotalIVA10 = de.DE.GTotSub.DLiqTotIVA10
-		data.TotalIVA = de.DE.GTotSub.DTotIVA
-		data.TotalGeneral = de.DE.GTotSub.DTotGralOpe
+	if tot := de.DE.GTotSub; tot != nil {
+		data.SubtotalExentas = tot.DSubExe.Float64()
+		data.SubtotalIVA5 = value(tot.DSub5).Float64()
+		data.SubtotalIVA10 = value(tot.DSub10).Float64()
+		data.TotalIVA5 = value(tot.DIVA5).Sub(value(tot.DLiqTotIVA5)).Float64()
+		data.TotalIVA10 = value(tot.DIVA10).Sub(value(tot.DLiqTotIVA10)).Float64()
+		data.TotalIVA = value(tot.DTotIVA).Float64()
+		data.TotalGeneral = tot.DTotGralOpe.Float64()
 	}
 
 	// Monto en Letras
 	data.MontoLetras = numeroALetras(data.TotalGeneral, data.Moneda)
 
-	// Generar URL QR
-	data.URLCompleta = g.GenerateQRURL(data)
+	// URL QR, calculada al firmar con el DigestValue real
+	if de.GCamFuFD != nil {
+		data.URLCompleta = de.GCamFuFD.DCarQR
+	}
 
 	return data
 }
@@ -248,6 +217,15 @@ func (g *KuDEGenerator) GenerateHTMLBase64(data KuDEData) (string, error) {
 }
 
 // Helpers
+
+// value retorna el monto de un campo opcional, o cero si no se informó
+func value(d *types.Decimal) types.Decimal {
+	if d == nil {
+		return types.Decimal{}
+	}
+	return *d
+}
+
 func formatMoney(amount float64) string {
 	// Formato manual con separador de miles
 	// Go fmt no soporta %V para miles nativamente de forma estándar en todas las versiones
@@ -0,0 +1,369 @@
+package sifen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rodascaar/sifen-go-py/sifen/types"
+	"gopkg.in/yaml.v3"
+)
+
+// ============================================================================
+// Carga de Configuración desde Archivo y Variables de Entorno
+// ============================================================================
+
+// EnvConfigFile es la variable de entorno con la ruta del archivo de configuración
+// que usa LoadConfig cuando no recibe una ruta
+const EnvConfigFile = "SIFEN_CONFIG_FILE"
+
+// configField asocia una clave del archivo y una variable de entorno a un campo
+type configField struct {
+	key string // clave en el archivo; las anidadas se escriben "cache.rucTTL"
+	env string
+	set func(c *SifenConfig, value string) error
+}
+
+// configFields lista los campos configurables desde archivo o entorno.
+// Las duraciones aceptan el formato de time.ParseDuration ("15s", "1m30s");
+// un número sin unidad se interpreta como milisegundos.
+var configFields = []configField{
+	// El ambiente va primero: sus URLs por defecto no deben pisar las
+	// indicadas con urlBase o urlConsultaQr en ninguna de las fuentes
+	{"ambiente", "SIFEN_AMBIENTE", func(c *SifenConfig, v string) error {
+		switch env := TipoAmbiente(strings.ToUpper(v)); env {
+		case TipoAmbienteDev, TipoAmbienteProd:
+			c.SetAmbiente(env)
+			return nil
+		}
+		return fmt.Errorf("ambiente %q inválido (DEV o PROD)", v)
+	}},
+	{"urlBase", "SIFEN_URL_BASE", setString(func(c *SifenConfig) *string { return &c.UrlBase })},
+	{"urlConsultaQr", "SIFEN_URL_CONSULTA_QR", setString(func(c *SifenConfig) *string { return &c.UrlConsultaQr })},
+
+	{"pathRecibe", "SIFEN_PATH_RECIBE", setString(func(c *SifenConfig) *string { return &c.PathRecibe })},
+	{"pathRecibeLote", "SIFEN_PATH_RECIBE_LOTE", setString(func(c *SifenConfig) *string { return &c.PathRecibeLote })},
+	{"pathEvento", "SIFEN_PATH_EVENTO", setString(func(c *SifenConfig) *string { return &c.PathEvento })},
+	{"pathConsultaLote", "SIFEN_PATH_CONSULTA_LOTE", setString(func(c *SifenConfig) *string { return &c.PathConsultaLote })},
+	{"pathConsultaRUC", "SIFEN_PATH_CONSULTA_RUC", setString(func(c *SifenConfig) *string { return &c.PathConsultaRUC })},
+	{"pathConsulta", "SIFEN_PATH_CONSULTA", setString(func(c *SifenConfig) *string { return &c.PathConsulta })},
+
+	{"usarCertificadoCliente", "SIFEN_USAR_CERTIFICADO_CLIENTE", setBool(func(c *SifenConfig) *bool { return &c.UsarCertificadoCliente })},
+	{"tipoCertificadoCliente", "SIFEN_TIPO_CERTIFICADO_CLIENTE", func(c *SifenConfig, v string) error {
+		c.TipoCertificadoCliente = TipoCertificadoCliente(strings.ToUpper(v))
+		return nil
+	}},
+	// Ruta del archivo, contenido PEM o contenido en Base64
+	{"certificadoCliente", "SIFEN_CERTIFICADO_CLIENTE", setString(func(c *SifenConfig) *string { return &c.CertificadoCliente })},
+	{"clavePrivadaCliente", "SIFEN_CLAVE_PRIVADA_CLIENTE", setString(func(c *SifenConfig) *string { return &c.ClavePrivadaCliente })},
+	{"contrasenaCertificadoCliente", "SIFEN_CONTRASENA_CERTIFICADO_CLIENTE", setString(func(c *SifenConfig) *string { return &c.ContrasenaCertificadoCliente })},
+	{"diasAvisoVencimientoCertificado", "SIFEN_DIAS_AVISO_VENCIMIENTO_CERTIFICADO", setInt(func(c *SifenConfig) *int { return &c.DiasAvisoVencimientoCertificado })},
+
+	{"idCSC", "SIFEN_ID_CSC", func(c *SifenConfig, v string) error { c.SetIdCSC(v); return nil }},
+	{"csc", "SIFEN_CSC", setString(func(c *SifenConfig) *string { return &c.CSC })},
+
+	{"rucEmisor", "SIFEN_RUC_EMISOR", setString(func(c *SifenConfig) *string { return &c.RucEmisor })},
+	{"dvEmisor", "SIFEN_DV_EMISOR", setString(func(c *SifenConfig) *string { return &c.DvEmisor })},
+	{"tipoContribuyente", "SIFEN_TIPO_CONTRIBUYENTE", func(c *SifenConfig, v string) error {
+		n, err := strconv.ParseInt(v, 10, 16)
+		if err != nil {
+			return fmt.Errorf("tipo de contribuyente %q inválido", v)
+		}
+		c.TipoContribuyente = types.TiTipCont(n)
+		return nil
+	}},
+	{"establecimientoDefecto", "SIFEN_ESTABLECIMIENTO_DEFECTO", setString(func(c *SifenConfig) *string { return &c.EstablecimientoDefecto })},
+
+	{"httpConnectTimeout", "SIFEN_HTTP_CONNECT_TIMEOUT", setMillis(func(c *SifenConfig) *int { return &c.HttpConnectTimeout })},
+	{"httpTLSHandshakeTimeout", "SIFEN_HTTP_TLS_HANDSHAKE_TIMEOUT", setMillis(func(c *SifenConfig) *int { return &c.HttpTLSHandshakeTimeout })},
+	{"httpReadTimeout", "SIFEN_HTTP_READ_TIMEOUT", setMillis(func(c *SifenConfig) *int { return &c.HttpReadTimeout })},
+	{"userAgent", "SIFEN_USER_AGENT", setString(func(c *SifenConfig) *string { return &c.UserAgent })},
+
+	{"cache.defaultTTL", "SIFEN_CACHE_DEFAULT_TTL", setDuration(func(c *SifenConfig) *time.Duration { return &c.CacheConfig.DefaultTTL })},
+	{"cache.rucTTL", "SIFEN_CACHE_RUC_TTL", setDuration(func(c *SifenConfig) *time.Duration { return &c.CacheConfig.RUCTTL })},
+	{"cache.deTTL", "SIFEN_CACHE_DE_TTL", setDuration(func(c *SifenConfig) *time.Duration { return &c.CacheConfig.DETTL })},
+	{"cache.maxSize", "SIFEN_CACHE_MAX_SIZE", setInt(func(c *SifenConfig) *int { return &c.CacheConfig.MaxSize })},
+	{"cache.enableAutoCleanup", "SIFEN_CACHE_ENABLE_AUTO_CLEANUP", setBool(func(c *SifenConfig) *bool { return &c.CacheConfig.EnableAutoCleanup })},
+	{"cache.cleanupInterval", "SIFEN_CACHE_CLEANUP_INTERVAL", setDuration(func(c *SifenConfig) *time.Duration { return &c.CacheConfig.CleanupInterval })},
+
+	{"retry.maxAttempts", "SIFEN_RETRY_MAX_ATTEMPTS", setInt(func(c *SifenConfig) *int { return &c.RetryPolicy.MaxAttempts })},
+	{"retry.baseDelay", "SIFEN_RETRY_BASE_DELAY", setDuration(func(c *SifenConfig) *time.Duration { return &c.RetryPolicy.BaseDelay })},
+	{"retry.maxDelay", "SIFEN_RETRY_MAX_DELAY", setDuration(func(c *SifenConfig) *time.Duration { return &c.RetryPolicy.MaxDelay })},
+
+	{"auditDir", "SIFEN_AUDIT_DIR", func(c *SifenConfig, v string) error {
+		sink, err := NewFileAuditSink(v)
+		if err != nil {
+			return err
+		}
+		c.AuditSink = sink
+		return nil
+	}},
+}
+
+// configSource es un origen de valores: el archivo o el entorno
+type configSource struct {
+	lookup func(configField) (string, bool)
+	name   func(configField) string // nombre del campo en los errores
+}
+
+// LoadConfig crea una configuración con los valores de NewSifenConfig, aplica
+// el archivo path (JSON o YAML) y luego las variables de entorno SIFEN_*, que
+// tienen prioridad sobre el archivo. Si path está vacío se usa la variable
+// SIFEN_CONFIG_FILE; si ésta tampoco está definida solo se lee el entorno.
+//
+// El ambiente se aplica antes que el resto de los campos de ambas fuentes, así
+// SIFEN_AMBIENTE no descarta una URL indicada en el archivo.
+func LoadConfig(path string) (*SifenConfig, error) {
+	if path == "" {
+		path = os.Getenv(EnvConfigFile)
+	}
+
+	var sources []configSource
+	if path != "" {
+		file, err := fileSource(path)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, file)
+	}
+	sources = append(sources, envSource())
+
+	config := NewSifenConfig()
+	if err := config.apply(sources...); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// LoadFile aplica la configuración del archivo JSON o YAML en path.
+// El formato se deduce de la extensión (.json, .yaml, .yml).
+func (c *SifenConfig) LoadFile(path string) error {
+	file, err := fileSource(path)
+	if err != nil {
+		return err
+	}
+	return c.apply(file)
+}
+
+// LoadEnv aplica las variables de entorno SIFEN_* definidas
+func (c *SifenConfig) LoadEnv() error {
+	return c.apply(envSource())
+}
+
+func fileSource(path string) (configSource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return configSource{}, fmt.Errorf("no se pudo leer la configuración: %w", err)
+	}
+
+	values, err := parseConfigFile(path, data)
+	if err != nil {
+		return configSource{}, fmt.Errorf("configuración %s inválida: %w", path, err)
+	}
+
+	known := make(map[string]bool, len(configFields))
+	for _, f := range configFields {
+		known[f.key] = true
+	}
+	var unknown []string
+	for key := range values {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return configSource{}, fmt.Errorf("configuración %s: claves desconocidas %s", path, strings.Join(unknown, ", "))
+	}
+
+	return configSource{
+		lookup: func(f configField) (string, bool) {
+			v, ok := values[f.key]
+			return v, ok
+		},
+		name: func(f configField) string { return f.key },
+	}, nil
+}
+
+func envSource() configSource {
+	return configSource{
+		lookup: func(f configField) (string, bool) { return os.LookupEnv(f.env) },
+		name:   func(f configField) string { return f.env },
+	}
+}
+
+// apply recorre los campos en el orden de configFields y, en cada uno, las
+// fuentes en orden: la última que define el campo tiene prioridad
+func (c *SifenConfig) apply(sources ...configSource) error {
+	for _, f := range configFields {
+		for _, src := range sources {
+			value, ok := src.lookup(f)
+			if !ok {
+				continue
+			}
+			if err := f.set(c, strings.TrimSpace(value)); err != nil {
+				return fmt.Errorf("%s: %w", src.name(f), err)
+			}
+		}
+	}
+	return nil
+}
+
+// parseConfigFile decodifica el archivo y aplana las secciones anidadas
+// en claves "seccion.campo". Los valores se conservan tal como están escritos,
+// así un CSC o un establecimiento numérico sin comillas no pierde dígitos ni
+// ceros a la izquierda.
+func parseConfigFile(path string, data []byte) (map[string]string, error) {
+	values := make(map[string]string)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		if err := flattenYAML("", &doc, values); err != nil {
+			return nil, err
+		}
+	default:
+		var raw map[string]interface{}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		if err := flattenConfig("", raw, values); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+func flattenConfig(prefix string, raw map[string]interface{}, out map[string]string) error {
+	for key, value := range raw {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if err := flattenConfig(key, v, out); err != nil {
+				return err
+			}
+		case []interface{}:
+			return fmt.Errorf("%s: no se admiten listas", key)
+		case nil:
+		default:
+			out[key] = fmt.Sprint(v)
+		}
+	}
+	return nil
+}
+
+// flattenYAML aplana un documento YAML tomando el texto de cada escalar, sin
+// convertirlo a número
+func flattenYAML(key string, n *yaml.Node, out map[string]string) error {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			if err := flattenYAML(key, c, out); err != nil {
+				return err
+			}
+		}
+	case yaml.AliasNode:
+		return flattenYAML(key, n.Alias, out)
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			name := n.Content[i].Value
+			if key != "" {
+				name = key + "." + name
+			}
+			if err := flattenYAML(name, n.Content[i+1], out); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		return fmt.Errorf("%s: no se admiten listas", key)
+	case yaml.ScalarNode:
+		if key == "" {
+			return fmt.Errorf("se esperaba un mapa de claves")
+		}
+		if n.Tag != "!!null" {
+			out[key] = n.Value
+		}
+	}
+	return nil
+}
+
+// ============================================================================
+// Conversión de Valores
+// ============================================================================
+
+// ParseDuration interpreta una duración legible ("45s", "1m30s", "30m").
+// Un número sin unidad se interpreta como milisegundos.
+func ParseDuration(value string) (time.Duration, error) {
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("duración %q inválida", value)
+	}
+	return d, nil
+}
+
+func setString(field func(*SifenConfig) *string) func(*SifenConfig, string) error {
+	return func(c *SifenConfig, v string) error {
+		*field(c) = v
+		return nil
+	}
+}
+
+func setBool(field func(*SifenConfig) *bool) func(*SifenConfig, string) error {
+	return func(c *SifenConfig, v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("valor booleano %q inválido", v)
+		}
+		*field(c) = b
+		return nil
+	}
+}
+
+func setInt(field func(*SifenConfig) *int) func(*SifenConfig, string) error {
+	return func(c *SifenConfig, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("valor entero %q inválido", v)
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+func setDuration(field func(*SifenConfig) *time.Duration) func(*SifenConfig, string) error {
+	return func(c *SifenConfig, v string) error {
+		d, err := ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		*field(c) = d
+		return nil
+	}
+}
+
+// setMillis asigna una duración a un campo expresado en milisegundos
+func setMillis(field func(*SifenConfig) *int) func(*SifenConfig, string) error {
+	return func(c *SifenConfig, v string) error {
+		d, err := ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		*field(c) = int(d / time.Millisecond)
+		return nil
+	}
+}
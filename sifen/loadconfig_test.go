@@ -0,0 +1,131 @@
+package sifen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfigFileAndEnv(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "sifen.yaml")
+	err := os.WriteFile(yamlPath, []byte(`
+ambiente: prod
+csc: A1B2C3D4E5F6A1B2C3D4E5F6A1B2C3D4
+idCSC: "1"
+rucEmisor: 80069563-1
+certificadoCliente: /certs/emisor.pfx
+httpConnectTimeout: 10s
+httpReadTimeout: 30000
+cache:
+  rucTTL: 1h
+  deTTL: 2m30s
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("SIFEN_CSC", "Z9Y8X7W6V5U4Z9Y8X7W6V5U4Z9Y8X7W6")
+	t.Setenv("SIFEN_CACHE_DE_TTL", "5m")
+
+	config, err := LoadConfig(yamlPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if config.Ambiente != TipoAmbienteProd || config.UrlBaseLocal != URL_BASE_PROD {
+		t.Errorf("Ambiente = %s, UrlBaseLocal = %s; want PROD", config.Ambiente, config.UrlBaseLocal)
+	}
+	if config.CSC != "Z9Y8X7W6V5U4Z9Y8X7W6V5U4Z9Y8X7W6" {
+		t.Errorf("CSC = %s; want the value from SIFEN_CSC", config.CSC)
+	}
+	if config.IdCSC != "0001" {
+		t.Errorf("IdCSC = %s; want 0001", config.IdCSC)
+	}
+	if config.HttpConnectTimeout != 10000 || config.HttpReadTimeout != 30000 {
+		t.Errorf("timeouts = %d/%d; want 10000/30000", config.HttpConnectTimeout, config.HttpReadTimeout)
+	}
+	if config.CacheConfig.RUCTTL != time.Hour || config.CacheConfig.DETTL != 5*time.Minute {
+		t.Errorf("cache TTLs = %v/%v; want 1h/5m", config.CacheConfig.RUCTTL, config.CacheConfig.DETTL)
+	}
+	if config.CertificadoCliente != "/certs/emisor.pfx" || config.RucEmisor != "80069563-1" {
+		t.Errorf("CertificadoCliente = %s, RucEmisor = %s", config.CertificadoCliente, config.RucEmisor)
+	}
+}
+
+func TestLoadConfigEnvAmbienteKeepsFileURLs(t *testing.T) {
+	jsonPath := filepath.Join(t.TempDir(), "sifen.json")
+	err := os.WriteFile(jsonPath, []byte(`{"ambiente": "DEV", "urlConsultaQr": "https://qr.example.com/consulta?"}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SIFEN_AMBIENTE", "PROD")
+
+	config, err := LoadConfig(jsonPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if config.Ambiente != TipoAmbienteProd || config.UrlBaseLocal != URL_BASE_PROD {
+		t.Errorf("Ambiente = %s, UrlBaseLocal = %s; want PROD", config.Ambiente, config.UrlBaseLocal)
+	}
+	if config.UrlConsultaQr != "https://qr.example.com/consulta?" {
+		t.Errorf("UrlConsultaQr = %s; want the URL from the file", config.UrlConsultaQr)
+	}
+}
+
+func TestLoadConfigKeepsNumericStrings(t *testing.T) {
+	yamlPath := filepath.Join(t.TempDir(), "sifen.yml")
+	err := os.WriteFile(yamlPath, []byte(`
+csc: 12345678901234567890123456789012
+idCSC: 0002
+establecimientoDefecto: 001
+retry:
+  maxAttempts: 5
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadConfig(yamlPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if config.CSC != "12345678901234567890123456789012" || config.IdCSC != "0002" || config.EstablecimientoDefecto != "001" {
+		t.Errorf("CSC = %s, IdCSC = %s, EstablecimientoDefecto = %s; want the values as written",
+			config.CSC, config.IdCSC, config.EstablecimientoDefecto)
+	}
+	if config.RetryPolicy.MaxAttempts != 5 {
+		t.Errorf("RetryPolicy.MaxAttempts = %d; want 5", config.RetryPolicy.MaxAttempts)
+	}
+}
+
+func TestLoadConfigRejectsUnknownKeys(t *testing.T) {
+	jsonPath := filepath.Join(t.TempDir(), "sifen.json")
+	if err := os.WriteFile(jsonPath, []byte(`{"csc": "X", "cache": {"rucTtl": "1h"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := LoadConfig(jsonPath)
+	if err == nil || !strings.Contains(err.Error(), "cache.rucTtl") {
+		t.Errorf("LoadConfig() error = %v; want unknown key cache.rucTtl", err)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"15000": 15 * time.Second,
+		"45s":   45 * time.Second,
+		"1m30s": 90 * time.Second,
+	}
+	for input, want := range tests {
+		got, err := ParseDuration(input)
+		if err != nil || got != want {
+			t.Errorf("ParseDuration(%q) = %v, %v; want %v", input, got, err, want)
+		}
+	}
+	if _, err := ParseDuration("quince"); err == nil {
+		t.Error("ParseDuration(\"quince\") succeeded; want error")
+	}
+}
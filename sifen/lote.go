@@ -3,11 +3,12 @@ package sifen
 import (
 	"archive/zip"
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/xml"
 	"fmt"
 
-	"github.com/rodascaar/sifen-go-py/internal/signature"
+	"github.com/rodascaar/sifen-go-py/sifen/errors"
 	"github.com/rodascaar/sifen-go-py/sifen/models"
 	"github.com/rodascaar/sifen-go-py/sifen/request"
 	"github.com/rodascaar/sifen-go-py/sifen/response"
@@ -24,13 +25,13 @@ const (
 )
 
 // ============================================================================
-// Estructura XML del Lote según ProtProcesLoteDE_v150.xsd
+// Estructura XML del Lote según siRecepLoteDE_v150.xsd
 // ============================================================================
 
-// RLoteDE representa la raíz del lote de documentos electrónicos
+// RLoteDE representa la raíz del lote de documentos electrónicos, para leer
+// el contenido de un lote. CrearLoteDE escribe cada rDE firmado tal cual.
 type RLoteDE struct {
 	XMLName xml.Name `xml:"rLoteDE"`
-	DVerFor int16    `xml:"dVerFor"` // Versión del formato (150)
 	// Lista de documentos electrónicos firmados
 	RDEList []RDEWrapper `xml:"rDE"`
 }
@@ -38,7 +39,7 @@ type RLoteDE struct {
 // RDEWrapper envuelve cada DE firmado dentro del lote
 type RDEWrapper struct {
 	XMLName xml.Name `xml:"rDE"`
-	// Contenido del DE firmado (se inserta como raw XML)
+	// Contenido del DE firmado, sin el elemento rDE
 	InnerXML string `xml:",innerxml"`
 }
 
@@ -84,47 +85,28 @@ func (c *SifenClient) CrearLoteDE(params LoteParams) (string, error) {
 		}
 	}
 
-	// 2. Firmar cada documento individualmente
-	var rdeList []RDEWrapper
-	for _, de := range params.Documentos {
-		deBytes, err := xml.Marshal(de)
-		if err != nil {
-			return "", fmt.Errorf("error al serializar DE %s: %w", de.DE.Id, err)
-		}
+	return c.comprimirLote(params.Documentos)
+}
 
-		// Firmar si está configurado
-		signedBytes := deBytes
-		if c.config.UsarCertificadoCliente {
-			cert := c.soapClient.GetCertificate()
-			if cert.PrivateKey != nil {
-				signer := signature.NewSigner(cert)
-				signedBytes, err = signer.Sign(deBytes, de.DE.Id)
-				if err != nil {
-					return "", fmt.Errorf("error al firmar DE %s: %w", de.DE.Id, err)
-				}
-			}
+// comprimirLote firma cada documento, arma el rLoteDE según
+// siRecepLoteDE_v150.xsd (los rDE firmados van directamente en rLoteDE), lo
+// comprime en un .zip y retorna su Base64 para el xDE de rEnvioLote
+func (c *SifenClient) comprimirLote(docs []*models.DocumentoElectronico) (string, error) {
+	loteXML := new(bytes.Buffer)
+	loteXML.WriteString(`<?xml version="1.0" encoding="UTF-8"?>`)
+	loteXML.WriteString(`<rLoteDE xmlns="` + models.Namespace + `">`)
+	for _, de := range docs {
+		// Firmar si está configurado y completar el QR
+		signedBytes, err := c.signDE(de)
+		if err != nil {
+			return "", fmt.Errorf("error al firmar DE %s: %w", de.DE.Id, err)
 		}
-
-		rdeList = append(rdeList, RDEWrapper{
-			InnerXML: string(signedBytes),
-		})
-	}
-
-	// 3. Construir estructura del lote según ProtProcesLoteDE_v150.xsd
-	lote := RLoteDE{
-		DVerFor: 150,
-		RDEList: rdeList,
-	}
-
-	loteXML, err := xml.Marshal(lote)
-	if err != nil {
-		return "", fmt.Errorf("error al serializar lote: %w", err)
+		loteXML.Write(signedBytes)
 	}
+	loteXML.WriteString(`</rLoteDE>`)
+	loteXMLFull := loteXML.Bytes()
 
-	// Agregar declaración XML
-	loteXMLFull := append([]byte(`<?xml version="1.0" encoding="UTF-8"?>`), loteXML...)
-
-	// 4. Comprimir en formato .zip
+	// Comprimir en formato .zip
 	zipBuffer := new(bytes.Buffer)
 	zipWriter := zip.NewWriter(zipBuffer)
 
@@ -151,7 +133,7 @@ func (c *SifenClient) CrearLoteDE(params LoteParams) (string, error) {
 			sizeKB, MaxSizeLoteKB)
 	}
 
-	// 5. Codificar en Base64
+	// Codificar en Base64
 	base64Content := base64.StdEncoding.EncodeToString(zipBuffer.Bytes())
 
 	return base64Content, nil
@@ -159,36 +141,50 @@ func (c *SifenClient) CrearLoteDE(params LoteParams) (string, error) {
 
 // EnviarLoteDE envía un lote de documentos para procesamiento asíncrono
 func (c *SifenClient) EnviarLoteDE(params LoteParams) (*LoteResult, error) {
+	return c.EnviarLoteDEContext(context.Background(), params)
+}
+
+// EnviarLoteDEContext es como EnviarLoteDE pero respeta la cancelación y el plazo de ctx
+func (c *SifenClient) EnviarLoteDEContext(ctx context.Context, params LoteParams) (*LoteResult, error) {
 	// 1. Crear el lote comprimido y codificado
 	base64Content, err := c.CrearLoteDE(params)
 	if err != nil {
 		return nil, err
 	}
 
-	// 2. Construir request según especificación SIFEN
-	req := request.REnviLoteDe{
-		DId:     c.nextID(),
-		XDEList: []request.XDE{{RawRDE: []byte(base64Content)}},
+	cdcs := make([]string, 0, len(params.Documentos))
+	for _, de := range params.Documentos {
+		cdcs = append(cdcs, de.DE.Id)
 	}
 
-	url := c.getURL(c.config.PathRecibeLote)
+	// 2. Enviar según especificación SIFEN
+	var resp *response.RespuestaRecepcionLoteDE
+	err = c.withRetry(ctx, OpEnviarLoteDE, func() (string, error) {
+		dId, err := c.nextID()
+		if err != nil {
+			return "", err
+		}
+		req := request.REnvioLote{
+			DId: dId,
+			XDE: base64Content,
+		}
 
-	rawResp, err := c.soapClient.Send(url, req)
-	if err != nil {
-		return nil, err
-	}
+		body, err := c.exchange(ctx, OpEnviarLoteDE, c.config.PathRecibeLote, req, auditMeta{dId: req.DId, cdcs: cdcs})
+		if err != nil {
+			return "", err
+		}
 
-	var env response.EnvelopeRefResponse
-	if err := xml.Unmarshal(rawResp, &env); err != nil {
-		return nil, fmt.Errorf("error al deserializar respuesta: %w", err)
-	}
+		if body.RRetEnviLoteDe == nil {
+			return "", errors.NewSifenResponseError("EMPTY_RESPONSE", "respuesta vacía o inválida")
+		}
 
-	if env.Body.RRetEnviLoteDe == nil {
-		return nil, fmt.Errorf("respuesta vacía o inválida")
+		resp = body.RRetEnviLoteDe
+		return resp.DCodRes, nil
+	}, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	resp := env.Body.RRetEnviLoteDe
-
 	return &LoteResult{
 		NumeroLote:      resp.DProtConsLot,
 		TiempoEstimado:  resp.DTmpLot,
@@ -199,30 +195,41 @@ func (c *SifenClient) EnviarLoteDE(params LoteParams) (*LoteResult, error) {
 
 // ConsultarResultadoLote consulta el estado de un lote enviado previamente
 func (c *SifenClient) ConsultarResultadoLote(numeroLote string) (*response.RespuestaConsultaLoteDE, error) {
+	return c.ConsultarResultadoLoteContext(context.Background(), numeroLote)
+}
+
+// ConsultarResultadoLoteContext es como ConsultarResultadoLote pero respeta la cancelación y el plazo de ctx
+func (c *SifenClient) ConsultarResultadoLoteContext(ctx context.Context, numeroLote string) (*response.RespuestaConsultaLoteDE, error) {
 	if numeroLote == "" {
 		return nil, fmt.Errorf("número de lote es requerido")
 	}
 
-	req := request.REnviConsLoteDe{
-		DId:           c.nextID(),
-		DProtConsLote: numeroLote,
-	}
+	var resp *response.RespuestaConsultaLoteDE
+	err := c.withRetry(ctx, OpConsultarResultadoLote, func() (string, error) {
+		dId, err := c.nextID()
+		if err != nil {
+			return "", err
+		}
+		req := request.REnviConsLoteDe{
+			DId:           dId,
+			DProtConsLote: numeroLote,
+		}
+
+		body, err := c.exchange(ctx, OpConsultarResultadoLote, c.config.PathConsultaLote, req, auditMeta{dId: req.DId, lote: numeroLote})
+		if err != nil {
+			return "", err
+		}
 
-	url := c.getURL(c.config.PathConsultaLote)
+		if body.RResEnviConsLoteDe == nil {
+			return "", errors.NewSifenResponseError("EMPTY_RESPONSE", "respuesta vacía o inválida")
+		}
 
-	rawResp, err := c.soapClient.Send(url, req)
+		resp = body.RResEnviConsLoteDe
+		return resp.DCodRes, nil
+	}, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	var env response.EnvelopeRefResponse
-	if err := xml.Unmarshal(rawResp, &env); err != nil {
-		return nil, fmt.Errorf("error al deserializar respuesta: %w", err)
-	}
-
-	if env.Body.RResEnviConsLoteDe == nil {
-		return nil, fmt.Errorf("respuesta vacía o inválida")
-	}
-
-	return env.Body.RResEnviConsLoteDe, nil
+	return resp, nil
 }
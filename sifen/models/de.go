@@ -7,9 +7,13 @@ import (
 	"github.com/rodascaar/sifen-go-py/sifen/types"
 )
 
+// Namespace is the SIFEN XML namespace every rDE element belongs to
+const Namespace = "http://ekuatia.set.gov.py/sifen/xsd"
+
 // DocumentoElectronico represents the rDE XML structure
 type DocumentoElectronico struct {
 	XMLName      xml.Name `xml:"rDE"`
+	Xmlns        string   `xml:"xmlns,attr"`
 	XmlnsXsi     string   `xml:"xmlns:xsi,attr"`
 	XsiSchemaLoc string   `xml:"xsi:schemaLocation,attr"`
 
@@ -67,6 +71,7 @@ type GCamFuFD struct {
 
 func NewDE(id string) *DocumentoElectronico {
 	return &DocumentoElectronico{
+		Xmlns:        Namespace,
 		XmlnsXsi:     "http://www.w3.org/2001/XMLSchema-instance",
 		XsiSchemaLoc: "http://ekuatia.set.gov.py/sifen/xsd siRecepDE_v150.xsd",
 		DVerFor:      150,
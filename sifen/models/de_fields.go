@@ -33,7 +33,7 @@ type TgCamFE struct {
 type TgCompPub struct {
 	DModCont   string `xml:"dModCont"`             // Modalidad de contratación
 	DEntCont   int32  `xml:"dEntCont"`             // Entidad contratante
-	DAnoContP  int16  `xml:"dAnoContP"`            // Año del contrato
+	DAnoCont   int16  `xml:"dAnoCont"`             // Año del contrato
 	DSecCont   int32  `xml:"dSecCont"`             // Secuencia del contrato
 	DFeCodCont string `xml:"dFeCodCont,omitempty"` // Fecha del contrato (yyyy-MM-dd)
 }
@@ -56,20 +56,14 @@ type TgCamAE struct {
 	DDesDisVen   string                `xml:"dDesDisVen,omitempty"` // Descripción distrito
 	CCiuVen      int32                 `xml:"cCiuVen"`              // Código ciudad vendedor
 	DDesCiuVen   string                `xml:"dDesCiuVen"`           // Descripción ciudad
-	DLugarTrans  string                `xml:"dDirProv,omitempty"`   // Lugar de la transacción
-	// Campos lugar de transacción
-	GInfLugTran *TgInfLugTran `xml:"gLugRec,omitempty"` // Lugar de recepción
-}
-
-// TgInfLugTran: Lugar de la Transacción para Autofactura
-type TgInfLugTran struct {
-	DDirLug    string              `xml:"dDirLug"`              // Dirección del lugar
-	CDepLug    types.TDepartamento `xml:"cDepLug"`              // Código departamento
-	DDesDepLug string              `xml:"dDesDepLug"`           // Descripción departamento
-	CDisLug    int16               `xml:"cDisLug,omitempty"`    // Código distrito
-	DDesDisLug string              `xml:"dDesDisLug,omitempty"` // Descripción distrito
-	CCiuLug    int32               `xml:"cCiuLug"`              // Código ciudad
-	DDesCiuLug string              `xml:"dDesCiuLug"`           // Descripción ciudad
+	// Lugar de la transacción
+	DDirProv    string              `xml:"dDirProv"`              // Dirección del lugar de la transacción
+	CDepProv    types.TDepartamento `xml:"cDepProv"`              // Código departamento
+	DDesDepProv string              `xml:"dDesDepProv"`           // Descripción departamento
+	CDisProv    int16               `xml:"cDisProv,omitempty"`    // Código distrito
+	DDesDisProv string              `xml:"dDesDisProv,omitempty"` // Descripción distrito
+	CCiuProv    int32               `xml:"cCiuProv"`              // Código ciudad
+	DDesCiuProv string              `xml:"dDesCiuProv"`           // Descripción ciudad
 }
 
 // ============================================================================
@@ -106,14 +100,14 @@ type TgCamCond struct {
 type TgPaConEIni struct {
 	ITiPago     types.TiTipPago `xml:"iTiPago"`               // Tipo de pago
 	DDesTiPago  string          `xml:"dDesTiPag"`             // Descripción del tipo de pago
-	DMonTiPag   float64         `xml:"dMonTiPag"`             // Monto del pago
+	DMonTiPag   types.Decimal   `xml:"dMonTiPag"`             // Monto del pago
 	CMoneOpe    types.CMondT    `xml:"cMoneTiPag"`            // Moneda del pago
 	DDesMoneOpe string          `xml:"dDMoneTiPag,omitempty"` // Descripción moneda
-	DTiCamTiPag *float64        `xml:"dTiCamTiPag,omitempty"` // Tipo de cambio por pago
+	DTiCamTiPag *types.Decimal  `xml:"dTiCamTiPag,omitempty"` // Tipo de cambio por pago
 	// Campos para tarjeta
 	GTarjeta *TgTarjeta `xml:"gPagTarCD,omitempty"` // Datos de tarjeta
 	// Campos para cheque
-	GCheque *TgCheque `xml:"gPagCheworking,omitempty"` // Datos de cheque
+	GCheque *TgCheque `xml:"gPagCheq,omitempty"` // Datos de cheque
 }
 
 // TgTarjeta: Datos de Pago con Tarjeta (E620-E629)
@@ -124,7 +118,7 @@ type TgTarjeta struct {
 	DRUCProTar  string `xml:"dRUCProTar,omitempty"`  // RUC de procesadora
 	DDVProTar   int16  `xml:"dDVProTar,omitempty"`   // Dígito verificador RUC procesadora
 	IForProPa   int16  `xml:"iForProPa,omitempty"`   // Forma de procesamiento del pago
-	DCoAu662    string `xml:"dCodAu662,omitempty"`   // Código de autorización
+	DCodAuOpe   string `xml:"dCodAuOpe,omitempty"`   // Código de autorización
 }
 
 // TgCheque: Datos de Pago con Cheque (E630-E639)
@@ -139,16 +133,16 @@ type TgCredCond struct {
 	DDesCondCred string              `xml:"dDCondCred"`          // Descripción condición
 	DPlazoCre    string              `xml:"dPlazoCre,omitempty"` // Plazo del crédito
 	DCuotas      int16               `xml:"dCuotas,omitempty"`   // Cantidad de cuotas
-	DMonEnt      float64             `xml:"dMonEnt,omitempty"`   // Monto de la entrega inicial
+	DMonEnt      *types.Decimal      `xml:"dMonEnt,omitempty"`   // Monto de la entrega inicial
 	GCuotas      []TgCuotas          `xml:"gCuotas,omitempty"`   // Detalle de cuotas
 }
 
 // TgCuotas: Detalle de Cuotas (E650-E659)
 type TgCuotas struct {
-	CMoneOpe    types.CMondT `xml:"cMoneCuo"`            // Moneda de la cuota
-	DDesMoneCuo string       `xml:"dDMoneCuo,omitempty"` // Descripción moneda
-	DMonCuota   float64      `xml:"dMonCuota"`           // Monto de la cuota
-	DVencCuo    string       `xml:"dVencCuo,omitempty"`  // Fecha de vencimiento (yyyy-MM-dd)
+	CMoneOpe    types.CMondT  `xml:"cMoneCuo"`            // Moneda de la cuota
+	DDesMoneCuo string        `xml:"dDMoneCuo,omitempty"` // Descripción moneda
+	DMonCuota   types.Decimal `xml:"dMonCuota"`           // Monto de la cuota
+	DVencCuo    string        `xml:"dVencCuo,omitempty"`  // Fecha de vencimiento (yyyy-MM-dd)
 }
 
 // ============================================================================
@@ -163,12 +157,12 @@ type TgCamEsp struct {
 
 // TgGrupEner: Sector Energía Eléctrica (E810-E819)
 type TgGrupEner struct {
-	DNroMed  string  `xml:"dNroMed"`            // Número de medidor
-	DActEner int32   `xml:"dActEner,omitempty"` // Código de actividad
-	DCatEner string  `xml:"dCatEner,omitempty"` // Categoría del servicio
-	DLecAnt  float64 `xml:"dLecAnt,omitempty"`  // Lectura anterior
-	DLecAct  float64 `xml:"dLecAct,omitempty"`  // Lectura actual
-	DConKwh  float64 `xml:"dConKwh,omitempty"`  // Consumo en kWh
+	DNroMed  string         `xml:"dNroMed"`            // Número de medidor
+	DActEner int32          `xml:"dActEner,omitempty"` // Código de actividad
+	DCatEner string         `xml:"dCatEner,omitempty"` // Categoría del servicio
+	DLecAnt  *types.Decimal `xml:"dLecAnt,omitempty"`  // Lectura anterior
+	DLecAct  *types.Decimal `xml:"dLecAct,omitempty"`  // Lectura actual
+	DConKwh  *types.Decimal `xml:"dConKwh,omitempty"`  // Consumo en kWh
 }
 
 // TgGrupSeg: Sector Seguros (E820-E829)
@@ -190,72 +184,84 @@ type TgPoliza struct {
 
 // TgGrupSup: Sector Supermercados (E830-E839)
 type TgGrupSup struct {
-	DNomCaj   string  `xml:"dNomCaj,omitempty"`   // Nombre del cajero
-	DEfecivo  float64 `xml:"dEfectivo,omitempty"` // Monto efectivo recibido
-	DVuelto   float64 `xml:"dVuelto,omitempty"`   // Monto del vuelto
-	DDonac    float64 `xml:"dDonac,omitempty"`    // Monto de donación
-	DDesDonac string  `xml:"dDesDonac,omitempty"` // Descripción de la donación
+	DNomCaj   string         `xml:"dNomCaj,omitempty"`   // Nombre del cajero
+	DEfecivo  *types.Decimal `xml:"dEfectivo,omitempty"` // Monto efectivo recibido
+	DVuelto   *types.Decimal `xml:"dVuelto,omitempty"`   // Monto del vuelto
+	DDonac    *types.Decimal `xml:"dDonac,omitempty"`    // Monto de donación
+	DDesDonac string         `xml:"dDesDonac,omitempty"` // Descripción de la donación
 }
 
 // TgGrupAdi: Grupo de Datos Adicionales (E840-E899)
 type TgGrupAdi struct {
-	DCiclo    string  `xml:"dCiclo,omitempty"`    // Ciclo facturado
-	DFecIniC  string  `xml:"dFecIniC,omitempty"`  // Fecha inicio del ciclo
-	DFecFinC  string  `xml:"dFecFinC,omitempty"`  // Fecha fin del ciclo
-	DVencPag  string  `xml:"dVencPag,omitempty"`  // Fecha de vencimiento para pago
-	DContrato string  `xml:"dContrato,omitempty"` // Número de contrato
-	DSalAnt   float64 `xml:"dSalAnt,omitempty"`   // Saldo anterior
+	DCiclo    string         `xml:"dCiclo,omitempty"`    // Ciclo facturado
+	DFecIniC  string         `xml:"dFecIniC,omitempty"`  // Fecha inicio del ciclo
+	DFecFinC  string         `xml:"dFecFinC,omitempty"`  // Fecha fin del ciclo
+	DVencPag  string         `xml:"dVencPag,omitempty"`  // Fecha de vencimiento para pago
+	DContrato string         `xml:"dContrato,omitempty"` // Número de contrato
+	DSalAnt   *types.Decimal `xml:"dSalAnt,omitempty"`   // Saldo anterior
 }
 
 // ============================================================================
 // TgTransp: Campos de Transporte (E900-E999)
 // ============================================================================
 type TgTransp struct {
-	ITipTrans     types.TiTipoTransporte      `xml:"iTipTrans"`               // Tipo de transporte
-	DDesTipTrans  string                      `xml:"dDesTipTrans"`            // Descripción tipo transporte
-	IModTrans     types.TiModalidadTransporte `xml:"iModTrans"`               // Modalidad de transporte
-	DDesModTrans  string                      `xml:"dDesModTrans"`            // Descripción modalidad
-	IRepFlete     types.TiRespFlete           `xml:"iTipRep"`                 // Responsable del flete
-	DDesRepFlete  string                      `xml:"dDesTipRep"`              // Descripción responsable
-	DCodNegoci    string                      `xml:"dCondNeg,omitempty"`      // Condición de negociación
-	DNuManworking string                      `xml:"dNuManworking,omitempty"` // Número de manifiesto
-	DNumDesDI     string                      `xml:"dNuDespImp,omitempty"`    // Número de despacho importación
-	DInIniTras    string                      `xml:"dIniTras,omitempty"`      // Fecha inicio traslado (yyyy-MM-ddT00:00:00)
-	DFesFiworking string                      `xml:"dFinTras,omitempty"`      // Fecha fin estimada traslado
-	CPaisDes      types.PaisType              `xml:"cPaisDest,omitempty"`     // País de destino
-	DDesPaisDes   string                      `xml:"dDesPaisDest,omitempty"`  // Descripción país destino
+	ITipTrans    types.TiTipoTransporte      `xml:"iTipTrans"`              // Tipo de transporte
+	DDesTipTrans string                      `xml:"dDesTipTrans"`           // Descripción tipo transporte
+	IModTrans    types.TiModalidadTransporte `xml:"iModTrans"`              // Modalidad de transporte
+	DDesModTrans string                      `xml:"dDesModTrans"`           // Descripción modalidad
+	IRepFlete    types.TiRespFlete           `xml:"iRespFlete"`             // Responsable del flete
+	DCodNegoci   string                      `xml:"cCondNeg,omitempty"`     // Condición de negociación (Incoterms)
+	DNuManif     string                      `xml:"dNuManif,omitempty"`     // Número de manifiesto
+	DNumDesDI    string                      `xml:"dNuDespImp,omitempty"`   // Número de despacho importación
+	DInIniTras   string                      `xml:"dIniTras,omitempty"`     // Fecha inicio traslado (yyyy-MM-dd)
+	DFinTras     string                      `xml:"dFinTras,omitempty"`     // Fecha fin estimada traslado (yyyy-MM-dd)
+	CPaisDes     types.PaisType              `xml:"cPaisDest,omitempty"`    // País de destino
+	DDesPaisDes  string                      `xml:"dDesPaisDest,omitempty"` // Descripción país destino
 
 	// Lugares de salida y entrega
-	GSalida  *TgDirSaliEnt `xml:"gCamSal,omitempty"` // Datos de salida
-	GEntrega *TgDirSaliEnt `xml:"gCamEnt,omitempty"` // Datos de entrega
+	GSalida  *TgCamSal `xml:"gCamSal,omitempty"` // Datos de salida
+	GEntrega *TgCamEnt `xml:"gCamEnt,omitempty"` // Datos de entrega
 
 	// Vehículo y transportista
 	GVehiculo      *TgVehiculo      `xml:"gVehTras,omitempty"`  // Datos del vehículo
 	GTransportista *TgTransportista `xml:"gCamTrans,omitempty"` // Datos del transportista
 }
 
-// TgDirSaliEnt: Dirección de Salida/Entrega (E920-E939)
-type TgDirSaliEnt struct {
-	DDirLoc   string              `xml:"dDirLocSal"`            // Dirección de salida/entrega
-	DNumCas   string              `xml:"dNumCasSal,omitempty"`  // Número de casa
-	DCompDir1 string              `xml:"dComp1Sal,omitempty"`   // Complemento dirección 1
-	DCompDir2 string              `xml:"dComp2Sal,omitempty"`   // Complemento dirección 2
-	CDep      types.TDepartamento `xml:"cDepSal,omitempty"`     // Código departamento
-	DDesDep   string              `xml:"dDesDepSal,omitempty"`  // Descripción departamento
-	CDis      int16               `xml:"cDisSal,omitempty"`     // Código distrito
-	DDesDis   string              `xml:"dDesDisSal,omitempty"`  // Descripción distrito
-	CCiu      int32               `xml:"cCiuSal,omitempty"`     // Código ciudad
-	DDesCiu   string              `xml:"dDesCiuSal,omitempty"`  // Descripción ciudad
-	CPais     types.PaisType      `xml:"cPaisSal,omitempty"`    // País
-	DDesPais  string              `xml:"dDesPaisSal,omitempty"` // Descripción país
-	DTelCont  string              `xml:"dTelSal,omitempty"`     // Teléfono de contacto
+// TgCamSal: Local de Salida de la Mercadería (E920-E929)
+type TgCamSal struct {
+	DDirLoc   string              `xml:"dDirLocSal"`           // Dirección del local de salida
+	DNumCas   string              `xml:"dNumCasSal"`           // Número de casa
+	DCompDir1 string              `xml:"dComp1Sal,omitempty"`  // Complemento dirección 1
+	DCompDir2 string              `xml:"dComp2Sal,omitempty"`  // Complemento dirección 2
+	CDep      types.TDepartamento `xml:"cDepSal"`              // Código departamento
+	DDesDep   string              `xml:"dDesDepSal"`           // Descripción departamento
+	CDis      int16               `xml:"cDisSal,omitempty"`    // Código distrito
+	DDesDis   string              `xml:"dDesDisSal,omitempty"` // Descripción distrito
+	CCiu      int32               `xml:"cCiuSal"`              // Código ciudad
+	DDesCiu   string              `xml:"dDesCiuSal"`           // Descripción ciudad
+	DTelCont  string              `xml:"dTelSal,omitempty"`    // Teléfono de contacto
+}
+
+// TgCamEnt: Local de Entrega de la Mercadería (E930-E939)
+type TgCamEnt struct {
+	DDirLoc   string              `xml:"dDirLocEnt"`           // Dirección del local de entrega
+	DNumCas   string              `xml:"dNumCasEnt"`           // Número de casa
+	DCompDir1 string              `xml:"dComp1Ent,omitempty"`  // Complemento dirección 1
+	DCompDir2 string              `xml:"dComp2Ent,omitempty"`  // Complemento dirección 2
+	CDep      types.TDepartamento `xml:"cDepEnt"`              // Código departamento
+	DDesDep   string              `xml:"dDesDepEnt"`           // Descripción departamento
+	CDis      int16               `xml:"cDisEnt,omitempty"`    // Código distrito
+	DDesDis   string              `xml:"dDesDisEnt,omitempty"` // Descripción distrito
+	CCiu      int32               `xml:"cCiuEnt"`              // Código ciudad
+	DDesCiu   string              `xml:"dDesCiuEnt"`           // Descripción ciudad
+	DTelCont  string              `xml:"dTelEnt,omitempty"`    // Teléfono de contacto
 }
 
 // TgVehiculo: Datos del Vehículo (E940-E959)
 type TgVehiculo struct {
-	DTipVeh   string `xml:"dTipVeh"`              // Tipo de vehículo
-	DMarca    string `xml:"dMarVeh,omitempty"`    // Marca del vehículo
-	DTipIdent int16  `xml:"dTipIdeVeh,omitempty"` // Tipo de identificación vehículo
+	DTipVeh   string `xml:"dTiVehTras"`           // Tipo de vehículo
+	DMarca    string `xml:"dMarVeh"`              // Marca del vehículo
+	DTipIdent int16  `xml:"dTipIdenVeh"`          // Tipo de identificación vehículo (1=número de identificación, 2=matrícula)
 	DNumIdent string `xml:"dNroIDVeh,omitempty"`  // Número de identificación
 	DAdicVeh  string `xml:"dAdicVeh,omitempty"`   // Información adicional del vehículo
 	DNumMat   string `xml:"dNroMatVeh,omitempty"` // Número de matrícula
@@ -273,27 +279,17 @@ type TgTransportista struct {
 	DNumIdTrans    string           `xml:"dNumIDTrans,omitempty"`  // Número de documento
 	CPaisTrans     types.PaisType   `xml:"cNacTrans,omitempty"`    // País del transportista
 	DDesPaisTrans  string           `xml:"dDesNacTrans,omitempty"` // Descripción país
-	DDirTrans      string           `xml:"dDirTrans,omitempty"`    // Dirección del transportista
 
 	// Datos del chofer
-	DChofer *TgChofer `xml:"gCamChof,omitempty"` // Datos del chofer
+	DNumIDChof string `xml:"dNumIDChof"`           // Número de documento del chofer
+	DNomChof   string `xml:"dNomChof"`             // Nombre del chofer
+	DDomFisc   string `xml:"dDomFisc,omitempty"`   // Domicilio fiscal del transportista
+	DDirChofer string `xml:"dDirChofer,omitempty"` // Dirección del chofer
 	// Datos del agente
-	DAgente *TgAgente `xml:"gCamAgente,omitempty"` // Datos del agente
-}
-
-// TgChofer: Datos del Chofer (E980-E989)
-type TgChofer struct {
-	DNomChofer   string `xml:"dNomChof"`             // Nombre del chofer
-	DNumIdChofer string `xml:"dNumIDChof,omitempty"` // Número de documento del chofer
-	DDirChofer   string `xml:"dDirChof,omitempty"`   // Dirección del chofer
-}
-
-// TgAgente: Datos del Agente (E990-E999)
-type TgAgente struct {
-	DNomAgente string `xml:"dNomAg,omitempty"` // Nombre del agente
-	DRucAgente string `xml:"dRucAg,omitempty"` // RUC del agente
-	DDVAgente  int16  `xml:"dDVAg,omitempty"`  // Dígito verificador
-	DDirAgente string `xml:"dDirAg,omitempty"` // Dirección del agente
+	DNombAg string `xml:"dNombAg,omitempty"` // Nombre del agente
+	DRucAg  string `xml:"dRucAg,omitempty"`  // RUC del agente
+	DDVAg   int16  `xml:"dDVAg,omitempty"`   // Dígito verificador
+	DDirAge string `xml:"dDirAge,omitempty"` // Dirección del agente
 }
 
 // ============================================================================
@@ -307,7 +303,7 @@ type TgOpeCom struct {
 	CMoneOpe    types.CMondT   `xml:"cMoneOpe"`              // Moneda de la operación
 	DDesMoneOpe string         `xml:"dDesMoneOpe"`           // Descripción moneda
 	DCondTiCam  *int16         `xml:"dCondTiCam,omitempty"`  // Condición tipo de cambio
-	DTiCam      *float64       `xml:"dTiCam,omitempty"`      // Tipo de cambio
+	DTiCam      *types.Decimal `xml:"dTiCam,omitempty"`      // Tipo de cambio
 	ICondAnt    *int16         `xml:"iCondAnt,omitempty"`    // Condición del anticipo
 	DDesCondAnt string         `xml:"dDesCondAnt,omitempty"` // Descripción condición anticipo
 }
@@ -399,14 +395,14 @@ type TgCamItem struct {
 	DDesProSer   string          `xml:"dDesProSer"`             // Descripción del producto/servicio
 	CUniMed      types.TcUniMed  `xml:"cUniMed"`                // Código unidad de medida
 	DDesUniMed   string          `xml:"dDesUniMed"`             // Descripción unidad de medida
-	DCantProSer  float64         `xml:"dCantProSer"`            // Cantidad del producto/servicio
+	DCantProSer  types.Decimal   `xml:"dCantProSer"`            // Cantidad del producto/servicio
 	CPaisOrig    *types.PaisType `xml:"cPaisOrig,omitempty"`    // País de origen
 	DDesPaisOrig string          `xml:"dDesPaisOrig,omitempty"` // Descripción país de origen
 	DInfItem     string          `xml:"dInfItem,omitempty"`     // Info adicional del item
 	CRelMerc     *int16          `xml:"cRelMerc,omitempty"`     // Relevancia de la mercadería
 	DDesRelMerc  string          `xml:"dDesRelMerc,omitempty"`  // Descripción relevancia
-	DCanQuiMer   *float64        `xml:"dCanQuiMer,omitempty"`   // Cantidad que acepta
-	DPorQuiMer   *float64        `xml:"dPorQuiMer,omitempty"`   // Porcentaje tolerancia merma
+	DCanQuiMer   *types.Decimal  `xml:"dCanQuiMer,omitempty"`   // Cantidad que acepta
+	DPorQuiMer   *types.Decimal  `xml:"dPorQuiMer,omitempty"`   // Porcentaje tolerancia merma
 	DCDCAnticipo string          `xml:"dCDCAnticipo,omitempty"` // CDC del anticipo
 
 	GValorItem TgValorItem `xml:"gValorItem"`          // Valores del item
@@ -417,107 +413,105 @@ type TgCamItem struct {
 
 // TgValorItem: Valores del Item (E720-E729)
 type TgValorItem struct {
-	DPUniProSer     float64          `xml:"dPUniProSer"`        // Precio unitario
-	DTiCamIt        *float64         `xml:"dTiCamIt,omitempty"` // Tipo de cambio por item
-	DTotBruOpeItem  float64          `xml:"dTotBruOpeItem"`     // Total bruto de la operación
+	DPUniProSer     types.Decimal    `xml:"dPUniProSer"`        // Precio unitario
+	DTiCamIt        *types.Decimal   `xml:"dTiCamIt,omitempty"` // Tipo de cambio por item
+	DTotBruOpeItem  types.Decimal    `xml:"dTotBruOpeItem"`     // Total bruto de la operación
 	GValorRestaItem TgValorRestaItem `xml:"gValorRestaItem"`    // Valores restantes
 }
 
 // TgValorRestaItem: Descuentos y Anticipos del Item (EA001-EA050)
 type TgValorRestaItem struct {
-	DDescItem       *float64 `xml:"dDescItem,omitempty"`       // Descuento particular del item
-	DPorcDesIt      *float64 `xml:"dPorcDesIt,omitempty"`      // Porcentaje de descuento
-	DDescGloItem    *float64 `xml:"dDescGloItem,omitempty"`    // Descuento global del item
-	DAntPreUniIt    *float64 `xml:"dAntPreUniIt,omitempty"`    // Anticipo particular del item
-	DAntGloPreUniIt *float64 `xml:"dAntGloPreUniIt,omitempty"` // Anticipo global del item
-	DTotOpeItem     float64  `xml:"dTotOpeItem"`               // Total de la operación
-	DTotOpeGs       *float64 `xml:"dTotOpeGs,omitempty"`       // Total operación en Guaraníes
+	DDescItem       *types.Decimal `xml:"dDescItem,omitempty"`       // Descuento particular del item
+	DPorcDesIt      *types.Decimal `xml:"dPorcDesIt,omitempty"`      // Porcentaje de descuento
+	DDescGloItem    *types.Decimal `xml:"dDescGloItem,omitempty"`    // Descuento global del item
+	DAntPreUniIt    *types.Decimal `xml:"dAntPreUniIt,omitempty"`    // Anticipo particular del item
+	DAntGloPreUniIt *types.Decimal `xml:"dAntGloPreUniIt,omitempty"` // Anticipo global del item
+	DTotOpeItem     types.Decimal  `xml:"dTotOpeItem"`               // Total de la operación
+	DTotOpeGs       *types.Decimal `xml:"dTotOpeGs,omitempty"`       // Total operación en Guaraníes
 }
 
 // TgCamIVA: Campos del IVA por Item (E730-E739)
 type TgCamIVA struct {
 	IAfecIVA    types.TiAfecIVA `xml:"iAfecIVA"`          // Afectación tributaria IVA
 	DDesAfecIVA string          `xml:"dDesAfecIVA"`       // Descripción afectación
-	DPropIVA    float64         `xml:"dPropIVA"`          // Proporción gravada de IVA
-	DTasaIVA    float64         `xml:"dTasaIVA"`          // Tasa del IVA
-	DBasGravIVA float64         `xml:"dBasGravIVA"`       // Base gravada del IVA
-	DLiqIVAItem float64         `xml:"dLiqIVAItem"`       // Liquidación del IVA
-	DBasExe     *float64        `xml:"dBasExe,omitempty"` // Base exenta
+	DPropIVA    types.Decimal   `xml:"dPropIVA"`          // Proporción gravada de IVA
+	DTasaIVA    types.Decimal   `xml:"dTasaIVA"`          // Tasa del IVA
+	DBasGravIVA types.Decimal   `xml:"dBasGravIVA"`       // Base gravada del IVA
+	DLiqIVAItem types.Decimal   `xml:"dLiqIVAItem"`       // Liquidación del IVA
+	DBasExe     *types.Decimal  `xml:"dBasExe,omitempty"` // Base exenta
 }
 
 // ============================================================================
 // TgRasMerc: Rastreo de Mercadería (E750-E760)
 // ============================================================================
 type TgRasMerc struct {
-	DNLote    string `xml:"dNLote,omitempty"`    // Número de lote
+	DNLote    string `xml:"dNumLote,omitempty"`  // Número de lote
 	DVencMerc string `xml:"dVencMerc,omitempty"` // Fecha de vencimiento (yyyy-MM-dd)
 	DNSerie   string `xml:"dNSerie,omitempty"`   // Número de serie
-	DNPedido  string `xml:"dNPedido,omitempty"`  // Número de pedido
-	DNSeguim  string `xml:"dNSeguim,omitempty"`  // Número de seguimiento
+	DNPedido  string `xml:"dNumPedi,omitempty"`  // Número de pedido
+	DNSeguim  string `xml:"dNumSegui,omitempty"` // Número de seguimiento
 	// Campos del importador
-	GCamImp     *TgCamImp `xml:"dInfoImport,omitempty"` // Datos del importador
-	DRegistroS  string    `xml:"dNRegSenave,omitempty"` // Registro SENAVE
-	DRegistroEn string    `xml:"dNRegEntCom,omitempty"` // Registro entidad comercial
-}
-
-// TgCamImp: Datos del Importador
-type TgCamImp struct {
-	DNomImp  string `xml:"dNomImp,omitempty"` // Nombre del importador
-	DDirImp  string `xml:"dDirImp,omitempty"` // Dirección del importador
-	DNRegImp string `xml:"dNumReg,omitempty"` // Número de registro
+	DNomImp string `xml:"dNomImp,omitempty"` // Nombre del importador
+	DDirImp string `xml:"dDirImp,omitempty"` // Dirección del importador
+	DNumFir string `xml:"dNumFir,omitempty"` // Número de registro de la firma del importador
+	DNumPer string `xml:"dNumPer,omitempty"` // Número de registro del permiso de importación
+	DNumAut string `xml:"dNumAut,omitempty"` // Número de autorización de importación
+	// Registros sanitarios
+	DRegistroS  string `xml:"dNumRegSenave,omitempty"` // Registro SENAVE
+	DRegistroEn string `xml:"dNumRegEntCom,omitempty"` // Registro entidad comercial
 }
 
 // ============================================================================
 // TgVehNuevo: Sector de Vehículos Nuevos/Usados (E770-E789)
 // ============================================================================
 type TgVehNuevo struct {
-	ITipOpVN    int16                   `xml:"iTipOpVN"`                 // Tipo de operación de vehículo
-	DDesTipOpVN string                  `xml:"dDesTipOpVN"`              // Descripción tipo operación
-	DChasis     string                  `xml:"dChworking,omitempty"`     // Número de chasis
-	DColor      string                  `xml:"dColor,omitempty"`         // Color del vehículo
-	DPotWorking int32                   `xml:"dPotVeh,omitempty"`        // Potencia del motor (HP)
-	DCapMot     int32                   `xml:"dCapMot,omitempty"`        // Capacidad del motor (CC)
-	DPNet       float64                 `xml:"dPNet,omitempty"`          // Peso neto
-	DPBrut      float64                 `xml:"dPBrut,omitempty"`         // Peso bruto
-	ITipCom     types.TiTipoCombustible `xml:"iTipComb,omitempty"`       // Tipo de combustible
-	DDesTipCom  string                  `xml:"dDesTipComb,omitempty"`    // Descripción tipo combustible
-	DNMotor     string                  `xml:"dNroMotor,omitempty"`      // Número de motor
-	DCapTracc   float64                 `xml:"dCapTraworking,omitempty"` // Capacidad de tracción
-	DAnoFab     int16                   `xml:"dAnoFab,omitempty"`        // Año de fabricación
-	DTipVeh     string                  `xml:"dTipVeh,omitempty"`        // Tipo de vehículo
-	DCap        int16                   `xml:"dCap,omitempty"`           // Capacidad (pasajeros)
-	DCil        string                  `xml:"dCil,omitempty"`           // Cilindrada
+	ITipOpVN    int16                   `xml:"iTipOpVN"`             // Tipo de operación de vehículo
+	DDesTipOpVN string                  `xml:"dDesTipOpVN"`          // Descripción tipo operación
+	DChasis     string                  `xml:"dChasis,omitempty"`    // Número de chasis
+	DColor      string                  `xml:"dColor,omitempty"`     // Color del vehículo
+	DPotencia   int32                   `xml:"dPotencia,omitempty"`  // Potencia del motor (HP)
+	DCapMot     int32                   `xml:"dCapMot,omitempty"`    // Capacidad del motor (CC)
+	DPNet       *types.Decimal          `xml:"dPNet,omitempty"`      // Peso neto
+	DPBrut      *types.Decimal          `xml:"dPBruto,omitempty"`    // Peso bruto
+	ITipCom     types.TiTipoCombustible `xml:"iTipCom,omitempty"`    // Tipo de combustible
+	DDesTipCom  string                  `xml:"dDesTipCom,omitempty"` // Descripción tipo combustible
+	DNMotor     string                  `xml:"dNroMotor,omitempty"`  // Número de motor
+	DCapTracc   *types.Decimal          `xml:"dCapTracc,omitempty"`  // Capacidad de tracción
+	DAnoFab     int16                   `xml:"dAnoFab,omitempty"`    // Año de fabricación
+	DTipVeh     string                  `xml:"cTipVeh,omitempty"`    // Tipo de vehículo
+	DCap        int16                   `xml:"dCapac,omitempty"`     // Capacidad (pasajeros)
+	DCil        string                  `xml:"dCilin,omitempty"`     // Cilindrada
 }
 
 // ============================================================================
 // TgTotSub: Totales del Documento (F001-F099)
 // ============================================================================
 type TgTotSub struct {
-	DSubExe        float64  `xml:"dSubExe"`                // Subtotal exentas
-	DSubExo        float64  `xml:"dSubExo"`                // Subtotal exoneradas
-	DSub5          float64  `xml:"dSub5,omitempty"`        // Subtotal 5%
-	DSub10         float64  `xml:"dSub10,omitempty"`       // Subtotal 10%
-	DTotOpe        float64  `xml:"dTotOpe"`                // Total bruto de la operación
-	DTotDesc       float64  `xml:"dTotDesc"`               // Total de descuentos
-	DTotDescGlotem float64  `xml:"dTotDescGlotem"`         // Total descuento global
-	DTotAntItem    float64  `xml:"dTotAntItem"`            // Total anticipo por item
-	DTotAnt        float64  `xml:"dTotAnt"`                // Total de anticipos
-	DPorcDescTotal float64  `xml:"dPorcDescTotal"`         // Porcentaje descuento total
-	DDescTotal     float64  `xml:"dDescTotal"`             // Descuento total
-	DAnticipo      float64  `xml:"dAnticipo"`              // Anticipo
-	DRedon         float64  `xml:"dRedon"`                 // Redondeo
-	DComi          *float64 `xml:"dComi,omitempty"`        // Comisión
-	DTotGralOpe    float64  `xml:"dTotGralOpe"`            // Total general de la operación
-	DIVA5          float64  `xml:"dIVA5,omitempty"`        // IVA 5%
-	DIVA10         float64  `xml:"dIVA10,omitempty"`       // IVA 10%
-	DLiqTotIVA5    float64  `xml:"dLiqTotIVA5,omitempty"`  // Liquidación total IVA 5%
-	DLiqTotIVA10   float64  `xml:"dLiqTotIVA10,omitempty"` // Liquidación total IVA 10%
-	DIVAComi       *float64 `xml:"dIVAComi,omitempty"`     // IVA de la comisión
-	DTotIVA        float64  `xml:"dTotIVA,omitempty"`      // Total IVA
-	DBaseGrav5     float64  `xml:"dBaseGrav5,omitempty"`   // Base gravada 5%
-	DBaseGrav10    float64  `xml:"dBaseGrav10,omitempty"`  // Base gravada 10%
-	DTBasGraIVA    float64  `xml:"dTBasGraIVA,omitempty"`  // Total base gravada IVA
-	DTotalGs       *float64 `xml:"dTotalGs,omitempty"`     // Total en Guaraníes
+	DSubExe        types.Decimal  `xml:"dSubExe"`                // Subtotal exentas
+	DSubExo        types.Decimal  `xml:"dSubExo"`                // Subtotal exoneradas
+	DSub5          *types.Decimal `xml:"dSub5,omitempty"`        // Subtotal 5%
+	DSub10         *types.Decimal `xml:"dSub10,omitempty"`       // Subtotal 10%
+	DTotOpe        types.Decimal  `xml:"dTotOpe"`                // Total bruto de la operación
+	DTotDesc       types.Decimal  `xml:"dTotDesc"`               // Total de descuentos
+	DTotDescGlotem types.Decimal  `xml:"dTotDescGlotem"`         // Total descuento global
+	DTotAntItem    types.Decimal  `xml:"dTotAntItem"`            // Total anticipo por item
+	DTotAnt        types.Decimal  `xml:"dTotAnt"`                // Total de anticipos
+	DPorcDescTotal types.Decimal  `xml:"dPorcDescTotal"`         // Porcentaje descuento total
+	DDescTotal     types.Decimal  `xml:"dDescTotal"`             // Descuento total
+	DAnticipo      types.Decimal  `xml:"dAnticipo"`              // Anticipo
+	DRedon         types.Decimal  `xml:"dRedon"`                 // Redondeo
+	DComi          *types.Decimal `xml:"dComi,omitempty"`        // Comisión
+	DTotGralOpe    types.Decimal  `xml:"dTotGralOpe"`            // Total general de la operación
+	DIVA5          *types.Decimal `xml:"dIVA5,omitempty"`        // IVA 5%
+	DIVA10         *types.Decimal `xml:"dIVA10,omitempty"`       // IVA 10%
+	DLiqTotIVA5    *types.Decimal `xml:"dLiqTotIVA5,omitempty"`  // IVA 5% del redondeo
+	DLiqTotIVA10   *types.Decimal `xml:"dLiqTotIVA10,omitempty"` // IVA 10% del redondeo
+	DIVAComi       *types.Decimal `xml:"dIVAComi,omitempty"`     // IVA de la comisión
+	DTotIVA        *types.Decimal `xml:"dTotIVA,omitempty"`      // Total IVA
+	DBaseGrav5     *types.Decimal `xml:"dBaseGrav5,omitempty"`   // Base gravada 5%
+	DBaseGrav10    *types.Decimal `xml:"dBaseGrav10,omitempty"`  // Base gravada 10%
+	DTBasGraIVA    *types.Decimal `xml:"dTBasGraIVA,omitempty"`  // Total base gravada IVA
+	DTotalGs       *types.Decimal `xml:"dTotalGs,omitempty"`     // Total en Guaraníes
 }
 
 // ============================================================================
@@ -0,0 +1,212 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/rodascaar/sifen-go-py/sifen/types"
+)
+
+// ============================================================================
+// Normalización de descripciones (dDes*)
+// ============================================================================
+
+// UnknownCode es un código del DE sin descripción conocida en sifen/types
+// cuyo campo de descripción quedó vacío
+type UnknownCode struct {
+	Field string // Ruta del campo del código, por ejemplo DE.GTimb.ITiDE
+	Code  string
+}
+
+func (u UnknownCode) Error() string {
+	return fmt.Sprintf("%s: código %s sin descripción conocida", u.Field, u.Code)
+}
+
+// Normalize completa cada campo dDes* a partir de su código con el texto
+// oficial de sifen/types, reemplazando el que tuviera. Si el código no es
+// conocido se conserva la descripción cargada; los que quedan sin descripción
+// se retornan.
+func (de *DocumentoElectronico) Normalize() []UnknownCode {
+	n := &normalizer{}
+	d := &de.DE
+
+	describe(n, "DE.GOpeDE.ITipEmi", d.GOpeDE.ITipEmi, &d.GOpeDE.DDesTipEmi)
+	describe(n, "DE.GTimb.ITiDE", d.GTimb.ITiDE, &d.GTimb.DDesTiDE)
+
+	gral := &d.GDatGralOpe
+	if ope := gral.GOpeCom; ope != nil {
+		if ope.ITipTra != nil {
+			describe(n, "DE.GDatGralOpe.GOpeCom.ITipTra", *ope.ITipTra, &ope.DDesTipTra)
+		}
+		describe(n, "DE.GDatGralOpe.GOpeCom.ITImp", ope.ITImp, &ope.DDesTImp)
+		n.moneda("DE.GDatGralOpe.GOpeCom.CMoneOpe", ope.CMoneOpe, &ope.DDesMoneOpe)
+	}
+
+	emis := &gral.GEmis
+	describe(n, "DE.GDatGralOpe.GEmis.CDepEmi", emis.CDepEmi, &emis.DDesDepEmi)
+	if resp := emis.GRespDE; resp != nil {
+		describe(n, "DE.GDatGralOpe.GEmis.GRespDE.ITipIDRespDE", types.TTipDocRec(resp.ITipIDRespDE), &resp.DDTipIDRespDE)
+	}
+
+	rec := &gral.GDatRec
+	n.pais("DE.GDatGralOpe.GDatRec.CPaisRec", rec.CPaisRec, &rec.DDesPaisRe)
+	if rec.ITipIDRec != nil {
+		describe(n, "DE.GDatGralOpe.GDatRec.ITipIDRec", types.TTipDocRec(*rec.ITipIDRec), &rec.DDTipIDRec)
+	}
+	if rec.CDepRec != nil {
+		describe(n, "DE.GDatGralOpe.GDatRec.CDepRec", *rec.CDepRec, &rec.DDesDepRec)
+	}
+
+	n.dtipDE("DE.GDtipDE", &d.GDtipDE)
+
+	if gen := d.GCamGen; gen != nil && gen.GCamCarg != nil {
+		n.carga("DE.GCamGen.GCamCarg", gen.GCamCarg)
+	}
+
+	for i := range d.GCamDEAsoc {
+		aso := &d.GCamDEAsoc[i]
+		path := fmt.Sprintf("DE.GCamDEAsoc[%d]", i)
+		describe(n, path+".ITipDocAso", aso.ITipDocAso, &aso.DDesTipDocAso)
+		if aso.ITipoDocAso != nil {
+			describe(n, path+".ITipoDocAso", *aso.ITipoDocAso, &aso.DDTipoDocAso)
+		}
+		if aso.ITipCons != nil {
+			describe(n, path+".ITipCons", *aso.ITipCons, &aso.DDesTipCons)
+		}
+	}
+
+	return n.unknown
+}
+
+type normalizer struct {
+	unknown []UnknownCode
+}
+
+// describe fija desc si el código es conocido y registra los códigos
+// desconocidos sin descripción
+func describe[T types.Codigo](n *normalizer, field string, code T, desc *string) {
+	text, ok := types.Descripcion(code)
+	n.set(field, strconv.Itoa(int(code)), text, ok, desc)
+}
+
+func (n *normalizer) set(field, code, text string, ok bool, desc *string) {
+	if ok {
+		*desc = text
+		return
+	}
+	if *desc == "" {
+		n.unknown = append(n.unknown, UnknownCode{Field: field, Code: code})
+	}
+}
+
+func (n *normalizer) moneda(field string, code types.CMondT, desc *string) {
+	text := code.Descripcion()
+	n.set(field, string(code), text, text != string(code), desc)
+}
+
+func (n *normalizer) pais(field string, code types.PaisType, desc *string) {
+	text := code.Nombre()
+	n.set(field, string(code), text, text != string(code), desc)
+}
+
+// unidad usa la abreviatura de la unidad de medida, que es lo que SIFEN
+// espera en dDesUniMed
+func (n *normalizer) unidad(field string, code types.TcUniMed, desc *string) {
+	_, ok := types.Descripcion(code)
+	n.set(field, strconv.Itoa(int(code)), code.Abreviatura(), ok, desc)
+}
+
+func (n *normalizer) dtipDE(path string, dtip *TgDtipDE) {
+	if fe := dtip.GCamFE; fe != nil {
+		describe(n, path+".GCamFE.IIndPres", fe.IIndPres, &fe.DDesIndPres)
+	}
+	if ae := dtip.GCamAE; ae != nil {
+		describe(n, path+".GCamAE.INatVen", ae.INatVen, &ae.DDesNatVen)
+		describe(n, path+".GCamAE.ITipIDVen", ae.ITipIDVen, &ae.DDesTipIDVen)
+		describe(n, path+".GCamAE.CDepVen", ae.CDepVen, &ae.DDesDepVen)
+		describe(n, path+".GCamAE.CDepProv", ae.CDepProv, &ae.DDesDepProv)
+	}
+	if nc := dtip.GCamNCDE; nc != nil {
+		describe(n, path+".GCamNCDE.IMotEmi", nc.IMotEmi, &nc.DDesMotEmi)
+	}
+	if nr := dtip.GCamNRE; nr != nil {
+		describe(n, path+".GCamNRE.IMotEmiNR", nr.IMotEmiNR, &nr.DDesMotEmiNR)
+		describe(n, path+".GCamNRE.IRespEmiNR", nr.IRespEmiNR, &nr.DDesRespEmiNR)
+	}
+
+	if cond := dtip.GCamCond; cond != nil {
+		describe(n, path+".GCamCond.ICondOpe", cond.ICondOpe, &cond.DDesCondOpe)
+		for i := range cond.GPaConEIni {
+			pago := &cond.GPaConEIni[i]
+			p := fmt.Sprintf("%s.GCamCond.GPaConEIni[%d]", path, i)
+			describe(n, p+".ITiPago", pago.ITiPago, &pago.DDesTiPago)
+			if pago.CMoneOpe != "" {
+				n.moneda(p+".CMoneOpe", pago.CMoneOpe, &pago.DDesMoneOpe)
+			}
+		}
+		if cred := cond.GCredCond; cred != nil {
+			describe(n, path+".GCamCond.GCredCond.ICondCred", cred.ICondCred, &cred.DDesCondCred)
+			for i := range cred.GCuotas {
+				cuota := &cred.GCuotas[i]
+				if cuota.CMoneOpe != "" {
+					n.moneda(fmt.Sprintf("%s.GCamCond.GCredCond.GCuotas[%d].CMoneOpe", path, i), cuota.CMoneOpe, &cuota.DDesMoneCuo)
+				}
+			}
+		}
+	}
+
+	for i := range dtip.GCamItemList {
+		item := &dtip.GCamItemList[i]
+		p := fmt.Sprintf("%s.GCamItemList[%d]", path, i)
+		n.unidad(p+".CUniMed", item.CUniMed, &item.DDesUniMed)
+		if item.CPaisOrig != nil {
+			n.pais(p+".CPaisOrig", *item.CPaisOrig, &item.DDesPaisOrig)
+		}
+		if iva := item.GCamIVA; iva != nil {
+			describe(n, p+".GCamIVA.IAfecIVA", iva.IAfecIVA, &iva.DDesAfecIVA)
+		}
+		if veh := item.GVehNuevo; veh != nil && veh.ITipCom != 0 {
+			describe(n, p+".GVehNuevo.ITipCom", veh.ITipCom, &veh.DDesTipCom)
+		}
+	}
+
+	if tr := dtip.GTransp; tr != nil {
+		n.transporte(path+".GTransp", tr)
+	}
+}
+
+func (n *normalizer) transporte(path string, tr *TgTransp) {
+	describe(n, path+".ITipTrans", tr.ITipTrans, &tr.DDesTipTrans)
+	describe(n, path+".IModTrans", tr.IModTrans, &tr.DDesModTrans)
+	if tr.CPaisDes != "" {
+		n.pais(path+".CPaisDes", tr.CPaisDes, &tr.DDesPaisDes)
+	}
+
+	if sal := tr.GSalida; sal != nil {
+		describe(n, path+".GSalida.CDep", sal.CDep, &sal.DDesDep)
+	}
+	if ent := tr.GEntrega; ent != nil {
+		describe(n, path+".GEntrega.CDep", ent.CDep, &ent.DDesDep)
+	}
+
+	if t := tr.GTransportista; t != nil {
+		if t.ITipIdTrans != 0 {
+			describe(n, path+".GTransportista.ITipIdTrans", t.ITipIdTrans, &t.DDesTipIdTrans)
+		}
+		if t.CPaisTrans != "" {
+			n.pais(path+".GTransportista.CPaisTrans", t.CPaisTrans, &t.DDesPaisTrans)
+		}
+	}
+}
+
+func (n *normalizer) carga(path string, c *TgCamCarg) {
+	if c.CUniMedTotVol != 0 {
+		n.unidad(path+".CUniMedTotVol", c.CUniMedTotVol, &c.DDesUniMedTotVol)
+	}
+	if c.CUniMedTotPes != 0 {
+		n.unidad(path+".CUniMedTotPes", c.CUniMedTotPes, &c.DDesUniMedTotPes)
+	}
+	if c.ICarCarga != 0 {
+		describe(n, path+".ICarCarga", c.ICarCarga, &c.DDesCarCarga)
+	}
+}
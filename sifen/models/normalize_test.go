@@ -0,0 +1,47 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/rodascaar/sifen-go-py/sifen/types"
+)
+
+func TestNormalize(t *testing.T) {
+	de := NewDE("")
+	de.DE.GOpeDE.ITipEmi = types.TTipEmi_Normal
+	de.DE.GTimb.ITiDE = types.TTiDE_FacturaElectronica
+	de.DE.GDatGralOpe.GEmis.CDepEmi = types.TDepartamento_Central
+	de.DE.GDatGralOpe.GDatRec.CPaisRec = types.PaisType_PRY
+	de.DE.GDtipDE.GCamCond = &TgCamCond{
+		ICondOpe:   types.TiCondOpe_Contado,
+		GPaConEIni: []TgPaConEIni{{ITiPago: types.TiTipPago_Efectivo, CMoneOpe: types.CMondT_PYG}},
+	}
+	de.DE.GDtipDE.GCamItemList = []TgCamItem{
+		// Descripción con una tilde de más: se reemplaza por el texto oficial
+		{CUniMed: types.TcUniMed_Unidad, GCamIVA: &TgCamIVA{IAfecIVA: types.TiAfecIVA_GravadoIVA, DDesAfecIVA: "Gravado ÍVA"}},
+		// Código desconocido con descripción cargada: se conserva
+		{CUniMed: 2, DDesUniMed: "ROLLO", GCamIVA: &TgCamIVA{IAfecIVA: 9}},
+	}
+
+	unknown := de.Normalize()
+
+	want := []UnknownCode{{Field: "DE.GDtipDE.GCamItemList[1].GCamIVA.IAfecIVA", Code: "9"}}
+	if len(unknown) != len(want) || unknown[0] != want[0] {
+		t.Fatalf("Normalize() = %v; want %v", unknown, want)
+	}
+
+	items := de.DE.GDtipDE.GCamItemList
+	if got := items[0].GCamIVA.DDesAfecIVA; got != "Gravado IVA" {
+		t.Errorf("DDesAfecIVA = %q", got)
+	}
+	if items[0].DDesUniMed != "UNI" || items[1].DDesUniMed != "ROLLO" {
+		t.Errorf("DDesUniMed = %q, %q", items[0].DDesUniMed, items[1].DDesUniMed)
+	}
+	pago := de.DE.GDtipDE.GCamCond.GPaConEIni[0]
+	if pago.DDesTiPago != "Efectivo" || pago.DDesMoneOpe != "Guarani" {
+		t.Errorf("GPaConEIni = %+v", pago)
+	}
+	if de.DE.GTimb.DDesTiDE != "Factura electrónica" || de.DE.GDatGralOpe.GEmis.DDesDepEmi != "CENTRAL" {
+		t.Errorf("DDesTiDE = %q, DDesDepEmi = %q", de.DE.GTimb.DDesTiDE, de.DE.GDatGralOpe.GEmis.DDesDepEmi)
+	}
+}
@@ -0,0 +1,245 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/rodascaar/sifen-go-py/sifen/types"
+)
+
+// ============================================================================
+// Cálculo de valores de los ítems y totales (EA001-EA009, E720-E737, F001-F037)
+// ============================================================================
+
+var (
+	cien    = types.DecimalFromInt(100)
+	diezMil = types.DecimalFromInt(10000)
+)
+
+// TotalsOptions ajusta el cálculo de CalculateTotals
+type TotalsOptions struct {
+	// Redondeo es el múltiplo en guaraníes hacia el que se redondea hacia abajo
+	// el total general de las operaciones en guaraníes (50 para el redondeo de
+	// SEDECO). La diferencia se informa en dRedon; 0 no redondea.
+	Redondeo int64
+}
+
+// CalculateTotals completa los valores de cada ítem y el grupo gTotSub a partir
+// del precio unitario, la cantidad, los descuentos y anticipos por unidad, la
+// afectación y tasa del IVA y, en los ítems gravados parcialmente, la
+// proporción gravada (dPropIVA). De gTotSub se conservan el porcentaje de
+// descuento global (dPorcDescTotal), que se prorratea en el dDescGloItem de cada
+// ítem, y la comisión con su IVA (dComi, dIVAComi); el resto se recalcula.
+//
+// Los montos se redondean a los decimales de la moneda de la operación: 0 en
+// guaraníes y 8 en las demás. Los subtotales y la liquidación por tasa sólo se
+// informan si el impuesto afectado es IVA o IVA-Renta. La nota de remisión no
+// lleva valores y no se modifica.
+//
+// Si algún resultado no cabe en un types.Decimal (18 dígitos significativos)
+// o la tasa y proporción del IVA anulan el divisor de la base gravada, retorna
+// el error y gTotSub no se modifica, aunque los ítems anteriores al que falló
+// ya estén calculados.
+func (de *DocumentoElectronico) CalculateTotals(opts TotalsOptions) error {
+	d := &de.DE
+	if d.GTimb.ITiDE == types.TTiDE_NotaRemisionElectronica {
+		return nil
+	}
+
+	c := &calculator{ope: d.GDatGralOpe.GOpeCom, tot: &TgTotSub{}}
+	if c.ope != nil && c.ope.CMoneOpe != "" {
+		c.decimals = c.ope.CMoneOpe.Decimales()
+	}
+	if prev := d.GTotSub; prev != nil {
+		c.tot.DPorcDescTotal = prev.DPorcDescTotal
+		c.tot.DComi = prev.DComi
+		c.tot.DIVAComi = prev.DIVAComi
+	}
+
+	for i := range d.GDtipDE.GCamItemList {
+		c.item(&d.GDtipDE.GCamItemList[i])
+		if c.err != nil {
+			return fmt.Errorf("ítem %d: %w", i+1, c.err)
+		}
+	}
+
+	tot := c.tot
+	tot.DDescTotal = c.add(tot.DTotDesc, tot.DTotDescGlotem)
+	tot.DAnticipo = c.add(tot.DTotAntItem, tot.DTotAnt)
+
+	var liq5, liq10 types.Decimal
+	if opts.Redondeo > 0 && c.decimals == 0 {
+		tot.DRedon = types.DecimalFromInt(tot.DTotOpe.IntPart() % opts.Redondeo)
+		// El IVA contenido en el redondeo se descuenta de la tasa mayor
+		switch {
+		case c.sub10.Sign() > 0:
+			liq10 = c.divRound(tot.DRedon, types.DecimalFromInt(11), 0)
+		case c.sub5.Sign() > 0:
+			liq5 = c.divRound(tot.DRedon, types.DecimalFromInt(21), 0)
+		}
+	}
+	tot.DTotGralOpe = c.add(c.sub(tot.DTotOpe, tot.DRedon), deref(tot.DComi))
+
+	if c.ope != nil && (c.ope.ITImp == types.TTImp_IVA || c.ope.ITImp == types.TTImp_IVARenta) {
+		tot.DSub5, tot.DSub10 = c.sub5.Ptr(), c.sub10.Ptr()
+		tot.DIVA5, tot.DIVA10 = c.iva5.Ptr(), c.iva10.Ptr()
+		tot.DLiqTotIVA5, tot.DLiqTotIVA10 = liq5.Ptr(), liq10.Ptr()
+		tot.DTotIVA = c.add(c.sub(c.sub(c.add(c.iva5, c.iva10), liq5), liq10), deref(tot.DIVAComi)).Ptr()
+		tot.DBaseGrav5, tot.DBaseGrav10 = c.base5.Ptr(), c.base10.Ptr()
+		tot.DTBasGraIVA = c.add(c.base5, c.base10).Ptr()
+	}
+
+	if c.decimals > 0 && c.ope.DCondTiCam != nil {
+		switch {
+		case *c.ope.DCondTiCam == 1 && c.ope.DTiCam != nil:
+			tot.DTotalGs = c.mul(tot.DTotGralOpe, *c.ope.DTiCam).Round(0).Ptr()
+		case *c.ope.DCondTiCam == 2:
+			tot.DTotalGs = c.totalGs.Ptr()
+		}
+	}
+
+	if c.err != nil {
+		return fmt.Errorf("totales: %w", c.err)
+	}
+	d.GTotSub = tot
+	return nil
+}
+
+type calculator struct {
+	decimals int
+	ope      *TgOpeCom
+	tot      *TgTotSub
+
+	// Acumulados por tasa y en guaraníes
+	sub5, sub10, iva5, iva10, base5, base10 types.Decimal
+	totalGs                                 types.Decimal
+
+	// Primer error de las operaciones; las siguientes retornan cero
+	err error
+}
+
+// add, sub, mul y divRound operan como los métodos de types.Decimal, pero
+// ante un desbordamiento o una división por cero guardan el error en c.err
+// en lugar de entrar en pánico
+func (c *calculator) add(a, b types.Decimal) types.Decimal {
+	return c.check(a.CheckedAdd(b))
+}
+
+func (c *calculator) sub(a, b types.Decimal) types.Decimal {
+	return c.check(a.CheckedSub(b))
+}
+
+func (c *calculator) mul(a, b types.Decimal) types.Decimal {
+	return c.check(a.CheckedMul(b))
+}
+
+func (c *calculator) divRound(a, b types.Decimal, decimals int) types.Decimal {
+	return c.check(a.CheckedDivRound(b, decimals))
+}
+
+func (c *calculator) check(d types.Decimal, err error) types.Decimal {
+	if err != nil {
+		if c.err == nil {
+			c.err = err
+		}
+		return types.Decimal{}
+	}
+	return d
+}
+
+// round redondea un monto a los decimales de la moneda
+func (c *calculator) round(d types.Decimal) types.Decimal {
+	return d.Round(c.decimals)
+}
+
+func (c *calculator) item(item *TgCamItem) {
+	val := &item.GValorItem
+	resta := &val.GValorRestaItem
+	cant := item.DCantProSer
+	tot := c.tot
+
+	val.DTotBruOpeItem = c.round(c.mul(val.DPUniProSer, cant))
+
+	desc := deref(resta.DDescItem)
+	if resta.DDescItem != nil && !val.DPUniProSer.IsZero() {
+		resta.DPorcDesIt = c.divRound(c.mul(desc, cien), val.DPUniProSer, types.MaxDecimales).Ptr()
+	}
+	if tot.DPorcDescTotal.Sign() > 0 {
+		resta.DDescGloItem = c.divRound(c.mul(c.sub(val.DPUniProSer, desc), tot.DPorcDescTotal), cien, types.MaxDecimales).Ptr()
+	}
+
+	unit := c.sub(c.sub(c.sub(c.sub(val.DPUniProSer, desc), deref(resta.DDescGloItem)),
+		deref(resta.DAntPreUniIt)), deref(resta.DAntGloPreUniIt))
+	total := c.round(c.mul(unit, cant))
+	resta.DTotOpeItem = total
+	resta.DTotOpeGs = nil
+	if c.decimals > 0 && c.ope.DCondTiCam != nil && *c.ope.DCondTiCam == 2 && val.DTiCamIt != nil {
+		gs := c.mul(total, *val.DTiCamIt).Round(0)
+		resta.DTotOpeGs = &gs
+		c.totalGs = c.add(c.totalGs, gs)
+	}
+
+	tot.DTotOpe = c.add(tot.DTotOpe, total)
+	tot.DTotDesc = c.add(tot.DTotDesc, c.round(c.mul(desc, cant)))
+	tot.DTotDescGlotem = c.add(tot.DTotDescGlotem, c.round(c.mul(deref(resta.DDescGloItem), cant)))
+	tot.DTotAntItem = c.add(tot.DTotAntItem, c.round(c.mul(deref(resta.DAntPreUniIt), cant)))
+	tot.DTotAnt = c.add(tot.DTotAnt, c.round(c.mul(deref(resta.DAntGloPreUniIt), cant)))
+
+	if item.GCamIVA != nil {
+		c.iva(item.GCamIVA, total)
+	}
+}
+
+// iva completa el gCamIVA del ítem. Con la proporción gravada P y la tasa T,
+// la base gravada es 100·total·P / (10000 + T·P) y la exenta
+// 100·total·(100-P) / (10000 + T·P); en un ítem gravado al 100% la base es el
+// total sin el IVA incluido.
+func (c *calculator) iva(iva *TgCamIVA, total types.Decimal) {
+	switch iva.IAfecIVA {
+	case types.TiAfecIVA_GravadoIVA:
+		iva.DPropIVA = cien
+	case types.TiAfecIVA_Exonerado, types.TiAfecIVA_Exento:
+		iva.DPropIVA = types.Decimal{}
+		iva.DTasaIVA = types.Decimal{}
+	}
+
+	prop, tasa := iva.DPropIVA, iva.DTasaIVA
+	div := c.add(diezMil, c.mul(tasa, prop))
+	iva.DBasGravIVA = c.divRound(c.mul(c.mul(cien, total), prop), div, c.decimals)
+	iva.DLiqIVAItem = c.divRound(c.mul(iva.DBasGravIVA, tasa), cien, c.decimals)
+	var exe types.Decimal
+	if iva.IAfecIVA == types.TiAfecIVA_GravadoParcial {
+		exe = c.divRound(c.mul(c.mul(cien, total), c.sub(cien, prop)), div, c.decimals)
+	}
+	iva.DBasExe = &exe
+
+	tot := c.tot
+	switch iva.IAfecIVA {
+	case types.TiAfecIVA_Exento:
+		tot.DSubExe = c.add(tot.DSubExe, total)
+		return
+	case types.TiAfecIVA_Exonerado:
+		tot.DSubExo = c.add(tot.DSubExo, total)
+		return
+	case types.TiAfecIVA_GravadoParcial:
+		tot.DSubExe = c.add(tot.DSubExe, exe)
+		total = c.add(iva.DBasGravIVA, iva.DLiqIVAItem)
+	}
+
+	switch tasa {
+	case types.DecimalFromInt(5):
+		c.sub5 = c.add(c.sub5, total)
+		c.iva5 = c.add(c.iva5, iva.DLiqIVAItem)
+		c.base5 = c.add(c.base5, iva.DBasGravIVA)
+	case types.DecimalFromInt(10):
+		c.sub10 = c.add(c.sub10, total)
+		c.iva10 = c.add(c.iva10, iva.DLiqIVAItem)
+		c.base10 = c.add(c.base10, iva.DBasGravIVA)
+	}
+}
+
+func deref(d *types.Decimal) types.Decimal {
+	if d == nil {
+		return types.Decimal{}
+	}
+	return *d
+}
@@ -0,0 +1,196 @@
+package models
+
+import (
+	"encoding/xml"
+	stderrors "errors"
+	"fmt"
+	"testing"
+
+	"github.com/rodascaar/sifen-go-py/sifen/types"
+)
+
+func newFactura(moneda types.CMondT, items ...TgCamItem) *DocumentoElectronico {
+	de := NewDE("")
+	de.DE.GTimb.ITiDE = types.TTiDE_FacturaElectronica
+	de.DE.GDatGralOpe.GOpeCom = &TgOpeCom{ITImp: types.TTImp_IVA, CMoneOpe: moneda}
+	de.DE.GDtipDE.GCamItemList = items
+	return de
+}
+
+func calcItem(precio, cantidad string, afec types.TiAfecIVA, tasa, prop int64) TgCamItem {
+	return TgCamItem{
+		DCantProSer: types.MustParseDecimal(cantidad),
+		GValorItem:  TgValorItem{DPUniProSer: types.MustParseDecimal(precio)},
+		GCamIVA:     &TgCamIVA{IAfecIVA: afec, DTasaIVA: types.DecimalFromInt(tasa), DPropIVA: types.DecimalFromInt(prop)},
+	}
+}
+
+func dec(s string) *types.Decimal {
+	return types.MustParseDecimal(s).Ptr()
+}
+
+// marshal serializa un grupo para compararlo con la salida XML esperada
+func marshal(t *testing.T, v any) string {
+	t.Helper()
+	out, err := xml.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestCalculateTotals(t *testing.T) {
+	gravado10 := calcItem("110000", "3", types.TiAfecIVA_GravadoIVA, 10, 0)
+	gravado10.GValorItem.GValorRestaItem.DDescItem = dec("11000")
+	de := newFactura(types.CMondT_PYG,
+		gravado10,
+		calcItem("21000", "2", types.TiAfecIVA_GravadoIVA, 5, 0),
+		calcItem("50000", "1", types.TiAfecIVA_Exento, 10, 0),
+		calcItem("30000", "1", types.TiAfecIVA_Exonerado, 0, 0),
+		calcItem("105000", "1", types.TiAfecIVA_GravadoParcial, 10, 50),
+	)
+
+	if err := de.CalculateTotals(TotalsOptions{}); err != nil {
+		t.Fatalf("CalculateTotals() error = %v", err)
+	}
+
+	items := de.DE.GDtipDE.GCamItemList
+	want := `<TgValorItem><dPUniProSer>110000</dPUniProSer><dTotBruOpeItem>330000</dTotBruOpeItem>` +
+		`<gValorRestaItem><dDescItem>11000</dDescItem><dPorcDesIt>10</dPorcDesIt><dTotOpeItem>297000</dTotOpeItem></gValorRestaItem></TgValorItem>`
+	if got := marshal(t, items[0].GValorItem); got != want {
+		t.Errorf("GValorItem = %s; want %s", got, want)
+	}
+	ivas := []string{
+		"<dPropIVA>100</dPropIVA><dTasaIVA>10</dTasaIVA><dBasGravIVA>270000</dBasGravIVA><dLiqIVAItem>27000</dLiqIVAItem><dBasExe>0</dBasExe>",
+		"<dPropIVA>100</dPropIVA><dTasaIVA>5</dTasaIVA><dBasGravIVA>40000</dBasGravIVA><dLiqIVAItem>2000</dLiqIVAItem><dBasExe>0</dBasExe>",
+		"<dPropIVA>0</dPropIVA><dTasaIVA>0</dTasaIVA><dBasGravIVA>0</dBasGravIVA><dLiqIVAItem>0</dLiqIVAItem><dBasExe>0</dBasExe>",
+		"<dPropIVA>0</dPropIVA><dTasaIVA>0</dTasaIVA><dBasGravIVA>0</dBasGravIVA><dLiqIVAItem>0</dLiqIVAItem><dBasExe>0</dBasExe>",
+		"<dPropIVA>50</dPropIVA><dTasaIVA>10</dTasaIVA><dBasGravIVA>50000</dBasGravIVA><dLiqIVAItem>5000</dLiqIVAItem><dBasExe>50000</dBasExe>",
+	}
+	for i, want := range ivas {
+		iva := *items[i].GCamIVA
+		iva.IAfecIVA = 0
+		if got := marshal(t, iva); got != "<TgCamIVA><iAfecIVA>0</iAfecIVA><dDesAfecIVA></dDesAfecIVA>"+want+"</TgCamIVA>" {
+			t.Errorf("item %d: GCamIVA = %s; want %s", i, got, want)
+		}
+	}
+
+	want = `<TgTotSub><dSubExe>100000</dSubExe><dSubExo>30000</dSubExo><dSub5>42000</dSub5><dSub10>352000</dSub10>` +
+		`<dTotOpe>524000</dTotOpe><dTotDesc>33000</dTotDesc><dTotDescGlotem>0</dTotDescGlotem><dTotAntItem>0</dTotAntItem>` +
+		`<dTotAnt>0</dTotAnt><dPorcDescTotal>0</dPorcDescTotal><dDescTotal>33000</dDescTotal><dAnticipo>0</dAnticipo>` +
+		`<dRedon>0</dRedon><dTotGralOpe>524000</dTotGralOpe><dIVA5>2000</dIVA5><dIVA10>32000</dIVA10>` +
+		`<dLiqTotIVA5>0</dLiqTotIVA5><dLiqTotIVA10>0</dLiqTotIVA10><dTotIVA>34000</dTotIVA>` +
+		`<dBaseGrav5>40000</dBaseGrav5><dBaseGrav10>320000</dBaseGrav10><dTBasGraIVA>360000</dTBasGraIVA></TgTotSub>`
+	if got := marshal(t, de.DE.GTotSub); got != want {
+		t.Errorf("GTotSub = %s; want %s", got, want)
+	}
+}
+
+func TestCalculateTotalsDescuentoGlobalYRedondeo(t *testing.T) {
+	de := newFactura(types.CMondT_PYG,
+		calcItem("100000", "2", types.TiAfecIVA_GravadoIVA, 10, 0),
+		calcItem("12000", "1", types.TiAfecIVA_GravadoIVA, 10, 0),
+	)
+	de.DE.GDtipDE.GCamItemList[1].GValorItem.GValorRestaItem.DAntPreUniIt = dec("345")
+	de.DE.GTotSub = &TgTotSub{DPorcDescTotal: types.DecimalFromInt(10), DTotOpe: types.DecimalFromInt(1)}
+
+	if err := de.CalculateTotals(TotalsOptions{Redondeo: 50}); err != nil {
+		t.Fatalf("CalculateTotals() error = %v", err)
+	}
+
+	resta := de.DE.GDtipDE.GCamItemList[0].GValorItem.GValorRestaItem
+	if resta.DDescGloItem.String() != "10000" || resta.DTotOpeItem.String() != "180000" {
+		t.Errorf("GValorRestaItem = %s", marshal(t, resta))
+	}
+	// 180000 + 12000 - 1200 de descuento global - 345 de anticipo
+	tot := de.DE.GTotSub
+	got := fmt.Sprint(tot.DTotOpe, tot.DTotDescGlotem, tot.DDescTotal, tot.DTotAntItem, tot.DAnticipo, tot.DRedon, tot.DTotGralOpe)
+	if want := "190455 21200 21200 345 345 5 190450"; got != want {
+		t.Errorf("dTotOpe, dTotDescGlotem, dDescTotal, dTotAntItem, dAnticipo, dRedon, dTotGralOpe = %s; want %s", got, want)
+	}
+
+	de.DE.GTotSub = nil
+	de.DE.GDtipDE.GCamItemList = de.DE.GDtipDE.GCamItemList[1:]
+	de.DE.GDtipDE.GCamItemList[0].GValorItem = TgValorItem{DPUniProSer: types.DecimalFromInt(12345)}
+	if err := de.CalculateTotals(TotalsOptions{Redondeo: 50}); err != nil {
+		t.Fatalf("CalculateTotals() error = %v", err)
+	}
+
+	tot = de.DE.GTotSub
+	got = fmt.Sprint(tot.DRedon, tot.DTotGralOpe, tot.DIVA10, tot.DLiqTotIVA10, tot.DTotIVA)
+	if want := "45 12300 1122 4 1118"; got != want {
+		t.Errorf("dRedon, dTotGralOpe, dIVA10, dLiqTotIVA10, dTotIVA = %s; want %s", got, want)
+	}
+}
+
+func TestCalculateTotalsMonedaExtranjera(t *testing.T) {
+	de := newFactura(types.CMondT_USD, calcItem("10.5", "3", types.TiAfecIVA_GravadoIVA, 10, 0))
+	cond := int16(1)
+	de.DE.GDatGralOpe.GOpeCom.DCondTiCam = &cond
+	de.DE.GDatGralOpe.GOpeCom.DTiCam = dec("7300")
+
+	if err := de.CalculateTotals(TotalsOptions{Redondeo: 50}); err != nil {
+		t.Fatalf("CalculateTotals() error = %v", err)
+	}
+
+	iva := de.DE.GDtipDE.GCamItemList[0].GCamIVA
+	if iva.DBasGravIVA.String() != "28.63636364" || iva.DLiqIVAItem.String() != "2.86363636" {
+		t.Errorf("GCamIVA = %s", marshal(t, iva))
+	}
+	tot := de.DE.GTotSub
+	if tot.DRedon.String() != "0" || tot.DTotGralOpe.String() != "31.5" || tot.DTotalGs == nil || tot.DTotalGs.String() != "229950" {
+		t.Errorf("GTotSub = %s", marshal(t, tot))
+	}
+}
+
+func TestCalculateTotalsSinIVA(t *testing.T) {
+	item := calcItem("1500000", "1", types.TiAfecIVA_GravadoIVA, 10, 0)
+	item.GCamIVA = nil
+	de := newFactura(types.CMondT_PYG, item)
+	de.DE.GDatGralOpe.GOpeCom.ITImp = types.TTImp_Renta
+
+	if err := de.CalculateTotals(TotalsOptions{}); err != nil {
+		t.Fatalf("CalculateTotals() error = %v", err)
+	}
+
+	tot := de.DE.GTotSub
+	if tot.DTotGralOpe.String() != "1500000" || tot.DSub10 != nil || tot.DTotIVA != nil {
+		t.Errorf("GTotSub = %s", marshal(t, tot))
+	}
+}
+
+func TestCalculateTotalsNotaRemision(t *testing.T) {
+	de := newFactura(types.CMondT_PYG, calcItem("1000", "1", types.TiAfecIVA_GravadoIVA, 10, 0))
+	de.DE.GTimb.ITiDE = types.TTiDE_NotaRemisionElectronica
+
+	if err := de.CalculateTotals(TotalsOptions{}); err != nil {
+		t.Fatalf("CalculateTotals() error = %v", err)
+	}
+
+	if de.DE.GTotSub != nil || !de.DE.GDtipDE.GCamItemList[0].GValorItem.GValorRestaItem.DTotOpeItem.IsZero() {
+		t.Errorf("la nota de remisión no debe tener valores: %+v", de.DE.GTotSub)
+	}
+}
+
+func TestCalculateTotalsFueraDeRango(t *testing.T) {
+	// El divisor de la base gravada 10000 + tasa·proporción se anula
+	divisorCero := calcItem("1000", "1", types.TiAfecIVA_GravadoParcial, -200, 50)
+	tests := []struct {
+		name string
+		item TgCamItem
+		want error
+	}{
+		{"desbordamiento", calcItem("900000000000000000", "100", types.TiAfecIVA_GravadoIVA, 10, 0), types.ErrDesbordamiento},
+		{"división por cero", divisorCero, types.ErrDivisionPorCero},
+	}
+	for _, tt := range tests {
+		de := newFactura(types.CMondT_PYG, calcItem("1000", "1", types.TiAfecIVA_GravadoIVA, 10, 0), tt.item)
+		err := de.CalculateTotals(TotalsOptions{})
+		if !stderrors.Is(err, tt.want) {
+			t.Errorf("%s: CalculateTotals() error = %v; want %v", tt.name, err, tt.want)
+		}
+		if de.DE.GTotSub != nil {
+			t.Errorf("%s: CalculateTotals() set gTotSub after an error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package qr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/rodascaar/sifen-go-py/sifen/models"
+	"github.com/rodascaar/sifen-go-py/sifen/types"
+)
+
+// ============================================================================
+// Código QR del KuDE (gCamFuFD.dCarQR)
+// ============================================================================
+
+// Params contiene los parámetros del QR en el orden del Manual Técnico v150
+type Params struct {
+	Version      int    // nVersion
+	CDC          string // Id
+	FechaEmision string // dFeEmiDE tal como figura en el DE (yyyy-MM-ddTHH:mm:ss)
+	RUCReceptor  string // dRucRec, si el receptor es contribuyente
+	IDReceptor   string // dNumIDRec, si el receptor no es contribuyente
+	TotalGeneral types.Decimal
+	TotalIVA     types.Decimal
+	Items        int
+	DigestValue  string // DigestValue de la firma, en Base64 tal como figura en el XML
+	IdCSC        string
+}
+
+// FromDE toma los parámetros del QR de un DE firmado. digestValue es el
+// DigestValue de su firma en Base64.
+func FromDE(de *models.DocumentoElectronico, digestValue, idCSC string) Params {
+	p := Params{
+		Version:      de.DVerFor,
+		CDC:          de.DE.Id,
+		FechaEmision: de.DE.GDatGralOpe.DFeEmiDE,
+		Items:        len(de.DE.GDtipDE.GCamItemList),
+		DigestValue:  digestValue,
+		IdCSC:        idCSC,
+	}
+	if p.Version == 0 {
+		p.Version = 150
+	}
+
+	rec := de.DE.GDatGralOpe.GDatRec
+	if rec.INatRec == types.TiNatRec_Contribuyente {
+		p.RUCReceptor = rec.DRucRec
+	} else {
+		// Receptor innominado: dNumIDRec = 0
+		p.IDReceptor = rec.DNumIDRec
+		if p.IDReceptor == "" {
+			p.IDReceptor = "0"
+		}
+	}
+
+	// La nota de remisión no tiene totales: se informan en 0
+	if de.DE.GTotSub != nil && de.DE.GTimb.ITiDE != types.TTiDE_NotaRemisionElectronica {
+		p.TotalGeneral = de.DE.GTotSub.DTotGralOpe
+		if iva := de.DE.GTotSub.DTotIVA; iva != nil {
+			p.TotalIVA = *iva
+		}
+	}
+	return p
+}
+
+// Query retorna los parámetros sin cHashQR. La fecha y el DigestValue se
+// codifican en hexadecimal.
+func (p Params) Query() string {
+	var b strings.Builder
+	b.WriteString("nVersion=")
+	b.WriteString(strconv.Itoa(p.Version))
+	b.WriteString("&Id=")
+	b.WriteString(p.CDC)
+	b.WriteString("&dFeEmiDE=")
+	b.WriteString(hex.EncodeToString([]byte(p.FechaEmision)))
+	if p.RUCReceptor != "" {
+		b.WriteString("&dRucRec=")
+		b.WriteString(p.RUCReceptor)
+	} else {
+		b.WriteString("&dNumIDRec=")
+		b.WriteString(p.IDReceptor)
+	}
+	b.WriteString("&dTotGralOpe=")
+	b.WriteString(p.TotalGeneral.String())
+	b.WriteString("&dTotIVA=")
+	b.WriteString(p.TotalIVA.String())
+	b.WriteString("&cItems=")
+	b.WriteString(strconv.Itoa(p.Items))
+	b.WriteString("&DigestValue=")
+	b.WriteString(hex.EncodeToString([]byte(p.DigestValue)))
+	b.WriteString("&IdCSC=")
+	b.WriteString(p.IdCSC)
+	return b.String()
+}
+
+// Hash calcula cHashQR: SHA-256 en hexadecimal de los parámetros seguidos del CSC
+func Hash(query, csc string) string {
+	sum := sha256.Sum256([]byte(query + csc))
+	return hex.EncodeToString(sum[:])
+}
+
+// URL retorna el contenido de dCarQR: baseURL (por ejemplo
+// https://ekuatia.set.gov.py/consultas/qr?), los parámetros y cHashQR
+func (p Params) URL(baseURL, csc string) string {
+	query := p.Query()
+	return baseURL + query + "&cHashQR=" + Hash(query, csc)
+}
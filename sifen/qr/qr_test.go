@@ -0,0 +1,53 @@
+package qr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/rodascaar/sifen-go-py/sifen/models"
+	"github.com/rodascaar/sifen-go-py/sifen/types"
+)
+
+const (
+	testCDC = "01800695631001001000000612024123017595714694"
+	testCSC = "ABCD0000000000000000000000000000"
+)
+
+func TestFromDE(t *testing.T) {
+	de := models.NewDE(testCDC)
+	de.DE.GTimb.ITiDE = types.TTiDE_FacturaElectronica
+	de.DE.GDatGralOpe.DFeEmiDE = "2024-12-30T17:59:57"
+	de.DE.GDatGralOpe.GDatRec = models.TgDatRec{INatRec: types.TiNatRec_Contribuyente, DRucRec: "80012345"}
+	de.DE.GDtipDE.GCamItemList = make([]models.TgCamItem, 2)
+	de.DE.GTotSub = &models.TgTotSub{DTotGralOpe: types.DecimalFromInt(1100000), DTotIVA: types.DecimalFromInt(100000).Ptr()}
+
+	p := FromDE(de, "mCH0aDXjR7fQmWWJJ7FxzxrUMo4CYW9qBA3gMiWA8X8=", "0001")
+	want := "nVersion=150&Id=" + testCDC +
+		"&dFeEmiDE=" + hex.EncodeToString([]byte("2024-12-30T17:59:57")) +
+		"&dRucRec=80012345&dTotGralOpe=1100000&dTotIVA=100000&cItems=2" +
+		"&DigestValue=" + hex.EncodeToString([]byte("mCH0aDXjR7fQmWWJJ7FxzxrUMo4CYW9qBA3gMiWA8X8=")) +
+		"&IdCSC=0001"
+	if got := p.Query(); got != want {
+		t.Errorf("Query() =\n%s\nwant\n%s", got, want)
+	}
+
+	sum := sha256.Sum256([]byte(want + testCSC))
+	url := p.URL("https://ekuatia.set.gov.py/consultas-test/qr?", testCSC)
+	if url != "https://ekuatia.set.gov.py/consultas-test/qr?"+want+"&cHashQR="+hex.EncodeToString(sum[:]) {
+		t.Errorf("URL() = %s", url)
+	}
+}
+
+func TestFromDEWithoutRUCOrTotals(t *testing.T) {
+	de := models.NewDE(testCDC)
+	de.DE.GTimb.ITiDE = types.TTiDE_NotaRemisionElectronica
+	de.DE.GDatGralOpe.GDatRec = models.TgDatRec{INatRec: types.TiNatRec_NoContribuyente, DNumIDRec: "1234567"}
+	de.DE.GTotSub = &models.TgTotSub{DTotGralOpe: types.DecimalFromInt(5000)}
+
+	query := FromDE(de, "", "0001").Query()
+	if !strings.Contains(query, "&dNumIDRec=1234567&dTotGralOpe=0&dTotIVA=0&") {
+		t.Errorf("Query() = %s", query)
+	}
+}
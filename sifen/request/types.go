@@ -29,10 +29,12 @@ type XDE struct {
 // ============================================================================
 // Recepción Lote DE Request (Batch Documents)
 // ============================================================================
-type REnviLoteDe struct {
-	XMLName xml.Name `xml:"http://ekuatia.set.gov.py/sifen/xsd rEnviLoteDe"`
+
+// REnvioLote lleva en XDE el .zip del lote (rLoteDE) codificado en Base64
+type REnvioLote struct {
+	XMLName xml.Name `xml:"http://ekuatia.set.gov.py/sifen/xsd rEnvioLote"`
 	DId     int64    `xml:"dId"`
-	XDEList []XDE    `xml:"xDE"`
+	XDE     string   `xml:"xDE"`
 }
 
 // ============================================================================
@@ -59,5 +61,14 @@ type REnviConsLoteDe struct {
 type REnviEventoDe struct {
 	XMLName xml.Name `xml:"http://ekuatia.set.gov.py/sifen/xsd rEnviEventoDe"`
 	DId     int64    `xml:"dId"`
-	DEvReg  string   `xml:"dEvReg"` // Evento registrado (XML del evento firmado)
+	DEvReg  DEvReg   `xml:"dEvReg"`
+}
+
+type DEvReg struct {
+	GGroupGesEve GGroupGesEve `xml:"gGroupGesEve"`
+}
+
+// GGroupGesEve contiene los rGesEve firmados (rEve seguido de ds:Signature)
+type GGroupGesEve struct {
+	RawRGesEve []byte `xml:",innerxml"`
 }
@@ -2,6 +2,7 @@ package response
 
 import (
 	"encoding/xml"
+	"strings"
 	"time"
 )
 
@@ -125,6 +126,7 @@ type EnvelopeRefResponse struct {
 }
 
 type BodyRefResponse struct {
+	Fault              *Fault                    `xml:"Fault,omitempty"`
 	RResEnviConsRuc    *RespuestaConsultaRUC     `xml:"rResEnviConsRuc,omitempty"`
 	RRetEnviDe         *RespuestaRecepcionDE     `xml:"rRetEnviDe,omitempty"`
 	RRetEnviLoteDe     *RespuestaRecepcionLoteDE `xml:"rRetEnviLoteDe,omitempty"`
@@ -133,6 +135,106 @@ type BodyRefResponse struct {
 	RRetEnviEventoDe   *RespuestaEvento          `xml:"rRetEnviEventoDe,omitempty"`
 }
 
+// Code returns the dCodRes of whichever response the body contains
+func (b *BodyRefResponse) Code() string {
+	switch {
+	case b.RResEnviConsRuc != nil:
+		return b.RResEnviConsRuc.DCodRes
+	case b.RRetEnviDe != nil:
+		return b.RRetEnviDe.DCodRes
+	case b.RRetEnviLoteDe != nil:
+		return b.RRetEnviLoteDe.DCodRes
+	case b.RResEnviConsDe != nil:
+		return b.RResEnviConsDe.DCodRes
+	case b.RResEnviConsLoteDe != nil:
+		return b.RResEnviConsLoteDe.DCodRes
+	case b.RRetEnviEventoDe != nil:
+		return b.RRetEnviEventoDe.DCodRes
+	}
+	return ""
+}
+
+// ============================================================================
+// SOAP 1.2 Fault
+// ============================================================================
+type Fault struct {
+	Code   FaultCode    `xml:"Code"`
+	Reason FaultReason  `xml:"Reason"`
+	Node   string       `xml:"Node,omitempty"`
+	Role   string       `xml:"Role,omitempty"`
+	Detail *FaultDetail `xml:"Detail,omitempty"`
+}
+
+type FaultCode struct {
+	Value   string     `xml:"Value"`
+	Subcode *FaultCode `xml:"Subcode,omitempty"`
+}
+
+type FaultReason struct {
+	Text []FaultText `xml:"Text"`
+}
+
+type FaultText struct {
+	Lang  string `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+type FaultDetail struct {
+	InnerXML string `xml:",innerxml"` // Contenido XML crudo del detalle
+}
+
+// Codes returns the fault code followed by every nested subcode, without namespace prefixes
+func (f *Fault) Codes() []string {
+	var codes []string
+	for c := &f.Code; c != nil; c = c.Subcode {
+		value := c.Value
+		if i := strings.LastIndex(value, ":"); i >= 0 {
+			value = value[i+1:]
+		}
+		codes = append(codes, value)
+	}
+	return codes
+}
+
+// MostSpecificCode returns the innermost subcode, or the fault code if there are no subcodes
+func (f *Fault) MostSpecificCode() string {
+	codes := f.Codes()
+	return codes[len(codes)-1]
+}
+
+// ReasonText returns the Spanish reason text if present, otherwise the first one
+func (f *Fault) ReasonText() string {
+	for _, t := range f.Reason.Text {
+		if strings.HasPrefix(t.Lang, "es") {
+			return strings.TrimSpace(t.Value)
+		}
+	}
+	if len(f.Reason.Text) > 0 {
+		return strings.TrimSpace(f.Reason.Text[0].Value)
+	}
+	return ""
+}
+
+// DetailXML returns the raw XML inside the Detail element
+func (f *Fault) DetailXML() string {
+	if f.Detail == nil {
+		return ""
+	}
+	return strings.TrimSpace(f.Detail.InnerXML)
+}
+
+// ParseFault returns the SOAP Fault contained in body, or nil if body is not a fault envelope
+func ParseFault(body []byte) *Fault {
+	if len(body) == 0 {
+		return nil
+	}
+	var env EnvelopeRefResponse
+	if err := xml.Unmarshal(body, &env); err != nil {
+		return nil
+	}
+	return env.Body.Fault
+}
+
 // ============================================================================
 // Error Codes
 // ============================================================================
@@ -153,6 +255,10 @@ const (
 	CodeInvalidIssuer   = "0161" // Emisor no autorizado
 	CodeInvalidReceiver = "0162" // Receptor inválido
 
+	// Consulta DE codes
+	CodeConsultaDENoExiste   = "0420" // CDC inexistente en SIFEN
+	CodeConsultaDEEncontrado = "0422" // CDC encontrado
+
 	// Event codes
 	CodeEventSuccess  = "0510" // Evento procesado correctamente
 	CodeEventAccepted = "0520" // Evento aceptado
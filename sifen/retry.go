@@ -0,0 +1,141 @@
+package sifen
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/rodascaar/sifen-go-py/sifen/errors"
+)
+
+// Operacion identifica una operación del cliente SIFEN
+type Operacion string
+
+const (
+	OpConsultaRUC            Operacion = "ConsultaRUC"
+	OpRecepcionDE            Operacion = "RecepcionDE"
+	OpRecepcionLoteDE        Operacion = "RecepcionLoteDE"
+	OpConsultaDE             Operacion = "ConsultaDE"
+	OpConsultaLoteDE         Operacion = "ConsultaLoteDE"
+	OpEnviarEvento           Operacion = "EnviarEvento"
+	OpEnviarLoteDE           Operacion = "EnviarLoteDE"
+	OpConsultarResultadoLote Operacion = "ConsultarResultadoLote"
+)
+
+// RetryPolicy configura los reintentos automáticos ante errores recuperables
+type RetryPolicy struct {
+	// Cantidad máxima de intentos, incluyendo el primero (0 o 1 = sin reintentos)
+	MaxAttempts int
+	// Espera antes del primer reintento; se duplica en cada intento
+	BaseDelay time.Duration
+	// Tope de la espera entre intentos (0 = sin tope)
+	MaxDelay time.Duration
+	// Fracción aleatoria (0 a 1) que se suma o resta a cada espera
+	Jitter float64
+	// Operaciones que pueden reintentarse
+	Operations map[Operacion]bool
+}
+
+// DefaultRetryPolicy retorna la política por defecto: solo se reintentan las consultas.
+//
+// RecepcionDE puede habilitarse agregándola a Operations; en ese caso el cliente
+// consulta el CDC con ConsultaDE antes de cada reintento y solo reenvía si SIFEN
+// no lo recibió, para evitar rechazos por CDC duplicado (0160).
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    10 * time.Second,
+		Jitter:      0.2,
+		Operations: map[Operacion]bool{
+			OpConsultaRUC:            true,
+			OpConsultaDE:             true,
+			OpConsultaLoteDE:         true,
+			OpConsultarResultadoLote: true,
+		},
+	}
+}
+
+// Allows indica si la operación puede reintentarse
+func (p RetryPolicy) Allows(op Operacion) bool {
+	return p.MaxAttempts > 1 && p.Operations[op]
+}
+
+// Delay calcula la espera antes del reintento número attempt (1 = primer reintento)
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			break
+		}
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		delta := float64(delay) * p.Jitter * (2*rand.Float64() - 1)
+		delay += time.Duration(delta)
+	}
+	if delay < 0 {
+		return 0
+	}
+	return delay
+}
+
+// codigosPropios son los códigos que en una operación tienen un significado
+// propio y no indican una condición transitoria, aunque en las demás sí lo sean.
+// En ConsultaRUC, 0500 es "RUC inexistente" y 0501 "sin permiso para consultar".
+var codigosPropios = map[Operacion]map[string]bool{
+	OpConsultaRUC: {"0500": true, "0501": true},
+}
+
+// retryableCode indica si el código de respuesta de op puede reintentarse
+func retryableCode(op Operacion, code string) bool {
+	return errors.IsRecoverableCode(code) && !codigosPropios[op][code]
+}
+
+// attemptFunc ejecuta un intento y retorna el código de respuesta SIFEN (si lo hubo)
+type attemptFunc func() (code string, err error)
+
+// retryGate decide antes de cada reintento si debe reenviarse. Si resend es
+// false, withRetry termina retornando err (nil si el gate resolvió el resultado).
+type retryGate func(lastErr error) (resend bool, err error)
+
+// withRetry ejecuta attempt según la política configurada para op.
+// Se reintenta ante errores recuperables (errors.IsRecoverable) y ante
+// respuestas SIFEN con códigos recuperables para op (ej. 0500 servicio no
+// disponible, salvo en ConsultaRUC).
+func (c *SifenClient) withRetry(ctx context.Context, op Operacion, attempt attemptFunc, gate retryGate) error {
+	policy := c.config.RetryPolicy
+	maxAttempts := 1
+	if policy.Allows(op) {
+		maxAttempts = policy.MaxAttempts
+	}
+
+	var err error
+	for i := 1; ; i++ {
+		var code string
+		code, err = attempt()
+
+		retryable := (err != nil && errors.IsRecoverable(err)) ||
+			(err == nil && retryableCode(op, code))
+		if !retryable || i >= maxAttempts || ctx.Err() != nil {
+			return err
+		}
+
+		timer := time.NewTimer(policy.Delay(i))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		if gate != nil {
+			if resend, gateErr := gate(err); !resend {
+				return gateErr
+			}
+		}
+	}
+}
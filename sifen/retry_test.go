@@ -0,0 +1,137 @@
+package sifen
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rodascaar/sifen-go-py/sifen/models"
+)
+
+const soapEnvelopeFmt = `<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"><env:Body>%s</env:Body></env:Envelope>`
+
+func newTestClient(t *testing.T, handler http.Handler, policy RetryPolicy) *SifenClient {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	config := NewSifenConfig()
+	config.UrlBase = server.URL
+	config.UsarCertificadoCliente = false
+	config.RetryPolicy = policy
+
+	client, err := NewSifenClient(config)
+	if err != nil {
+		t.Fatalf("NewSifenClient() error = %v", err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+func fastPolicy(ops ...Operacion) RetryPolicy {
+	policy := RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		Operations:  map[Operacion]bool{},
+	}
+	for _, op := range ops {
+		policy.Operations[op] = true
+	}
+	return policy
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+
+	tests := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 300 * time.Millisecond},
+		{10, 300 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		if got := policy.Delay(tt.attempt); got != tt.expected {
+			t.Errorf("Delay(%d) = %v; want %v", tt.attempt, got, tt.expected)
+		}
+	}
+}
+
+func TestConsultaRUCRetriesRecoverableCode(t *testing.T) {
+	var calls int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code := "0502"
+		if atomic.AddInt32(&calls, 1) == 1 {
+			code = "0100"
+		}
+		fmt.Fprintf(w, soapEnvelopeFmt, "<rResEnviConsRuc><dCodRes>"+code+"</dCodRes></rResEnviConsRuc>")
+	})
+
+	client := newTestClient(t, handler, fastPolicy(OpConsultaRUC))
+
+	resp, err := client.ConsultaRUC("80069563")
+	if err != nil {
+		t.Fatalf("ConsultaRUC() error = %v", err)
+	}
+	if resp.DCodRes != "0502" {
+		t.Errorf("DCodRes = %s; want 0502", resp.DCodRes)
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d; want 2", calls)
+	}
+}
+
+func TestConsultaRUCNotRetriedWhenDisabled(t *testing.T) {
+	var calls int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		fmt.Fprintf(w, soapEnvelopeFmt, "<rResEnviConsRuc><dCodRes>0100</dCodRes></rResEnviConsRuc>")
+	})
+
+	client := newTestClient(t, handler, fastPolicy())
+
+	if _, err := client.ConsultaRUC("80069563"); err != nil {
+		t.Fatalf("ConsultaRUC() error = %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d; want 1", calls)
+	}
+}
+
+func TestRecepcionDEChecksCDCBeforeRetry(t *testing.T) {
+	cdc := strings.Repeat("1", 44)
+	var recibe, consulta int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "recibe.wsdl"):
+			atomic.AddInt32(&recibe, 1)
+			fmt.Fprintf(w, soapEnvelopeFmt, "<rRetEnviDe><dCodRes>0500</dCodRes></rRetEnviDe>")
+		case strings.HasSuffix(r.URL.Path, "consulta.wsdl"):
+			atomic.AddInt32(&consulta, 1)
+			fmt.Fprintf(w, soapEnvelopeFmt, "<rResEnviConsDe><dCodRes>0422</dCodRes><rProtDe><Id>"+cdc+
+				"</Id><dEstRes>Aprobado</dEstRes></rProtDe></rResEnviConsDe>")
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	client := newTestClient(t, handler, fastPolicy(OpRecepcionDE))
+
+	resp, err := client.RecepcionDE(models.NewDE(cdc))
+	if err != nil {
+		t.Fatalf("RecepcionDE() error = %v", err)
+	}
+	if !resp.IsApproved() {
+		t.Errorf("IsApproved() = false; want true (state taken from ConsultaDE)")
+	}
+	if recibe != 1 || consulta != 1 {
+		t.Errorf("recibe = %d, consulta = %d; want 1 and 1", recibe, consulta)
+	}
+}
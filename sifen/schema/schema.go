@@ -0,0 +1,155 @@
+// Package schema valida el XML de SIFEN contra los esquemas XSD v150, sin
+// herramientas externas ni red.
+//
+// Los esquemas embebidos son los de recepción de DE (siRecepDE_v150), de lotes
+// (siRecepLoteDE_v150) y de eventos (siRecepEvento_v150) con los tipos de
+// DE_v150 y DE_Types_v150. No son los archivos publicados por la SET en
+// https://ekuatia.set.gov.py/sifen/xsd/ sino transcripciones según el Manual
+// Técnico v150, con la misma estructura y cardinalidad. Para validar contra
+// los archivos oficiales, descárguelos y cárguelos con Load.
+//
+// El validador implementa el subconjunto de XSD que usan: orden y cardinalidad
+// de sequence/choice, atributos, patrones, longitudes, enumeraciones, rangos y
+// dígitos de los decimales. La firma (ds:Signature) no se valida contra el
+// esquema de XMLDSig.
+package schema
+
+import (
+	"embed"
+	"encoding/xml"
+	stderrors "errors"
+	"fmt"
+	"io/fs"
+	"sync"
+
+	"github.com/rodascaar/sifen-go-py/sifen/errors"
+	"github.com/rodascaar/sifen-go-py/sifen/models"
+)
+
+// XSD contiene los esquemas v150 embebidos, en el directorio xsd/, para
+// usarlos también con otras herramientas
+//
+//go:embed xsd/*.xsd
+var XSD embed.FS
+
+var embedded = sync.OnceValues(func() (*Schema, error) {
+	set, err := loadSchemas(XSD, "xsd")
+	if err != nil {
+		return nil, errors.NewInternalError("failed to load embedded XSD", err)
+	}
+	return &Schema{set: set}, nil
+})
+
+// Schema es un conjunto de esquemas cargado con Load
+type Schema struct {
+	set *schemaSet
+}
+
+// Load carga todos los .xsd del directorio dir de fsys, por ejemplo los
+// publicados por la SET, descargados en un directorio local:
+//
+//	s, err := schema.Load(os.DirFS("/ruta/xsd"), ".")
+//
+// El error es ErrEsquemaInvalido si algún archivo usa una construcción de XSD
+// que el validador no soporta o referencia un tipo no declarado.
+func Load(fsys fs.FS, dir string) (*Schema, error) {
+	set, err := loadSchemas(fsys, dir)
+	if err != nil {
+		return nil, errors.ErrEsquemaInvalido.WithCause(err)
+	}
+	return &Schema{set: set}, nil
+}
+
+// Error es un incumplimiento del esquema, ubicado con el XPath del elemento o
+// atributo (ej. "/rDE/DE/gDtipDE/gCamItem[2]/dCodInt")
+type Error struct {
+	Path    string
+	Message string
+}
+
+func (e Error) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// Errors es el resultado de una validación, en el orden del documento
+type Errors []Error
+
+// Err retorna nil si no hay errores y, si los hay, ErrEsquemaInvalido con
+// todos ellos como causa
+func (es Errors) Err() error {
+	if len(es) == 0 {
+		return nil
+	}
+	errs := make([]error, len(es))
+	for i, e := range es {
+		errs[i] = e
+	}
+	return errors.ErrEsquemaInvalido.WithCause(stderrors.Join(errs...)).WithContext("errores", len(es))
+}
+
+// Validate valida un documento XML cuya raíz sea un elemento global de los
+// esquemas embebidos: rDE, rEnviDe, rLoteDE, rEnvioLote o rEnviEventoDe. El
+// error es ErrEsquemaInvalido si el XML está mal formado o la raíz es
+// desconocida; los incumplimientos del esquema se retornan como Errors.
+func Validate(doc []byte) (Errors, error) {
+	s, err := embedded()
+	if err != nil {
+		return nil, err
+	}
+	return s.Validate(doc)
+}
+
+// ValidateDE serializa el DE como lo hace el cliente antes de firmarlo y lo
+// valida contra el esquema embebido del rDE. La firma y gCamFuFD, que el rDE
+// exige, se agregan al firmar, así que su falta no se informa; el DE firmado
+// se valida completo con Validate.
+func ValidateDE(de *models.DocumentoElectronico) (Errors, error) {
+	s, err := embedded()
+	if err != nil {
+		return nil, err
+	}
+	return s.ValidateDE(de)
+}
+
+// Validate valida un documento XML cuya raíz sea un elemento global de s,
+// con los mismos errores que la función Validate
+func (s *Schema) Validate(doc []byte) (Errors, error) {
+	set := s.set
+	root, err := parse(doc)
+	if err != nil {
+		return nil, errors.ErrEsquemaInvalido.WithCause(fmt.Errorf("XML mal formado: %w", err))
+	}
+	decl, ok := set.elements[root.name]
+	if !ok {
+		return nil, errors.ErrEsquemaInvalido.WithCause(fmt.Errorf("elemento raíz %s no declarado en los esquemas", formatName(root.name)))
+	}
+
+	v := &validator{set: set}
+	v.element(root, decl, "/"+root.name.Local)
+	return v.errors, nil
+}
+
+// ValidateDE serializa el DE como lo hace el cliente antes de firmarlo y lo
+// valida contra el rDE de s, sin exigir la firma ni gCamFuFD
+func (s *Schema) ValidateDE(de *models.DocumentoElectronico) (Errors, error) {
+	// gCamFuFD depende de la firma; el cliente lo descarta antes de firmar
+	unsigned := *de
+	unsigned.GCamFuFD = nil
+	doc, err := xml.Marshal(&unsigned)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal DE")
+	}
+	errs, err := s.Validate(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var out Errors
+	for _, e := range errs {
+		if e.Path == "/rDE" && e.Message == "falta el elemento Signature" {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
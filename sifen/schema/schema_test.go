@@ -0,0 +1,438 @@
+package schema
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	stderrors "errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rodascaar/sifen-go-py/sifen"
+	"github.com/rodascaar/sifen-go-py/sifen/builder"
+	"github.com/rodascaar/sifen-go-py/sifen/errors"
+	"github.com/rodascaar/sifen-go-py/sifen/events"
+	"github.com/rodascaar/sifen-go-py/sifen/models"
+	"github.com/rodascaar/sifen-go-py/sifen/sifentest"
+	"github.com/rodascaar/sifen-go-py/sifen/types"
+)
+
+func testConfig() *sifen.SifenConfig {
+	config := sifen.NewSifenConfig()
+	config.RucEmisor = "80069563"
+	config.DvEmisor = "1"
+	config.TipoContribuyente = types.TiTipCont_PersonaJuridica
+	return config
+}
+
+func testEmisor() models.TgEmis {
+	return models.TgEmis{
+		DNomEmi: "Empresa Test SA", DDirEmi: "Avda. Mcal. López", DNumCas: "1234",
+		CDepEmi: types.TDepartamento_Capital, CCiuEmi: 1, DDesCiuEmi: "ASUNCION (DISTRITO)",
+		DTelEmi: "021123456", DEmailE: "facturacion@empresa.com.py",
+		GActEcoList: []models.TgActEco{{CActEco: "46510", DDesActEco: "Comercio al por mayor de equipos informáticos"}},
+	}
+}
+
+func testReceptor() models.TgDatRec {
+	tipCont := types.TiTipCont_PersonaJuridica
+	return models.TgDatRec{
+		INatRec: types.TiNatRec_Contribuyente, ITiOpe: types.TiTiOpe_B2B, CPaisRec: types.PaisType_PRY,
+		ITiContRec: &tipCont, DRucRec: "80012345", DNomRec: "Cliente SA",
+	}
+}
+
+func testItem() models.TgCamItem {
+	return models.TgCamItem{
+		DCodInt: "001", DDesProSer: "Producto", CUniMed: types.TcUniMed_Unidad, DCantProSer: types.DecimalFromInt(3),
+		GValorItem: models.TgValorItem{DPUniProSer: types.DecimalFromInt(110000), DTotBruOpeItem: types.DecimalFromInt(330000), GValorRestaItem: models.TgValorRestaItem{DTotOpeItem: types.DecimalFromInt(330000)}},
+		GCamIVA:    &models.TgCamIVA{IAfecIVA: types.TiAfecIVA_GravadoIVA, DPropIVA: types.DecimalFromInt(100), DTasaIVA: types.DecimalFromInt(10), DBasGravIVA: types.DecimalFromInt(300000), DLiqIVAItem: types.DecimalFromInt(30000)},
+	}
+}
+
+func testTotales() models.TgTotSub {
+	return models.TgTotSub{DSub10: types.DecimalFromInt(330000).Ptr(), DTotOpe: types.DecimalFromInt(330000), DTotGralOpe: types.DecimalFromInt(330000), DIVA10: types.DecimalFromInt(30000).Ptr(), DTotIVA: types.DecimalFromInt(30000).Ptr(), DBaseGrav10: types.DecimalFromInt(300000).Ptr(), DTBasGraIVA: types.DecimalFromInt(300000).Ptr()}
+}
+
+func testAsociado() models.TgCamDEAsoc {
+	return models.TgCamDEAsoc{ITipDocAso: types.TiTipDocAso_Electronico, DCdCDERef: "01800695631001001000000612024123017595714694"}
+}
+
+func build(t *testing.T, b *builder.Builder) *models.DocumentoElectronico {
+	t.Helper()
+	de, err := b.DesdeConfig(testConfig()).Emisor(testEmisor()).
+		Timbrado(12345678, "2024-01-01").Numero("001", "001", "0000061").
+		Fecha(time.Date(2024, 12, 30, 17, 59, 57, 0, time.Local)).Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	return de
+}
+
+func documentos(t *testing.T) map[string]*models.DocumentoElectronico {
+	efectivo := models.TgPaConEIni{ITiPago: types.TiTipPago_Efectivo, DMonTiPag: types.DecimalFromInt(330000), CMoneOpe: types.CMondT_PYG}
+	constancia := types.TdTipCons_ConstanciaMicroproductores
+	numCons := int64(12345678901)
+
+	item := testItem()
+	item.GCamIVA = nil
+	item.GValorItem = models.TgValorItem{}
+
+	return map[string]*models.DocumentoElectronico{
+		"factura": build(t, builder.NewFactura().Receptor(testReceptor()).Contado(efectivo).Item(testItem()).Totales(testTotales())),
+		"autofactura": build(t, builder.NewAutofactura(models.TgCamAE{
+			INatVen: types.TiNatVendedorAF_NoContribuyente, ITipIDVen: types.TTipDocRec_CedulaParaguaya, DNumIDVen: "1234567",
+			DNomVen: "Juan Pérez", DDirVen: "Calle 1", DNumCasVen: 10, CDepVen: types.TDepartamento_Central, CCiuVen: 1, DDesCiuVen: "LUQUE",
+			DDirProv: "Calle 2", CDepProv: types.TDepartamento_Central, CCiuProv: 1, DDesCiuProv: "LUQUE",
+		}).Receptor(testReceptor()).Contado(efectivo).Item(testItem()).Totales(testTotales()).
+			DocumentoAsociado(models.TgCamDEAsoc{ITipDocAso: types.TiTipDocAso_ConstanciaElectronica, ITipCons: &constancia, DNumCons: &numCons})),
+		"nota de crédito": build(t, builder.NewNotaCredito(types.TiMotEmiNC_Devolucion).Receptor(testReceptor()).Item(testItem()).CalcularTotales(models.TotalsOptions{Redondeo: 50}).DocumentoAsociado(testAsociado())),
+		"nota de débito":  build(t, builder.NewNotaDebito(types.TiMotEmiNC_AjustePrecio).Receptor(testReceptor()).Item(testItem()).Totales(testTotales()).DocumentoAsociado(testAsociado())),
+		"nota de remisión": build(t, builder.NewNotaRemision(types.TiMotEmiNR_TrasladoVentas, types.TiRespFlete_EmisorFactura).Receptor(testReceptor()).Item(item).
+			Transporte(models.TgTransp{
+				ITipTrans: types.TiTipoTransporte_Propio, IModTrans: types.TiModalidadTransporte_Terrestre, IRepFlete: types.TiRespFlete_EmisorFactura,
+				DInIniTras: "2024-12-31",
+				GSalida:    &models.TgCamSal{DDirLoc: "Depósito central", DNumCas: "100", CDep: types.TDepartamento_Capital, CCiu: 1, DDesCiu: "ASUNCION (DISTRITO)"},
+				GEntrega:   &models.TgCamEnt{DDirLoc: "Sucursal Luque", DNumCas: "200", CDep: types.TDepartamento_Central, CCiu: 1, DDesCiu: "LUQUE"},
+				GVehiculo:  &models.TgVehiculo{DTipVeh: "CAMION", DMarca: "VOLVO", DTipIdent: 2, DNumMat: "ABC123"},
+				GTransportista: &models.TgTransportista{
+					IContTrans: types.TiNatRec_Contribuyente, DNomTrans: "Transportes SA", DRucTrans: "80011111", DDVTrans: 5,
+					DNumIDChof: "2345678", DNomChof: "Pedro Gómez",
+				},
+			})),
+	}
+}
+
+func TestDocumentosValidos(t *testing.T) {
+	for name, de := range documentos(t) {
+		errs, err := ValidateDE(de)
+		if err != nil {
+			t.Fatalf("%s: ValidateDE() error = %v", name, err)
+		}
+		if len(errs) > 0 {
+			t.Errorf("%s: ValidateDE() = %v", name, errs)
+		}
+	}
+	for name, doc := range firmados(t) {
+		if errs, err := Validate(doc); err != nil || len(errs) > 0 {
+			t.Errorf("%s firmado: Validate() = %v, %v", name, errs, err)
+		}
+	}
+}
+
+// solicitudes guarda el contenido del Body de la última solicitud enviada en
+// cada operación
+type solicitudes map[sifen.Operacion][]byte
+
+func (s solicitudes) Record(rec *sifen.AuditRecord) error {
+	var env struct {
+		Body struct {
+			Inner []byte `xml:",innerxml"`
+		} `xml:"Body"`
+	}
+	if err := xml.Unmarshal(rec.Request, &env); err != nil {
+		return err
+	}
+	s[rec.Operation] = env.Body.Inner
+	return nil
+}
+
+// clienteFirmante retorna un cliente que firma con un certificado de prueba
+// contra un sifentest.Server, y las solicitudes que envía
+func clienteFirmante(t *testing.T) (*sifen.SifenClient, solicitudes) {
+	t.Helper()
+	srv := sifentest.NewServer(sifentest.Config{})
+	t.Cleanup(srv.Close)
+	signer, err := sifentest.NewSoftwareSigner("80069563-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent := solicitudes{}
+	config := srv.SifenConfig()
+	config.RucEmisor = "80069563-1"
+	config.ClaveFirma = sifen.NewDigestCryptoSigner(signer)
+	config.CertificadosFirma = signer.Certificates()
+	config.AuditSink = sent
+	client, err := sifen.NewSifenClient(config)
+	if err != nil {
+		t.Fatalf("NewSifenClient() error = %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client, sent
+}
+
+// firmados retorna el rDE firmado, con gCamFuFD, de cada tipo de documento
+func firmados(t *testing.T) map[string][]byte {
+	t.Helper()
+	client, _ := clienteFirmante(t)
+	out := map[string][]byte{}
+	for name, de := range documentos(t) {
+		signed, err := client.FirmarDE(de)
+		if err != nil {
+			t.Fatalf("%s: FirmarDE() error = %v", name, err)
+		}
+		out[name] = signed
+	}
+	return out
+}
+
+// descomprimirLote retorna el rLoteDE del .zip en Base64 de un xDE
+func descomprimirLote(t *testing.T, content string) []byte {
+	t.Helper()
+	data, _ := base64.StdEncoding.DecodeString(content)
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := reader.File[0].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	lote, _ := io.ReadAll(f)
+	return lote
+}
+
+// loteFirmado retorna el XML del lote con la factura firmada
+func loteFirmado(t *testing.T) []byte {
+	t.Helper()
+	client, _ := clienteFirmante(t)
+	content, err := client.CrearLoteDE(sifen.LoteParams{
+		Documentos:    []*models.DocumentoElectronico{documentos(t)["factura"]},
+		TipoDocumento: types.TTiDE_FacturaElectronica,
+	})
+	if err != nil {
+		t.Fatalf("CrearLoteDE() error = %v", err)
+	}
+	return descomprimirLote(t, content)
+}
+
+// lotesEnviados retorna el rEnvioLote que envían EnviarLoteDE y
+// RecepcionLoteDE, y el rLoteDE comprimido en cada uno
+func lotesEnviados(t *testing.T) map[string][]byte {
+	t.Helper()
+	client, sent := clienteFirmante(t)
+	docs := documentos(t)
+	if _, err := client.EnviarLoteDE(sifen.LoteParams{
+		Documentos:    []*models.DocumentoElectronico{docs["factura"]},
+		TipoDocumento: types.TTiDE_FacturaElectronica,
+	}); err != nil {
+		t.Fatalf("EnviarLoteDE() error = %v", err)
+	}
+	if _, err := client.RecepcionLoteDE([]*models.DocumentoElectronico{docs["nota de crédito"]}); err != nil {
+		t.Fatalf("RecepcionLoteDE() error = %v", err)
+	}
+
+	out := map[string][]byte{}
+	for _, op := range []sifen.Operacion{sifen.OpEnviarLoteDE, sifen.OpRecepcionLoteDE} {
+		envio := sent[op]
+		var req struct {
+			XDE string `xml:"xDE"`
+		}
+		if err := xml.Unmarshal(envio, &req); err != nil {
+			t.Fatal(err)
+		}
+		out[string(op)+" rEnvioLote"] = envio
+		out[string(op)+" rLoteDE"] = descomprimirLote(t, req.XDE)
+	}
+	return out
+}
+
+func TestLoteFirmadoValido(t *testing.T) {
+	for name, doc := range lotesEnviados(t) {
+		if errs, err := Validate(doc); err != nil || len(errs) > 0 {
+			t.Errorf("%s: Validate() = %v, %v", name, errs, err)
+		}
+	}
+}
+
+// eventosEnviados retorna el rEnviEventoDe de cada tipo de evento
+func eventosEnviados(t *testing.T) map[string][]byte {
+	t.Helper()
+	client, sent := clienteFirmante(t)
+	cdc := documentos(t)["factura"].DE.Id
+	receptor := events.NewEventBuilder(1)
+
+	desconocimiento, err := receptor.BuildDesconocimiento(events.EventoDesconocimientoData{
+		CDC: cdc, TipoReceptor: types.TiNatRec_Contribuyente, Nombre: "Cliente SA", RUC: "80012345", DV: "7",
+		Motivo: "No corresponde a una compra",
+	})
+	if err != nil {
+		t.Fatalf("BuildDesconocimiento() error = %v", err)
+	}
+	notificacion, err := receptor.BuildNotificacion(events.EventoNotificacionData{
+		CDC: cdc, TipoReceptor: types.TiNatRec_NoContribuyente, Nombre: "Juan Pérez",
+		TipoDocumento: types.TTipDocRec_CedulaParaguaya, NumeroDoc: "1234567", TotalPYG: types.DecimalFromInt(330000),
+	})
+	if err != nil {
+		t.Fatalf("BuildNotificacion() error = %v", err)
+	}
+
+	enviar := map[string]func() error{
+		"cancelación": func() error {
+			_, err := client.CancelarDE(cdc, "Error en los datos del receptor")
+			return err
+		},
+		"inutilización": func() error {
+			_, err := client.InutilizarNumeracion(events.EventoInutilizacionData{
+				Timbrado: 12345678, TipoDocumento: types.TTiDE_FacturaElectronica,
+				Establecimiento: "001", Punto: "001", Desde: 100, Hasta: 110, Motivo: "Saltos de numeración",
+			})
+			return err
+		},
+		"conformidad": func() error {
+			_, err := client.ConfirmarRecepcion(events.EventoConformidadData{CDC: cdc, TipoConformidad: types.TiTipoConformidad_Total})
+			return err
+		},
+		"disconformidad": func() error {
+			_, err := client.ReportarDisconformidad(cdc, "Mercadería incompleta")
+			return err
+		},
+		"desconocimiento": func() error {
+			_, err := client.EnviarEvento(desconocimiento)
+			return err
+		},
+		"notificación": func() error {
+			_, err := client.EnviarEvento(notificacion)
+			return err
+		},
+	}
+	out := map[string][]byte{}
+	for name, send := range enviar {
+		if err := send(); err != nil {
+			t.Fatalf("%s: error = %v", name, err)
+		}
+		out[name] = sent[sifen.OpEnviarEvento]
+	}
+	return out
+}
+
+func TestEventosValidos(t *testing.T) {
+	for name, doc := range eventosEnviados(t) {
+		if errs, err := Validate(doc); err != nil || len(errs) > 0 {
+			t.Errorf("%s: Validate(rEnviEventoDe) = %v, %v", name, errs, err)
+		}
+	}
+}
+
+func TestValidateErrors(t *testing.T) {
+	valid := string(firmados(t)["factura"])
+
+	tests := []struct {
+		name     string
+		old, new string
+		want     Error
+	}{
+		{"orden", "<dEst>001</dEst><dPunExp>001</dPunExp>", "<dPunExp>001</dPunExp><dEst>001</dEst>",
+			Error{"/rDE/DE/gTimb/dPunExp", "elemento dPunExp inesperado; se esperaba dEst"}},
+		{"elemento faltante", "<dNumDoc>0000061</dNumDoc>", "",
+			Error{"/rDE/DE/gTimb/dFeIniT", "elemento dFeIniT inesperado; se esperaba dNumDoc"}},
+		{"elemento faltante al final", "<dTelEmi>021123456</dTelEmi><dEmailE>facturacion@empresa.com.py</dEmailE>", "",
+			Error{"/rDE/DE/gDatGralOpe/gEmis/gActEco", "elemento gActEco inesperado; se esperaba dTelEmi"}},
+		{"patrón", "<dEst>001</dEst>", "<dEst>1</dEst>",
+			Error{"/rDE/DE/gTimb/dEst", `el valor "1" no cumple el patrón [0-9]{3}`}},
+		{"longitud", "<dNomRec>Cliente SA</dNomRec>", "<dNomRec>CSA</dNomRec>",
+			Error{"/rDE/DE/gDatGralOpe/gDatRec/dNomRec", `el valor "CSA" tiene longitud 3; mínimo 4`}},
+		{"enumeración", "<iTiDE>1</iTiDE>", "<iTiDE>9</iTiDE>",
+			Error{"/rDE/DE/gTimb/iTiDE", `el valor "9" no es uno de los permitidos (1, 2, 3, 4, 5, 6, 7, 8)`}},
+		{"decimal con exponente", "<dTotOpe>330000</dTotOpe>", "<dTotOpe>3.3e+05</dTotOpe>",
+			Error{"/rDE/DE/gTotSub/dTotOpe", `el valor "3.3e+05" no es un xs:decimal válido`}},
+		{"atributo no permitido", `<gTimb>`, `<gTimb version="1">`,
+			Error{"/rDE/DE/gTimb/@version", "atributo no permitido"}},
+		{"elemento no permitido", "</gTimb>", "<dOtro>1</dOtro></gTimb>",
+			Error{"/rDE/DE/gTimb/dOtro", "elemento dOtro no permitido en gTimb"}},
+	}
+
+	for _, tt := range tests {
+		if !strings.Contains(valid, tt.old) {
+			t.Fatalf("%s: %q not found in %s", tt.name, tt.old, valid)
+		}
+		errs, err := Validate([]byte(strings.Replace(valid, tt.old, tt.new, 1)))
+		if err != nil {
+			t.Fatalf("%s: Validate() error = %v", tt.name, err)
+		}
+		if len(errs) != 1 || errs[0] != tt.want {
+			t.Errorf("%s: Validate() = %v; want %v", tt.name, errs, tt.want)
+		}
+		if err := errs.Err(); !stderrors.Is(err, errors.ErrEsquemaInvalido) {
+			t.Errorf("%s: Err() = %v", tt.name, err)
+		}
+	}
+
+	// La firma y gCamFuFD son obligatorios en el rDE
+	sinQR := valid[:strings.Index(valid, "<gCamFuFD>")] + "</rDE>"
+	want := Error{"/rDE", "falta el elemento gCamFuFD"}
+	if errs, err := Validate([]byte(sinQR)); err != nil || len(errs) != 1 || errs[0] != want {
+		t.Errorf("Validate(sin gCamFuFD) = %v, %v; want %v", errs, err, want)
+	}
+}
+
+func TestValidateItemPath(t *testing.T) {
+	de := documentos(t)["factura"]
+	item := de.DE.GDtipDE.GCamItemList[0]
+	item.DCodInt = ""
+	de.DE.GDtipDE.GCamItemList = append(de.DE.GDtipDE.GCamItemList, item)
+
+	errs, err := ValidateDE(de)
+	want := Error{"/rDE/DE/gDtipDE/gCamItem[2]/dCodInt", `el valor "" tiene longitud 0; mínimo 1`}
+	if err != nil || len(errs) != 1 || errs[0] != want {
+		t.Errorf("ValidateDE() = %v, %v; want %v", errs, err, want)
+	}
+}
+
+func TestValidateDocumento(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+	}{
+		{"mal formado", `<rDE xmlns="http://ekuatia.set.gov.py/sifen/xsd">`},
+		{"sin espacio de nombres", `<rDE><dVerFor>150</dVerFor></rDE>`},
+	}
+	for _, tt := range tests {
+		if _, err := Validate([]byte(tt.doc)); !stderrors.Is(err, errors.ErrEsquemaInvalido) {
+			t.Errorf("%s: Validate() error = %v; want ErrEsquemaInvalido", tt.name, err)
+		}
+	}
+	if err := Errors(nil).Err(); err != nil {
+		t.Errorf("Err() = %v", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	s, err := Load(XSD, "xsd")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	lote := loteFirmado(t)
+	if errs, err := s.Validate(lote); err != nil || len(errs) > 0 {
+		t.Errorf("Validate(lote) = %v, %v", errs, err)
+	}
+	if _, err := Load(XSD, "."); !stderrors.Is(err, errors.ErrEsquemaInvalido) {
+		t.Errorf("Load(sin .xsd) error = %v; want ErrEsquemaInvalido", err)
+	}
+}
+
+// TestEsquemasOficiales valida cada tipo de documento firmado, los lotes y los
+// eventos contra los XSD publicados por la SET, descargados en el directorio
+// SIFEN_XSD_DIR
+func TestEsquemasOficiales(t *testing.T) {
+	dir := os.Getenv("SIFEN_XSD_DIR")
+	if dir == "" {
+		t.Skip("SIFEN_XSD_DIR no definido")
+	}
+	s, err := Load(os.DirFS(dir), ".")
+	if err != nil {
+		t.Fatalf("Load(%s) error = %v", dir, err)
+	}
+	for _, docs := range []map[string][]byte{firmados(t), lotesEnviados(t), eventosEnviados(t)} {
+		for name, doc := range docs {
+			if errs, err := s.Validate(doc); err != nil || len(errs) > 0 {
+				t.Errorf("%s: Validate() = %v, %v", name, errs, err)
+			}
+		}
+	}
+}
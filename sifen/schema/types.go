@@ -0,0 +1,194 @@
+package schema
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ============================================================================
+// Tipos simples: tipos base de XSD y facetas
+// ============================================================================
+
+type builtin struct {
+	numeric  bool
+	lexical  *regexp.Regexp
+	min, max string // rango de los enteros
+}
+
+var (
+	decimalRE  = regexp.MustCompile(`^[+-]?(?:[0-9]+(?:\.[0-9]*)?|\.[0-9]+)$`)
+	integerRE  = regexp.MustCompile(`^[+-]?[0-9]+$`)
+	dateRE     = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}(?:Z|[+-][0-9]{2}:[0-9]{2})?$`)
+	dateTimeRE = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}T[0-9]{2}:[0-9]{2}:[0-9]{2}(?:\.[0-9]+)?(?:Z|[+-][0-9]{2}:[0-9]{2})?$`)
+	booleanRE  = regexp.MustCompile(`^(?:true|false|1|0)$`)
+)
+
+// builtins son los tipos de XSD soportados como base de los tipos simples
+var builtins = map[string]builtin{
+	"string":             {},
+	"normalizedString":   {},
+	"token":              {},
+	"anyURI":             {},
+	"base64Binary":       {},
+	"boolean":            {lexical: booleanRE},
+	"date":               {lexical: dateRE},
+	"dateTime":           {lexical: dateTimeRE},
+	"decimal":            {numeric: true, lexical: decimalRE},
+	"integer":            {numeric: true, lexical: integerRE},
+	"nonNegativeInteger": {numeric: true, lexical: integerRE, min: "0"},
+	"positiveInteger":    {numeric: true, lexical: integerRE, min: "1"},
+	"long":               {numeric: true, lexical: integerRE, min: "-9223372036854775808", max: "9223372036854775807"},
+	"int":                {numeric: true, lexical: integerRE, min: "-2147483648", max: "2147483647"},
+	"short":              {numeric: true, lexical: integerRE, min: "-32768", max: "32767"},
+	"byte":               {numeric: true, lexical: integerRE, min: "-128", max: "127"},
+	"unsignedLong":       {numeric: true, lexical: integerRE, min: "0", max: "18446744073709551615"},
+	"unsignedInt":        {numeric: true, lexical: integerRE, min: "0", max: "4294967295"},
+	"unsignedShort":      {numeric: true, lexical: integerRE, min: "0", max: "65535"},
+	"unsignedByte":       {numeric: true, lexical: integerRE, min: "0", max: "255"},
+}
+
+// checkValue valida value contra un tipo simple, declarado (name) o anónimo
+// (inline), y retorna el motivo del rechazo o "" si es válido
+func (s *schemaSet) checkValue(value string, name xml.Name, inline *simpleType) string {
+	var steps []*simpleType
+	if inline != nil {
+		steps = append(steps, inline)
+		name = inline.base
+	}
+	for name.Space != xsdNS {
+		st, ok := s.types[name].(*simpleType)
+		if !ok {
+			return fmt.Sprintf("el tipo %s no es un tipo simple", name.Local)
+		}
+		steps = append(steps, st)
+		name = st.base
+	}
+	base := builtins[name.Local]
+
+	switch name.Local {
+	case "string":
+	case "normalizedString":
+		value = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(value)
+	default:
+		value = strings.Join(strings.Fields(value), " ")
+	}
+	if msg := checkBuiltin(name.Local, base, value); msg != "" {
+		return msg
+	}
+
+	// Las facetas de cada restricción de la cadena se aplican todas
+	for i := len(steps) - 1; i >= 0; i-- {
+		if msg := steps[i].check(name.Local, base, value); msg != "" {
+			return msg
+		}
+	}
+	return ""
+}
+
+func checkBuiltin(name string, b builtin, value string) string {
+	if b.lexical != nil && !b.lexical.MatchString(value) {
+		return fmt.Sprintf("el valor %q no es un xs:%s válido", value, name)
+	}
+	switch name {
+	case "date":
+		if _, err := time.Parse("2006-01-02", value[:10]); err != nil {
+			return fmt.Sprintf("el valor %q no es una fecha válida", value)
+		}
+	case "dateTime":
+		if _, err := time.Parse("2006-01-02T15:04:05", value[:19]); err != nil {
+			return fmt.Sprintf("el valor %q no es una fecha y hora válida", value)
+		}
+	case "base64Binary":
+		if _, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value), "")); err != nil {
+			return fmt.Sprintf("el valor no es un xs:base64Binary válido: %v", err)
+		}
+	}
+	if b.min != "" && compare(value, b.min) < 0 || b.max != "" && compare(value, b.max) > 0 {
+		return fmt.Sprintf("el valor %s está fuera del rango de xs:%s", value, name)
+	}
+	return ""
+}
+
+func (st *simpleType) check(baseName string, base builtin, value string) string {
+	if st.pattern != nil && !st.pattern.MatchString(value) {
+		return fmt.Sprintf("el valor %q no cumple el patrón %s", value, st.patternSrc)
+	}
+
+	if len(st.enums) > 0 {
+		found := false
+		for _, e := range st.enums {
+			if e == value || base.numeric && compare(e, value) == 0 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Sprintf("el valor %q no es uno de los permitidos (%s)", value, strings.Join(st.enums, ", "))
+		}
+	}
+
+	if !base.numeric && base.lexical == nil {
+		n := utf8.RuneCountInString(value)
+		if baseName == "base64Binary" {
+			data, _ := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value), ""))
+			n = len(data)
+		}
+		switch {
+		case st.length >= 0 && n != st.length:
+			return fmt.Sprintf("el valor %q tiene longitud %d; se requiere %d", value, n, st.length)
+		case st.minLength >= 0 && n < st.minLength:
+			return fmt.Sprintf("el valor %q tiene longitud %d; mínimo %d", value, n, st.minLength)
+		case st.maxLength >= 0 && n > st.maxLength:
+			return fmt.Sprintf("el valor %q tiene longitud %d; máximo %d", value, n, st.maxLength)
+		}
+	}
+
+	if !base.numeric {
+		return ""
+	}
+	switch {
+	case st.minInclusive != "" && compare(value, st.minInclusive) < 0:
+		return fmt.Sprintf("el valor %s es menor que el mínimo %s", value, st.minInclusive)
+	case st.maxInclusive != "" && compare(value, st.maxInclusive) > 0:
+		return fmt.Sprintf("el valor %s es mayor que el máximo %s", value, st.maxInclusive)
+	case st.minExclusive != "" && compare(value, st.minExclusive) <= 0:
+		return fmt.Sprintf("el valor %s debe ser mayor que %s", value, st.minExclusive)
+	case st.maxExclusive != "" && compare(value, st.maxExclusive) >= 0:
+		return fmt.Sprintf("el valor %s debe ser menor que %s", value, st.maxExclusive)
+	}
+
+	total, fraction := digits(value)
+	switch {
+	case st.totalDigits >= 0 && total > st.totalDigits:
+		return fmt.Sprintf("el valor %s tiene %d dígitos; máximo %d", value, total, st.totalDigits)
+	case st.fractionDigits >= 0 && fraction > st.fractionDigits:
+		return fmt.Sprintf("el valor %s tiene %d decimales; máximo %d", value, fraction, st.fractionDigits)
+	}
+	return ""
+}
+
+// compare compara dos números decimales sin pérdida de precisión
+func compare(a, b string) int {
+	x, okA := new(big.Rat).SetString(a)
+	y, okB := new(big.Rat).SetString(b)
+	if !okA || !okB {
+		return strings.Compare(a, b)
+	}
+	return x.Cmp(y)
+}
+
+// digits cuenta los dígitos significativos totales y fraccionarios de un
+// decimal, sin ceros a la izquierda ni a la derecha
+func digits(value string) (total, fraction int) {
+	value = strings.TrimLeft(value, "+-")
+	integer, frac, _ := strings.Cut(value, ".")
+	integer = strings.TrimLeft(integer, "0")
+	frac = strings.TrimRight(frac, "0")
+	return len(integer) + len(frac), len(frac)
+}
@@ -0,0 +1,228 @@
+package schema
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// ============================================================================
+// Validación de un documento contra los esquemas
+// ============================================================================
+
+type validator struct {
+	set    *schemaSet
+	errors Errors
+}
+
+func (v *validator) add(path, format string, args ...any) {
+	v.errors = append(v.errors, Error{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) element(n *node, decl *element, path string) {
+	if decl.ref {
+		global, ok := v.set.elements[decl.name]
+		if !ok {
+			// Espacio de nombres sin esquema cargado (ds:Signature): no se valida
+			return
+		}
+		decl = global
+	}
+
+	ct := decl.complex
+	if ct == nil && decl.simple == nil {
+		ct, _ = v.set.types[decl.typ].(*complexType)
+	}
+	if ct != nil {
+		v.complex(n, ct, path)
+		return
+	}
+
+	v.attributes(n, nil, path)
+	if len(n.children) > 0 {
+		v.add(path, "el elemento %s no admite elementos hijos", n.name.Local)
+		return
+	}
+	if msg := v.set.checkValue(n.text, decl.typ, decl.simple); msg != "" {
+		v.add(path, "%s", msg)
+	}
+}
+
+func (v *validator) complex(n *node, ct *complexType, path string) {
+	v.attributes(n, ct.attrs, path)
+	if strings.TrimSpace(n.text) != "" {
+		v.add(path, "el elemento %s no admite texto", n.name.Local)
+	}
+
+	kids := n.children
+	paths := childPaths(kids, path)
+	m := &matcher{kids: kids, assigned: make([]*element, len(kids))}
+	end, ok := 0, true
+	if ct.content != nil {
+		end, ok = m.repeat(ct.content, 0)
+	}
+
+	switch {
+	case ok && end == len(kids):
+	case m.far < len(kids) && m.far >= end && len(m.expected) > 0:
+		v.add(paths[m.far], "elemento %s inesperado; se esperaba %s", kids[m.far].name.Local, strings.Join(m.expected, ", "))
+	case ok:
+		v.add(paths[end], "elemento %s no permitido en %s", kids[end].name.Local, n.name.Local)
+	default:
+		v.add(path, "falta el elemento %s", strings.Join(m.expected, ", "))
+	}
+
+	for i, k := range kids {
+		if decl := m.assigned[i]; decl != nil {
+			v.element(k, decl, paths[i])
+		}
+	}
+}
+
+func (v *validator) attributes(n *node, decls []attribute, path string) {
+	seen := map[string]bool{}
+	for _, a := range n.attrs {
+		if isNamespaceDecl(a) || a.Name.Space == xsiNS {
+			continue
+		}
+		var decl *attribute
+		for i := range decls {
+			if a.Name.Space == "" && decls[i].name == a.Name.Local {
+				decl = &decls[i]
+			}
+		}
+		if decl == nil {
+			v.add(path+"/@"+a.Name.Local, "atributo no permitido")
+			continue
+		}
+		seen[decl.name] = true
+		if msg := v.set.checkValue(a.Value, decl.typ, decl.simple); msg != "" {
+			v.add(path+"/@"+a.Name.Local, "%s", msg)
+		}
+	}
+	for _, d := range decls {
+		if d.required && !seen[d.name] {
+			v.add(path, "falta el atributo %s", d.name)
+		}
+	}
+}
+
+// childPaths arma el XPath de cada hijo, con índice si hay hermanos con el
+// mismo nombre
+func childPaths(kids []*node, path string) []string {
+	count := map[string]int{}
+	for _, k := range kids {
+		count[k.name.Local]++
+	}
+	seen := map[string]int{}
+	paths := make([]string, len(kids))
+	for i, k := range kids {
+		name := k.name.Local
+		seen[name]++
+		paths[i] = path + "/" + name
+		if count[name] > 1 {
+			paths[i] += fmt.Sprintf("[%d]", seen[name])
+		}
+	}
+	return paths
+}
+
+// matcher recorre los hijos de un elemento contra el modelo de contenido.
+// Los esquemas de SIFEN son deterministas, así que alcanza con avanzar sin
+// retroceder; far y expected registran el punto más lejano donde falló un
+// elemento, que es lo que se informa.
+type matcher struct {
+	kids     []*node
+	assigned []*element
+	far      int
+	expected []string
+}
+
+func (m *matcher) record(i int, name string) {
+	if i > m.far {
+		m.far, m.expected = i, nil
+	}
+	if i == m.far {
+		for _, e := range m.expected {
+			if e == name {
+				return
+			}
+		}
+		m.expected = append(m.expected, name)
+	}
+}
+
+// repeat aplica la partícula tantas veces como su cardinalidad lo permita
+func (m *matcher) repeat(p *particle, i int) (int, bool) {
+	count := 0
+	for p.max < 0 || count < p.max {
+		j, ok := m.once(p, i)
+		if !ok || j == i {
+			break
+		}
+		i = j
+		count++
+	}
+	if count < p.min && !emptiable(p) {
+		return i, false
+	}
+	return i, true
+}
+
+func (m *matcher) once(p *particle, i int) (int, bool) {
+	if e := p.element; e != nil {
+		if i < len(m.kids) && m.kids[i].name == e.name {
+			m.assigned[i] = e
+			return i + 1, true
+		}
+		m.record(i, e.name.Local)
+		return i, false
+	}
+
+	if p.choice {
+		for _, c := range p.children {
+			if j, ok := m.repeat(c, i); ok && j > i {
+				return j, true
+			}
+		}
+		for _, c := range p.children {
+			if c.min == 0 || emptiable(c) {
+				return i, true
+			}
+		}
+		return i, false
+	}
+
+	j := i
+	for _, c := range p.children {
+		var ok bool
+		if j, ok = m.repeat(c, j); !ok {
+			return i, false
+		}
+	}
+	return j, true
+}
+
+// emptiable indica si el contenido de la partícula puede no tener elementos
+func emptiable(p *particle) bool {
+	if p.element != nil {
+		return false
+	}
+	for _, c := range p.children {
+		ok := c.min == 0 || emptiable(c)
+		if p.choice && ok {
+			return true
+		}
+		if !p.choice && !ok {
+			return false
+		}
+	}
+	return !p.choice
+}
+
+func formatName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local + " (sin espacio de nombres)"
+	}
+	return "{" + name.Space + "}" + name.Local
+}
@@ -0,0 +1,525 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// Lectura de XML y de los esquemas XSD
+// ============================================================================
+
+const (
+	xsdNS = "http://www.w3.org/2001/XMLSchema"
+	xsiNS = "http://www.w3.org/2001/XMLSchema-instance"
+)
+
+// node es un elemento XML con los prefijos en alcance, necesarios para
+// resolver los QName de los atributos type, ref y base de un XSD
+type node struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	children []*node
+	text     string
+	ns       map[string]string
+}
+
+func (n *node) attr(local string) string {
+	for _, a := range n.attrs {
+		if a.Name.Space == "" && a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// qname resuelve un valor "prefijo:nombre" con los prefijos del elemento
+func (n *node) qname(value string) xml.Name {
+	prefix, local, ok := strings.Cut(value, ":")
+	if !ok {
+		prefix, local = "", value
+	}
+	return xml.Name{Space: n.ns[prefix], Local: local}
+}
+
+func isNamespaceDecl(a xml.Attr) bool {
+	return a.Name.Space == "xmlns" || (a.Name.Space == "" && a.Name.Local == "xmlns")
+}
+
+// parse lee un documento XML completo como un árbol de nodos
+func parse(data []byte) (*node, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var root *node
+	var stack []*node
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &node{name: t.Name, attrs: t.Attr, ns: map[string]string{}}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				n.ns = parent.ns
+				parent.children = append(parent.children, n)
+			} else if root != nil {
+				return nil, fmt.Errorf("más de un elemento raíz")
+			} else {
+				root = n
+			}
+			for _, a := range t.Attr {
+				if !isNamespaceDecl(a) {
+					continue
+				}
+				ns := make(map[string]string, len(n.ns)+1)
+				for k, v := range n.ns {
+					ns[k] = v
+				}
+				if a.Name.Space == "xmlns" {
+					ns[a.Name.Local] = a.Value
+				} else {
+					ns[""] = a.Value
+				}
+				n.ns = ns
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("documento vacío")
+	}
+	return root, nil
+}
+
+// schemaSet son las declaraciones globales de todos los esquemas cargados
+type schemaSet struct {
+	elements   map[xml.Name]*element
+	types      map[xml.Name]any // *simpleType o *complexType
+	namespaces map[string]bool
+}
+
+type element struct {
+	name    xml.Name
+	ref     bool // name es un elemento global de otro esquema o de este
+	typ     xml.Name
+	simple  *simpleType
+	complex *complexType
+}
+
+// particle es un elemento, sequence o choice con su cardinalidad
+type particle struct {
+	element  *element
+	choice   bool
+	children []*particle
+	min, max int // max < 0: unbounded
+}
+
+type complexType struct {
+	content *particle // nil: sin elementos hijos
+	attrs   []attribute
+}
+
+type attribute struct {
+	name     string
+	typ      xml.Name
+	simple   *simpleType
+	required bool
+}
+
+// simpleType es una restricción de un tipo base con sus facetas
+type simpleType struct {
+	base           xml.Name
+	pattern        *regexp.Regexp
+	patternSrc     string
+	enums          []string
+	length         int
+	minLength      int
+	maxLength      int
+	minInclusive   string
+	maxInclusive   string
+	minExclusive   string
+	maxExclusive   string
+	totalDigits    int
+	fractionDigits int
+}
+
+// loadSchemas carga todos los .xsd de dir en un único conjunto. Los include
+// e import se resuelven porque todos los archivos se cargan juntos; los
+// espacios de nombres sin esquema (como el de la firma) no se validan.
+func loadSchemas(fsys fs.FS, dir string) (*schemaSet, error) {
+	set := &schemaSet{
+		elements:   map[xml.Name]*element{},
+		types:      map[xml.Name]any{},
+		namespaces: map[string]bool{},
+	}
+	files, err := fs.Glob(fsys, path.Join(dir, "*.xsd"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no hay archivos .xsd en %s", dir)
+	}
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		root, err := parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if err := set.add(root); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+	if err := set.check(); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+type loader struct {
+	set       *schemaSet
+	tns       string
+	qualified bool
+}
+
+func (s *schemaSet) add(root *node) error {
+	if root.name != (xml.Name{Space: xsdNS, Local: "schema"}) {
+		return fmt.Errorf("la raíz no es xs:schema")
+	}
+	l := &loader{set: s, tns: root.attr("targetNamespace"), qualified: root.attr("elementFormDefault") == "qualified"}
+	s.namespaces[l.tns] = true
+
+	for _, c := range root.children {
+		if c.name.Space != xsdNS {
+			return fmt.Errorf("elemento %s no soportado", c.name.Local)
+		}
+		switch c.name.Local {
+		case "include", "import", "annotation":
+		case "element":
+			e, err := l.element(c, true)
+			if err != nil {
+				return err
+			}
+			if _, dup := s.elements[e.name]; dup {
+				return fmt.Errorf("elemento %s declarado dos veces", e.name.Local)
+			}
+			s.elements[e.name] = e
+		case "simpleType", "complexType":
+			name := xml.Name{Space: l.tns, Local: c.attr("name")}
+			if _, dup := s.types[name]; dup {
+				return fmt.Errorf("tipo %s declarado dos veces", name.Local)
+			}
+			var t any
+			var err error
+			if c.name.Local == "simpleType" {
+				t, err = l.simpleType(c)
+			} else {
+				t, err = l.complexType(c)
+			}
+			if err != nil {
+				return fmt.Errorf("tipo %s: %w", name.Local, err)
+			}
+			s.types[name] = t
+		default:
+			return fmt.Errorf("xs:%s no soportado", c.name.Local)
+		}
+	}
+	return nil
+}
+
+func (l *loader) element(n *node, global bool) (*element, error) {
+	if ref := n.attr("ref"); ref != "" {
+		return &element{name: n.qname(ref), ref: true}, nil
+	}
+
+	e := &element{name: xml.Name{Local: n.attr("name")}}
+	if global || l.qualified {
+		e.name.Space = l.tns
+	}
+	if t := n.attr("type"); t != "" {
+		e.typ = n.qname(t)
+	}
+	for _, c := range n.children {
+		var err error
+		switch c.name.Local {
+		case "annotation":
+		case "simpleType":
+			e.simple, err = l.simpleType(c)
+		case "complexType":
+			e.complex, err = l.complexType(c)
+		default:
+			err = fmt.Errorf("xs:%s no soportado", c.name.Local)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("elemento %s: %w", e.name.Local, err)
+		}
+	}
+	if e.typ.Local == "" && e.simple == nil && e.complex == nil {
+		return nil, fmt.Errorf("elemento %s sin tipo", e.name.Local)
+	}
+	return e, nil
+}
+
+func (l *loader) particle(n *node) (*particle, error) {
+	p := &particle{min: 1, max: 1}
+	if v := n.attr("minOccurs"); v != "" {
+		min, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("minOccurs %q inválido", v)
+		}
+		p.min = min
+	}
+	switch v := n.attr("maxOccurs"); v {
+	case "":
+	case "unbounded":
+		p.max = -1
+	default:
+		max, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("maxOccurs %q inválido", v)
+		}
+		p.max = max
+	}
+
+	switch n.name.Local {
+	case "element":
+		e, err := l.element(n, false)
+		if err != nil {
+			return nil, err
+		}
+		p.element = e
+	case "sequence", "choice":
+		p.choice = n.name.Local == "choice"
+		for _, c := range n.children {
+			if c.name.Local == "annotation" {
+				continue
+			}
+			cp, err := l.particle(c)
+			if err != nil {
+				return nil, err
+			}
+			p.children = append(p.children, cp)
+		}
+	default:
+		return nil, fmt.Errorf("xs:%s no soportado", n.name.Local)
+	}
+	return p, nil
+}
+
+func (l *loader) complexType(n *node) (*complexType, error) {
+	ct := &complexType{}
+	for _, c := range n.children {
+		switch c.name.Local {
+		case "annotation":
+		case "sequence", "choice":
+			if ct.content != nil {
+				return nil, fmt.Errorf("más de un grupo de contenido")
+			}
+			p, err := l.particle(c)
+			if err != nil {
+				return nil, err
+			}
+			ct.content = p
+		case "attribute":
+			a := attribute{name: c.attr("name"), required: c.attr("use") == "required"}
+			if t := c.attr("type"); t != "" {
+				a.typ = c.qname(t)
+			}
+			for _, st := range c.children {
+				if st.name.Local != "simpleType" {
+					continue
+				}
+				s, err := l.simpleType(st)
+				if err != nil {
+					return nil, fmt.Errorf("atributo %s: %w", a.name, err)
+				}
+				a.simple = s
+			}
+			if a.typ.Local == "" && a.simple == nil {
+				return nil, fmt.Errorf("atributo %s sin tipo", a.name)
+			}
+			ct.attrs = append(ct.attrs, a)
+		default:
+			return nil, fmt.Errorf("xs:%s no soportado", c.name.Local)
+		}
+	}
+	return ct, nil
+}
+
+func (l *loader) simpleType(n *node) (*simpleType, error) {
+	var r *node
+	for _, c := range n.children {
+		switch c.name.Local {
+		case "annotation":
+		case "restriction":
+			r = c
+		default:
+			return nil, fmt.Errorf("xs:%s no soportado", c.name.Local)
+		}
+	}
+	if r == nil || r.attr("base") == "" {
+		return nil, fmt.Errorf("se requiere xs:restriction con base")
+	}
+
+	st := &simpleType{base: r.qname(r.attr("base")), length: -1, minLength: -1, maxLength: -1, totalDigits: -1, fractionDigits: -1}
+	var patterns []string
+	for _, f := range r.children {
+		value := f.attr("value")
+		var err error
+		switch f.name.Local {
+		case "annotation", "whiteSpace":
+		case "pattern":
+			patterns = append(patterns, value)
+		case "enumeration":
+			st.enums = append(st.enums, value)
+		case "length":
+			st.length, err = strconv.Atoi(value)
+		case "minLength":
+			st.minLength, err = strconv.Atoi(value)
+		case "maxLength":
+			st.maxLength, err = strconv.Atoi(value)
+		case "totalDigits":
+			st.totalDigits, err = strconv.Atoi(value)
+		case "fractionDigits":
+			st.fractionDigits, err = strconv.Atoi(value)
+		case "minInclusive":
+			st.minInclusive = value
+		case "maxInclusive":
+			st.maxInclusive = value
+		case "minExclusive":
+			st.minExclusive = value
+		case "maxExclusive":
+			st.maxExclusive = value
+		default:
+			err = fmt.Errorf("faceta xs:%s no soportada", f.name.Local)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Los patrones de una misma restricción son alternativas y cada uno
+	// debe cubrir el valor completo
+	if len(patterns) > 0 {
+		st.patternSrc = strings.Join(patterns, "|")
+		alts := make([]string, len(patterns))
+		for i, p := range patterns {
+			alts[i] = "(?:" + p + ")"
+		}
+		re, err := regexp.Compile("^(?:" + strings.Join(alts, "|") + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("patrón %q: %w", st.patternSrc, err)
+		}
+		st.pattern = re
+	}
+	return st, nil
+}
+
+// check verifica que todos los tipos y referencias usados estén declarados
+func (s *schemaSet) check() error {
+	var checkElement func(e *element) error
+	checkType := func(name xml.Name) error {
+		if name.Space == xsdNS {
+			if _, ok := builtins[name.Local]; !ok {
+				return fmt.Errorf("tipo xs:%s no soportado", name.Local)
+			}
+			return nil
+		}
+		if _, ok := s.types[name]; !ok {
+			return fmt.Errorf("tipo %s no declarado", name.Local)
+		}
+		return nil
+	}
+	checkSimple := func(st *simpleType) error {
+		if st == nil {
+			return nil
+		}
+		return checkType(st.base)
+	}
+	var checkParticle func(p *particle) error
+	checkComplex := func(ct *complexType) error {
+		if ct == nil {
+			return nil
+		}
+		for _, a := range ct.attrs {
+			if a.simple != nil {
+				if err := checkSimple(a.simple); err != nil {
+					return err
+				}
+			} else if err := checkType(a.typ); err != nil {
+				return err
+			}
+		}
+		if ct.content != nil {
+			return checkParticle(ct.content)
+		}
+		return nil
+	}
+	checkParticle = func(p *particle) error {
+		if p.element != nil {
+			return checkElement(p.element)
+		}
+		for _, c := range p.children {
+			if err := checkParticle(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	checkElement = func(e *element) error {
+		if e.ref {
+			if _, ok := s.elements[e.name]; !ok && s.namespaces[e.name.Space] {
+				return fmt.Errorf("elemento %s no declarado", e.name.Local)
+			}
+			return nil
+		}
+		if e.typ.Local != "" {
+			if err := checkType(e.typ); err != nil {
+				return fmt.Errorf("elemento %s: %w", e.name.Local, err)
+			}
+		}
+		if err := checkSimple(e.simple); err != nil {
+			return err
+		}
+		return checkComplex(e.complex)
+	}
+
+	for _, e := range s.elements {
+		if err := checkElement(e); err != nil {
+			return err
+		}
+	}
+	for _, t := range s.types {
+		var err error
+		switch t := t.(type) {
+		case *simpleType:
+			err = checkSimple(t)
+		case *complexType:
+			err = checkComplex(t)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
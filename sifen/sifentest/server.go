@@ -0,0 +1,596 @@
+// Package sifentest provee un simulador en proceso de los servicios web SIFEN
+// para pruebas sin acceso a sifen-test.set.gov.py.
+//
+// El simulador atiende los seis endpoints de sifen.NewSifenConfig, guarda en
+// memoria los DE recibidos, procesa los lotes en forma asíncrona con una demora
+// configurable y responde ConsultaDE y ConsultaRUC con datos sembrados.
+//
+//	srv := sifentest.NewServer(sifentest.Config{ProcessingDelay: time.Second})
+//	defer srv.Close()
+//	srv.RejectCDC(cdc, "0160", "CDC duplicado")
+//	client, _ := sifen.NewSifenClient(srv.SifenConfig())
+package sifentest
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rodascaar/sifen-go-py/sifen"
+	"github.com/rodascaar/sifen-go-py/sifen/events"
+	"github.com/rodascaar/sifen-go-py/sifen/models"
+	"github.com/rodascaar/sifen-go-py/sifen/request"
+	"github.com/rodascaar/sifen-go-py/sifen/response"
+)
+
+// ============================================================================
+// Códigos de Respuesta del Simulador
+// ============================================================================
+const (
+	CodeDEAprobado      = "0260" // Autorización del DE satisfactoria
+	CodeLoteRecibido    = "0300" // Lote recibido con éxito
+	CodeLoteInexistente = "0360" // Número de lote inexistente
+	CodeLoteEnProceso   = "0361" // Lote en procesamiento
+	CodeLoteConcluido   = "0362" // Procesamiento de lote concluido
+	CodeRUCInexistente  = "0500" // RUC inexistente
+	CodeRUCEncontrado   = "0502" // RUC encontrado
+	CodeEventoAprobado  = "0510" // Evento procesado correctamente
+	CodeEventoRechazado = "0530" // Evento rechazado
+
+	EstadoAprobado  = "Aprobado"
+	EstadoRechazado = "Rechazado"
+	EstadoCancelado = "Cancelado"
+)
+
+// ============================================================================
+// Configuración y Reglas
+// ============================================================================
+
+// Config configura el simulador
+type Config struct {
+	// Demora entre la recepción de un lote y el fin de su procesamiento
+	ProcessingDelay time.Duration
+	// Tiempo estimado informado al recibir un lote (dTmpLot, minutos)
+	TiempoEstimadoLote int32
+}
+
+// Rejection es el rechazo que una regla aplica a un DE
+type Rejection struct {
+	Code    string
+	Message string
+}
+
+// Rule decide si un DE recibido se rechaza; nil significa aprobado
+type Rule func(de *models.DocumentoElectronico) *Rejection
+
+// StoredDE es un DE recibido (o sembrado) por el simulador
+type StoredDE struct {
+	CDC          string
+	XML          []byte // rDE tal como fue recibido
+	Estado       string // Aprobado, Rechazado o Cancelado
+	Protocolo    string
+	Resultado    response.TgResProc
+	FechaProceso time.Time
+}
+
+type lote struct {
+	numero     string
+	recibido   time.Time
+	documentos []*models.DocumentoElectronico
+	rawDocs    [][]byte
+	resultados []response.TgResProcLote
+	procesado  bool
+}
+
+// ============================================================================
+// Server
+// ============================================================================
+
+// Server es un simulador SIFEN sobre httptest.Server
+type Server struct {
+	*httptest.Server
+
+	config Config
+	paths  *sifen.SifenConfig
+
+	mu        sync.Mutex
+	des       map[string]*StoredDE
+	rucs      map[string]response.TxContRuc
+	lotes     map[string]*lote
+	rules     []Rule
+	rejectCDC map[string]Rejection
+	seq       int64
+	now       func() time.Time
+}
+
+// NewServer inicia un simulador con los paths por defecto de sifen.NewSifenConfig
+func NewServer(config Config) *Server {
+	s := &Server{
+		config:    config,
+		paths:     sifen.NewSifenConfig(),
+		des:       make(map[string]*StoredDE),
+		rucs:      make(map[string]response.TxContRuc),
+		lotes:     make(map[string]*lote),
+		rejectCDC: make(map[string]Rejection),
+		now:       time.Now,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(s.paths.PathConsultaRUC, s.handle(s.consultaRUC))
+	mux.HandleFunc(s.paths.PathRecibe, s.handle(s.recibe))
+	mux.HandleFunc(s.paths.PathRecibeLote, s.handle(s.recibeLote))
+	mux.HandleFunc(s.paths.PathConsulta, s.handle(s.consultaDE))
+	mux.HandleFunc(s.paths.PathConsultaLote, s.handle(s.consultaLote))
+	mux.HandleFunc(s.paths.PathEvento, s.handle(s.evento))
+
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// SifenConfig retorna una configuración de cliente apuntando al simulador,
+// sin certificado cliente
+func (s *Server) SifenConfig() *sifen.SifenConfig {
+	config := sifen.NewSifenConfig()
+	config.UrlBase = s.URL
+	config.UsarCertificadoCliente = false
+	return config
+}
+
+// ============================================================================
+// Datos Sembrados y Reglas
+// ============================================================================
+
+// SeedRUC registra un contribuyente para ConsultaRUC
+func (s *Server) SeedRUC(ruc string, data response.TxContRuc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if data.DRUCCons == "" {
+		data.DRUCCons = ruc
+	}
+	s.rucs[ruc] = data
+}
+
+// SeedDE registra un DE como ya recibido con el estado indicado
+func (s *Server) SeedDE(cdc, estado string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.des[cdc] = &StoredDE{
+		CDC:          cdc,
+		Estado:       estado,
+		Protocolo:    s.nextProtocol(),
+		FechaProceso: s.now(),
+	}
+}
+
+// RejectCDC hace que el DE con ese CDC sea rechazado con el código indicado
+func (s *Server) RejectCDC(cdc, code, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rejectCDC[cdc] = Rejection{Code: code, Message: message}
+}
+
+// AddRule agrega una regla de rechazo evaluada para cada DE recibido
+func (s *Server) AddRule(rule Rule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules = append(s.rules, rule)
+}
+
+// DE retorna el DE almacenado para un CDC
+func (s *Server) DE(cdc string) (StoredDE, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.des[cdc]
+	if !ok {
+		return StoredDE{}, false
+	}
+	return *stored, true
+}
+
+// ============================================================================
+// Handlers
+// ============================================================================
+
+type soapRequestEnvelope struct {
+	Body struct {
+		Content []byte `xml:",innerxml"`
+	} `xml:"Body"`
+}
+
+type soapResponseEnvelope struct {
+	XMLName  xml.Name `xml:"env:Envelope"`
+	XmlnsEnv string   `xml:"xmlns:env,attr"`
+	Body     struct {
+		Content interface{}
+	} `xml:"env:Body"`
+}
+
+// handle decodifica el sobre SOAP y serializa la respuesta de fn
+func (s *Server) handle(fn func(body []byte) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		raw, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var env soapRequestEnvelope
+		if err := xml.Unmarshal(raw, &env); err != nil {
+			http.Error(w, "invalid soap envelope: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		content, err := fn(env.Body.Content)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		out := soapResponseEnvelope{XmlnsEnv: "http://www.w3.org/2003/05/soap-envelope"}
+		out.Body.Content = content
+		data, err := xml.Marshal(out)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/soap+xml; charset=utf-8")
+		w.Write(data)
+	}
+}
+
+type rResEnviConsRuc struct {
+	XMLName xml.Name `xml:"rResEnviConsRuc"`
+	response.RespuestaConsultaRUC
+}
+
+func (s *Server) consultaRUC(body []byte) (interface{}, error) {
+	var req request.REnviConsRUC
+	if err := xml.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, ok := s.rucs[req.DRUCCons]
+	if !ok {
+		return rResEnviConsRuc{RespuestaConsultaRUC: response.RespuestaConsultaRUC{
+			BaseResponse: response.BaseResponse{DCodRes: CodeRUCInexistente, DMsgRes: "RUC inexistente"},
+		}}, nil
+	}
+	return rResEnviConsRuc{RespuestaConsultaRUC: response.RespuestaConsultaRUC{
+		BaseResponse: response.BaseResponse{DCodRes: CodeRUCEncontrado, DMsgRes: "RUC encontrado"},
+		XContRUC:     data,
+	}}, nil
+}
+
+type rRetEnviDe struct {
+	XMLName xml.Name `xml:"rRetEnviDe"`
+	response.RespuestaRecepcionDE
+}
+
+func (s *Server) recibe(body []byte) (interface{}, error) {
+	var req request.REnviDe
+	if err := xml.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+
+	de, err := parseRDE(req.XDE.RawRDE)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := s.process(de, req.XDE.RawRDE)
+	return rRetEnviDe{RespuestaRecepcionDE: response.RespuestaRecepcionDE{
+		BaseResponse: response.BaseResponse{DCodRes: stored.Resultado.DCodRes, DMsgRes: stored.Resultado.DMsgRes},
+		RProtDe:      stored.protDe(),
+	}}, nil
+}
+
+type rRetEnviLoteDe struct {
+	XMLName xml.Name `xml:"rRetEnviLoteDe"`
+	response.RespuestaRecepcionLoteDE
+}
+
+func (s *Server) recibeLote(body []byte) (interface{}, error) {
+	var req request.REnvioLote
+	if err := xml.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+
+	rawDocs, err := unzipLote(strings.TrimSpace(req.XDE))
+	if err != nil {
+		return nil, err
+	}
+
+	l := &lote{recibido: s.now(), rawDocs: rawDocs}
+	for _, raw := range rawDocs {
+		de, err := parseRDE(raw)
+		if err != nil {
+			return nil, err
+		}
+		l.documentos = append(l.documentos, de)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	l.numero = strconv.FormatInt(s.seq, 10)
+	s.lotes[l.numero] = l
+
+	return rRetEnviLoteDe{RespuestaRecepcionLoteDE: response.RespuestaRecepcionLoteDE{
+		BaseResponse: response.BaseResponse{DCodRes: CodeLoteRecibido, DMsgRes: "Lote recibido con éxito"},
+		DProtConsLot: l.numero,
+		DTmpLot:      s.config.TiempoEstimadoLote,
+	}}, nil
+}
+
+type rResEnviConsDe struct {
+	XMLName xml.Name `xml:"rResEnviConsDe"`
+	response.RespuestaConsultaDE
+}
+
+func (s *Server) consultaDE(body []byte) (interface{}, error) {
+	var req request.REnviConsDE
+	if err := xml.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.processDueLotes()
+
+	stored, ok := s.des[req.DCdCDE]
+	if !ok {
+		return rResEnviConsDe{RespuestaConsultaDE: response.RespuestaConsultaDE{
+			BaseResponse: response.BaseResponse{DCodRes: response.CodeConsultaDENoExiste, DMsgRes: "CDC inexistente"},
+		}}, nil
+	}
+
+	prot := stored.protDe()
+	return rResEnviConsDe{RespuestaConsultaDE: response.RespuestaConsultaDE{
+		BaseResponse: response.BaseResponse{DCodRes: response.CodeConsultaDEEncontrado, DMsgRes: "CDC encontrado"},
+		RProtDe:      &prot,
+		RDE:          stored.XML,
+	}}, nil
+}
+
+type rResEnviConsLoteDe struct {
+	XMLName xml.Name `xml:"rResEnviConsLoteDe"`
+	response.RespuestaConsultaLoteDE
+}
+
+func (s *Server) consultaLote(body []byte) (interface{}, error) {
+	var req request.REnviConsLoteDe
+	if err := xml.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.processDueLotes()
+
+	l, ok := s.lotes[req.DProtConsLote]
+	if !ok {
+		return rResEnviConsLoteDe{RespuestaConsultaLoteDE: response.RespuestaConsultaLoteDE{
+			BaseResponse: response.BaseResponse{DCodRes: CodeLoteInexistente, DMsgRes: "Número de lote inexistente"},
+			DProtConsLot: req.DProtConsLote,
+		}}, nil
+	}
+	if !l.procesado {
+		return rResEnviConsLoteDe{RespuestaConsultaLoteDE: response.RespuestaConsultaLoteDE{
+			BaseResponse: response.BaseResponse{DCodRes: CodeLoteEnProceso, DMsgRes: "Lote en procesamiento"},
+			DEstLote:     "En procesamiento",
+			DProtConsLot: l.numero,
+		}}, nil
+	}
+	return rResEnviConsLoteDe{RespuestaConsultaLoteDE: response.RespuestaConsultaLoteDE{
+		BaseResponse: response.BaseResponse{DCodRes: CodeLoteConcluido, DMsgRes: "Procesamiento de lote concluido"},
+		DEstLote:     "Concluido",
+		DProtConsLot: l.numero,
+		GResProcLote: l.resultados,
+	}}, nil
+}
+
+type rRetEnviEventoDe struct {
+	XMLName xml.Name `xml:"rRetEnviEventoDe"`
+	response.RespuestaEvento
+}
+
+func (s *Server) evento(body []byte) (interface{}, error) {
+	var req request.REnviEventoDe
+	if err := xml.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+
+	var grupo struct {
+		RGesEve []events.RGesEve `xml:"rGesEve"`
+	}
+	raw := "<gGroupGesEve>" + string(req.DEvReg.GGroupGesEve.RawRGesEve) + "</gGroupGesEve>"
+	if err := xml.Unmarshal([]byte(raw), &grupo); err != nil {
+		return nil, fmt.Errorf("invalid event: %w", err)
+	}
+	if len(grupo.RGesEve) != 1 || grupo.RGesEve[0].REve == nil {
+		return nil, fmt.Errorf("invalid event: expected one rGesEve with rEve, got %d", len(grupo.RGesEve))
+	}
+	evento := grupo.RGesEve[0].REve
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.processDueLotes()
+
+	code, msg := CodeEventoAprobado, "Evento registrado correctamente"
+	if cdc := evento.CDC(); cdc != "" {
+		stored, ok := s.des[cdc]
+		switch {
+		case !ok:
+			code, msg = CodeEventoRechazado, "CDC del evento inexistente"
+		case evento.GGroupTiEvt.RGeVeCan != nil:
+			if stored.Estado != EstadoAprobado {
+				code, msg = CodeEventoRechazado, "Solo se pueden cancelar DE aprobados"
+			} else {
+				stored.Estado = EstadoCancelado
+			}
+		}
+	}
+
+	return rRetEnviEventoDe{RespuestaEvento: response.RespuestaEvento{
+		BaseResponse: response.BaseResponse{DCodRes: code, DMsgRes: msg},
+		RProtEve: response.TxProtEve{
+			Id:       evento.Id,
+			DFecProc: s.now(),
+			DCodRes:  code,
+			DMsgRes:  msg,
+		},
+	}}, nil
+}
+
+// ============================================================================
+// Procesamiento
+// ============================================================================
+
+// process evalúa las reglas y almacena el DE; requiere s.mu tomado
+func (s *Server) process(de *models.DocumentoElectronico, raw []byte) *StoredDE {
+	stored := &StoredDE{
+		CDC:          de.DE.Id,
+		XML:          raw,
+		FechaProceso: s.now(),
+	}
+
+	rejection := s.evaluate(de)
+	if rejection != nil {
+		stored.Estado = EstadoRechazado
+		stored.Resultado = response.TgResProc{DCodRes: rejection.Code, DMsgRes: rejection.Message}
+	} else {
+		stored.Estado = EstadoAprobado
+		stored.Protocolo = s.nextProtocol()
+		stored.Resultado = response.TgResProc{DCodRes: CodeDEAprobado, DMsgRes: "Autorización del DE satisfactoria"}
+	}
+
+	// Un CDC duplicado no reemplaza al DE original, salvo que haya sido rechazado
+	if existing, exists := s.des[de.DE.Id]; !exists || existing.Estado == EstadoRechazado {
+		s.des[de.DE.Id] = stored
+	}
+	return stored
+}
+
+func (s *Server) evaluate(de *models.DocumentoElectronico) *Rejection {
+	if rejection, ok := s.rejectCDC[de.DE.Id]; ok {
+		return &rejection
+	}
+	if existing, ok := s.des[de.DE.Id]; ok && existing.Estado != EstadoRechazado {
+		return &Rejection{Code: "0160", Message: "CDC duplicado"}
+	}
+	for _, rule := range s.rules {
+		if rejection := rule(de); rejection != nil {
+			return rejection
+		}
+	}
+	return nil
+}
+
+// processDueLotes procesa los lotes cuya demora ya venció; requiere s.mu tomado
+func (s *Server) processDueLotes() {
+	now := s.now()
+	for _, l := range s.lotes {
+		if l.procesado || now.Sub(l.recibido) < s.config.ProcessingDelay {
+			continue
+		}
+		for i, de := range l.documentos {
+			stored := s.process(de, l.rawDocs[i])
+			l.resultados = append(l.resultados, response.TgResProcLote{
+				Id:       stored.CDC,
+				DEstRes:  stored.Estado,
+				DProtAut: stored.Protocolo,
+				GResProc: []response.TgResProc{stored.Resultado},
+			})
+		}
+		l.procesado = true
+	}
+}
+
+func (s *Server) nextProtocol() string {
+	s.seq++
+	return strconv.FormatInt(s.seq, 10)
+}
+
+func (d *StoredDE) protDe() response.TxProtDe {
+	return response.TxProtDe{
+		Id:       d.CDC,
+		DFecProc: d.FechaProceso,
+		DEstRes:  d.Estado,
+		DProtAut: d.Protocolo,
+		GResProc: []response.TgResProc{d.Resultado},
+	}
+}
+
+// ============================================================================
+// Helpers
+// ============================================================================
+
+// parseRDE decodifica un rDE (firmado o no)
+func parseRDE(raw []byte) (*models.DocumentoElectronico, error) {
+	var de models.DocumentoElectronico
+	if err := xml.Unmarshal(raw, &de); err != nil {
+		return nil, fmt.Errorf("invalid rDE: %w", err)
+	}
+	if de.DE.Id == "" {
+		return nil, fmt.Errorf("invalid rDE: missing DE Id")
+	}
+	return &de, nil
+}
+
+// unzipLote decodifica el contenido Base64 de un lote y retorna cada rDE
+func unzipLote(content string) ([][]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lote encoding: %w", err)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid lote zip: %w", err)
+	}
+
+	var docs [][]byte
+	for _, file := range reader.File {
+		f, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		loteXML, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		var parsed sifen.RLoteDE
+		if err := xml.Unmarshal(loteXML, &parsed); err != nil {
+			return nil, fmt.Errorf("invalid rLoteDE: %w", err)
+		}
+		for _, wrapper := range parsed.RDEList {
+			inner := strings.TrimSpace(wrapper.InnerXML)
+			if !strings.HasPrefix(inner, "<rDE") {
+				inner = "<rDE>" + inner + "</rDE>"
+			}
+			docs = append(docs, []byte(inner))
+		}
+	}
+	return docs, nil
+}
@@ -0,0 +1,286 @@
+package sifentest_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rodascaar/sifen-go-py/sifen"
+	"github.com/rodascaar/sifen-go-py/sifen/models"
+	"github.com/rodascaar/sifen-go-py/sifen/response"
+	"github.com/rodascaar/sifen-go-py/sifen/sifentest"
+	"github.com/rodascaar/sifen-go-py/sifen/types"
+)
+
+const (
+	testCDC1 = "01800695631001001000000612024123017595714694"
+	testCDC2 = "01800695631001001000000712024123017595714695"
+)
+
+func newClient(t *testing.T, srv *sifentest.Server) *sifen.SifenClient {
+	t.Helper()
+	config := srv.SifenConfig()
+	config.RetryPolicy = sifen.RetryPolicy{}
+	config.RucEmisor = "80069563-1"
+	client, err := sifen.NewSifenClient(config)
+	if err != nil {
+		t.Fatalf("NewSifenClient() error = %v", err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+func newDE(cdc string) *models.DocumentoElectronico {
+	de := models.NewDE(cdc)
+	de.DE.GTimb.ITiDE = types.TTiDE_FacturaElectronica
+	return de
+}
+
+func TestRecepcionDEAndConsulta(t *testing.T) {
+	srv := sifentest.NewServer(sifentest.Config{})
+	defer srv.Close()
+	client := newClient(t, srv)
+
+	resp, err := client.RecepcionDE(newDE(testCDC1))
+	if err != nil {
+		t.Fatalf("RecepcionDE() error = %v", err)
+	}
+	if !resp.IsApproved() || resp.DCodRes != sifentest.CodeDEAprobado {
+		t.Fatalf("RecepcionDE() = %+v; want approved", resp)
+	}
+
+	// Reenviar el mismo CDC es rechazado como duplicado
+	resp, err = client.RecepcionDE(newDE(testCDC1))
+	if err != nil {
+		t.Fatalf("RecepcionDE() error = %v", err)
+	}
+	if resp.DCodRes != "0160" {
+		t.Errorf("duplicate DCodRes = %q; want 0160", resp.DCodRes)
+	}
+
+	consulta, err := client.ConsultaDE(testCDC1)
+	if err != nil {
+		t.Fatalf("ConsultaDE() error = %v", err)
+	}
+	if consulta.DCodRes != response.CodeConsultaDEEncontrado || consulta.RProtDe.DEstRes != sifentest.EstadoAprobado {
+		t.Errorf("ConsultaDE() = %+v; want approved DE", consulta)
+	}
+
+	consulta, err = client.ConsultaDE(testCDC2)
+	if err != nil {
+		t.Fatalf("ConsultaDE() error = %v", err)
+	}
+	if consulta.DCodRes != response.CodeConsultaDENoExiste {
+		t.Errorf("ConsultaDE(unknown) DCodRes = %q; want %s", consulta.DCodRes, response.CodeConsultaDENoExiste)
+	}
+}
+
+func TestRejectionRules(t *testing.T) {
+	srv := sifentest.NewServer(sifentest.Config{})
+	defer srv.Close()
+	client := newClient(t, srv)
+
+	srv.RejectCDC(testCDC1, "0160", "XML mal formado")
+	srv.AddRule(func(de *models.DocumentoElectronico) *sifentest.Rejection {
+		if de.DE.GTimb.ITiDE != types.TTiDE_FacturaElectronica {
+			return &sifentest.Rejection{Code: "1001", Message: "Tipo de documento no habilitado"}
+		}
+		return nil
+	})
+
+	resp, err := client.RecepcionDE(newDE(testCDC1))
+	if err != nil {
+		t.Fatalf("RecepcionDE() error = %v", err)
+	}
+	if resp.IsApproved() || resp.RProtDe.GResProc[0].DCodRes != "0160" {
+		t.Errorf("RecepcionDE() = %+v; want rejection 0160", resp.RProtDe)
+	}
+
+	de := newDE(testCDC2)
+	de.DE.GTimb.ITiDE = types.TTiDE_NotaCreditoElectronica
+	resp, err = client.RecepcionDE(de)
+	if err != nil {
+		t.Fatalf("RecepcionDE() error = %v", err)
+	}
+	if resp.IsApproved() || resp.RProtDe.GResProc[0].DCodRes != "1001" {
+		t.Errorf("RecepcionDE() = %+v; want rejection 1001", resp.RProtDe)
+	}
+}
+
+func TestLoteLifecycle(t *testing.T) {
+	srv := sifentest.NewServer(sifentest.Config{ProcessingDelay: 50 * time.Millisecond})
+	defer srv.Close()
+	client := newClient(t, srv)
+
+	result, err := client.EnviarLoteDE(sifen.LoteParams{
+		Documentos:    []*models.DocumentoElectronico{newDE(testCDC1), newDE(testCDC2)},
+		TipoDocumento: types.TTiDE_FacturaElectronica,
+	})
+	if err != nil {
+		t.Fatalf("EnviarLoteDE() error = %v", err)
+	}
+	if result.NumeroLote == "" {
+		t.Fatal("EnviarLoteDE() returned empty NumeroLote")
+	}
+
+	consulta, err := client.ConsultaLoteDE(result.NumeroLote)
+	if err != nil {
+		t.Fatalf("ConsultaLoteDE() error = %v", err)
+	}
+	if consulta.DCodRes != sifentest.CodeLoteEnProceso {
+		t.Errorf("ConsultaLoteDE() DCodRes = %q; want %s", consulta.DCodRes, sifentest.CodeLoteEnProceso)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	consulta, err = client.ConsultaLoteDE(result.NumeroLote)
+	if err != nil {
+		t.Fatalf("ConsultaLoteDE() error = %v", err)
+	}
+	if consulta.DCodRes != sifentest.CodeLoteConcluido || len(consulta.GResProcLote) != 2 {
+		t.Fatalf("ConsultaLoteDE() = %+v; want 2 processed documents", consulta)
+	}
+	if consulta.GResProcLote[0].Id != testCDC1 || consulta.GResProcLote[0].DEstRes != sifentest.EstadoAprobado {
+		t.Errorf("GResProcLote[0] = %+v; want %s approved", consulta.GResProcLote[0], testCDC1)
+	}
+
+	if _, ok := srv.DE(testCDC2); !ok {
+		t.Errorf("DE(%s) not stored after batch processing", testCDC2)
+	}
+}
+
+func TestConsultaRUCSeeded(t *testing.T) {
+	srv := sifentest.NewServer(sifentest.Config{})
+	defer srv.Close()
+	client := newClient(t, srv)
+
+	srv.SeedRUC("80069563", response.TxContRuc{DRazCons: "EMPRESA DE PRUEBA S.A."})
+
+	resp, err := client.ConsultaRUC("80069563")
+	if err != nil {
+		t.Fatalf("ConsultaRUC() error = %v", err)
+	}
+	if resp.DCodRes != sifentest.CodeRUCEncontrado || resp.XContRUC.DRazCons != "EMPRESA DE PRUEBA S.A." {
+		t.Errorf("ConsultaRUC() = %+v; want seeded taxpayer", resp)
+	}
+
+	resp, err = client.ConsultaRUC("1234567")
+	if err != nil {
+		t.Fatalf("ConsultaRUC() error = %v", err)
+	}
+	if resp.DCodRes != sifentest.CodeRUCInexistente {
+		t.Errorf("ConsultaRUC(unknown) DCodRes = %q; want %s", resp.DCodRes, sifentest.CodeRUCInexistente)
+	}
+}
+
+func TestConsultaRUCInexistenteNotRetried(t *testing.T) {
+	srv := sifentest.NewServer(sifentest.Config{})
+	defer srv.Close()
+
+	// 0500 es "RUC inexistente" en ConsultaRUC, no "servicio no disponible"
+	var calls int32
+	config := srv.SifenConfig()
+	config.RetryPolicy = sifen.DefaultRetryPolicy()
+	config.RetryPolicy.BaseDelay = time.Millisecond
+	config.Middlewares = []sifen.Middleware{func(next sifen.SoapHandler) sifen.SoapHandler {
+		return func(ctx context.Context, req *sifen.SoapRequest) (*sifen.SoapResponse, error) {
+			atomic.AddInt32(&calls, 1)
+			return next(ctx, req)
+		}
+	}}
+	client, err := sifen.NewSifenClient(config)
+	if err != nil {
+		t.Fatalf("NewSifenClient() error = %v", err)
+	}
+	defer client.Close()
+
+	resp, err := client.ConsultaRUC("99999999")
+	if err != nil {
+		t.Fatalf("ConsultaRUC() error = %v", err)
+	}
+	if resp.DCodRes != sifentest.CodeRUCInexistente {
+		t.Errorf("DCodRes = %q; want %s", resp.DCodRes, sifentest.CodeRUCInexistente)
+	}
+	if calls != 1 {
+		t.Errorf("requests = %d; want 1", calls)
+	}
+}
+
+func TestCancelarDE(t *testing.T) {
+	srv := sifentest.NewServer(sifentest.Config{})
+	defer srv.Close()
+	client := newClient(t, srv)
+
+	srv.SeedDE(testCDC1, sifentest.EstadoAprobado)
+
+	resp, err := client.CancelarDE(testCDC1, "Error en los datos del receptor")
+	if err != nil {
+		t.Fatalf("CancelarDE() error = %v", err)
+	}
+	if !resp.IsApproved() {
+		t.Errorf("CancelarDE() = %+v; want approved event", resp.RProtEve)
+	}
+
+	stored, _ := srv.DE(testCDC1)
+	if stored.Estado != sifentest.EstadoCancelado {
+		t.Errorf("Estado = %q; want %s", stored.Estado, sifentest.EstadoCancelado)
+	}
+
+	resp, err = client.CancelarDE(testCDC2, "Error en los datos del receptor")
+	if err != nil {
+		t.Fatalf("CancelarDE() error = %v", err)
+	}
+	if resp.IsApproved() {
+		t.Errorf("CancelarDE(unknown) approved; want %s", sifentest.CodeEventoRechazado)
+	}
+}
+
+func TestRecepcionDESignedWithExternalKey(t *testing.T) {
+	srv := sifentest.NewServer(sifentest.Config{})
+	defer srv.Close()
+
+	signer, err := sifentest.NewSoftwareSigner("80069563-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := srv.SifenConfig()
+	config.RucEmisor = "80069563-1"
+	config.ClaveFirma = sifen.NewDigestCryptoSigner(signer)
+	config.CertificadosFirma = signer.Certificates()
+	config.VerificarFirma = true
+	client, err := sifen.NewSifenClient(config)
+	if err != nil {
+		t.Fatalf("NewSifenClient() error = %v", err)
+	}
+	defer client.Close()
+
+	de := newDE(testCDC1)
+	if _, err := client.RecepcionDE(de); err != nil {
+		t.Fatalf("RecepcionDE() error = %v", err)
+	}
+	if signer.Signatures() != 1 {
+		t.Errorf("Signatures() = %d; want 1", signer.Signatures())
+	}
+	stored, _ := srv.DE(testCDC1)
+	results, err := sifen.VerificarFirma(stored.XML)
+	if err != nil {
+		t.Fatalf("VerificarFirma() error = %v", err)
+	}
+	if results[0].ReferenceID != testCDC1 || !results[0].Certificate.Equal(signer.Certificates()[0]) {
+		t.Errorf("VerificarFirma() = %+v", results[0])
+	}
+
+	// El QR lleva el DigestValue de la firma y va después de Signature
+	digest := base64.StdEncoding.EncodeToString(results[0].DigestValue)
+	if de.GCamFuFD == nil || !strings.Contains(de.GCamFuFD.DCarQR, "&DigestValue="+hex.EncodeToString([]byte(digest))+"&") {
+		t.Errorf("GCamFuFD = %+v; want the QR with DigestValue %s", de.GCamFuFD, digest)
+	}
+	xml := string(stored.XML)
+	if !strings.Contains(xml, "</Signature><gCamFuFD><dCarQR>") || !strings.HasSuffix(xml, "</gCamFuFD></rDE>") {
+		t.Errorf("stored XML does not end with Signature followed by gCamFuFD: %s", xml[len(xml)-200:])
+	}
+}
@@ -0,0 +1,73 @@
+package sifentest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"sync/atomic"
+	"time"
+)
+
+// SoftwareSigner es un sifen.DigestSigner con la clave en memoria, para probar
+// la firma con claves externas (HSM, KMS) sin hardware. Registra cuántas firmas hizo.
+type SoftwareSigner struct {
+	key          *rsa.PrivateKey
+	certificates []*x509.Certificate
+	signatures   atomic.Int64
+}
+
+// NewSoftwareSigner genera una clave RSA y un certificado autofirmado válido por
+// un año, con el RUC del emisor (ej. "80069563-1") en el serialNumber del sujeto
+func NewSoftwareSigner(ruc string) (*SoftwareSigner, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(now.UnixNano()),
+		Subject: pkix.Name{
+			Country:      []string{"PY"},
+			CommonName:   "FIRMANTE DE PRUEBA",
+			SerialNumber: "RUC" + ruc,
+		},
+		NotBefore: now.Add(-time.Hour),
+		NotAfter:  now.AddDate(1, 0, 0),
+		KeyUsage:  x509.KeyUsageDigitalSignature | x509.KeyUsageContentCommitment,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SoftwareSigner{key: key, certificates: []*x509.Certificate{cert}}, nil
+}
+
+// Public retorna la clave pública del firmante
+func (s *SoftwareSigner) Public() crypto.PublicKey {
+	return &s.key.PublicKey
+}
+
+// SignDigest firma un digest SHA-256 con RSASSA-PKCS1-v1_5
+func (s *SoftwareSigner) SignDigest(digest []byte) ([]byte, error) {
+	s.signatures.Add(1)
+	return rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest)
+}
+
+// Certificates retorna la cadena para SifenConfig.CertificadosFirma
+func (s *SoftwareSigner) Certificates() []*x509.Certificate {
+	return s.certificates
+}
+
+// Signatures retorna la cantidad de firmas realizadas
+func (s *SoftwareSigner) Signatures() int {
+	return int(s.signatures.Load())
+}
@@ -0,0 +1,19 @@
+package sifen
+
+import "github.com/rodascaar/sifen-go-py/internal/soap"
+
+// Tipos del cliente SOAP expuestos para escribir middlewares.
+// Un Middleware ve el sobre SOAP saliente, la URL y la respuesta HTTP cruda;
+// sirve para logging, inyección de headers o simulación de fallas en tests.
+type (
+	// Middleware envuelve un SoapHandler
+	Middleware = soap.Middleware
+	// SoapHandler ejecuta un intercambio SOAP
+	SoapHandler = soap.Handler
+	// SoapRequest es la llamada SOAP saliente
+	SoapRequest = soap.Request
+	// SoapResponse es la respuesta HTTP cruda
+	SoapResponse = soap.Response
+	// OperationTimeouts reemplaza los timeouts de conexión y lectura de una operación
+	OperationTimeouts = soap.Timeouts
+)
@@ -0,0 +1,324 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// Decimal: números decimales exactos (tdMonto, tdCantidad, tdPorcentaje, ...)
+// ============================================================================
+
+// MaxDecimales es la cantidad máxima de decimales que admiten los montos,
+// cantidades, porcentajes y tipos de cambio del Manual Técnico v150
+const MaxDecimales = 8
+
+// Decimal es un número decimal exacto de hasta 8 decimales. El valor cero
+// representa 0 y los valores se pueden comparar con ==.
+//
+// Se serializa en XML con la forma canónica de xs:decimal ("1500000",
+// "10.5"), nunca en notación exponencial. Internamente es un entero de 64 bits
+// con la escala, así que admite hasta 18 dígitos significativos; las
+// operaciones que se exceden entran en pánico. Con datos no controlados se
+// usan las variantes Checked, que retornan ErrDesbordamiento o
+// ErrDivisionPorCero.
+type Decimal struct {
+	coef  int64 // valor sin la coma decimal, sin ceros a la derecha si scale > 0
+	scale int8  // cantidad de decimales (0-8)
+}
+
+var (
+	// ErrDesbordamiento indica que el resultado no cabe en un Decimal
+	ErrDesbordamiento = errors.New("types.Decimal: desbordamiento")
+	// ErrDivisionPorCero indica una división por un Decimal cero
+	ErrDivisionPorCero = errors.New("types.Decimal: división por cero")
+)
+
+var decimalRE = regexp.MustCompile(`^[+-]?(?:[0-9]+(?:\.[0-9]*)?|\.[0-9]+)$`)
+
+var pow10 = [...]int64{1, 10, 100, 1000, 10000, 100000, 1000000, 10000000, 100000000}
+
+// NewDecimal retorna coef × 10^-scale; por ejemplo NewDecimal(105, 1) es 10.5.
+// Con más de 8 decimales redondea como Round.
+func NewDecimal(coef int64, scale int) Decimal {
+	return fromBig(big.NewInt(coef), scale)
+}
+
+// DecimalFromInt retorna el entero n como Decimal
+func DecimalFromInt(n int64) Decimal {
+	return NewDecimal(n, 0)
+}
+
+// DecimalFromFloat convierte f redondeando a 8 decimales. Sirve para migrar
+// datos en float64; los cálculos deben hacerse con Decimal.
+func DecimalFromFloat(f float64) Decimal {
+	d, err := ParseDecimal(strconv.FormatFloat(f, 'f', MaxDecimales, 64))
+	if err != nil {
+		panic(fmt.Sprintf("types.Decimal: %v", err))
+	}
+	return d
+}
+
+// ParseDecimal interpreta un xs:decimal ("1500000", "-10.50", ".5"). Rechaza
+// la notación exponencial y más de 8 decimales significativos.
+func ParseDecimal(s string) (Decimal, error) {
+	s = strings.TrimSpace(s)
+	if !decimalRE.MatchString(s) {
+		return Decimal{}, fmt.Errorf("decimal inválido %q", s)
+	}
+	integer, frac, _ := strings.Cut(s, ".")
+	frac = strings.TrimRight(frac, "0")
+	if len(frac) > MaxDecimales {
+		return Decimal{}, fmt.Errorf("decimal %q con más de %d decimales", s, MaxDecimales)
+	}
+	v, ok := new(big.Int).SetString(integer+frac, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("decimal inválido %q", s)
+	}
+	if !v.IsInt64() {
+		return Decimal{}, fmt.Errorf("decimal %q fuera de rango", s)
+	}
+	return normalize(v.Int64(), len(frac)), nil
+}
+
+// MustParseDecimal es ParseDecimal para constantes conocidas; entra en pánico
+// si s no es válido
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(fmt.Sprintf("types.Decimal: %v", err))
+	}
+	return d
+}
+
+// normalize quita los ceros a la derecha para que cada valor tenga una única
+// representación
+func normalize(coef int64, scale int) Decimal {
+	if coef == 0 {
+		return Decimal{}
+	}
+	for scale > 0 && coef%10 == 0 {
+		coef /= 10
+		scale--
+	}
+	return Decimal{coef: coef, scale: int8(scale)}
+}
+
+// fromBig retorna v × 10^-scale redondeado a 8 decimales; entra en pánico si
+// no cabe en un Decimal
+func fromBig(v *big.Int, scale int) Decimal {
+	d, err := checkedFromBig(v, scale)
+	if err != nil {
+		panic(err.Error())
+	}
+	return d
+}
+
+// checkedFromBig es fromBig con ErrDesbordamiento en lugar del pánico
+func checkedFromBig(v *big.Int, scale int) (Decimal, error) {
+	v = new(big.Int).Set(v)
+	for ; scale < 0; scale++ {
+		v.Mul(v, big.NewInt(10))
+	}
+	if scale > MaxDecimales {
+		v = roundBig(v, scale-MaxDecimales)
+		scale = MaxDecimales
+	}
+	if !v.IsInt64() {
+		return Decimal{}, ErrDesbordamiento
+	}
+	return normalize(v.Int64(), scale), nil
+}
+
+func pow10Big(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// roundBig divide v por 10^digits redondeando la mitad lejos del cero
+func roundBig(v *big.Int, digits int) *big.Int {
+	div := pow10Big(digits)
+	q, r := new(big.Int).QuoRem(v, div, new(big.Int))
+	if r.Sign() != 0 && new(big.Int).Mul(new(big.Int).Abs(r), big.NewInt(2)).Cmp(div) >= 0 {
+		q.Add(q, big.NewInt(int64(v.Sign())))
+	}
+	return q
+}
+
+// aligned retorna los coeficientes de d y e con la misma escala
+func (d Decimal) aligned(e Decimal) (x, y *big.Int, scale int) {
+	scale = max(int(d.scale), int(e.scale))
+	x = new(big.Int).Mul(big.NewInt(d.coef), big.NewInt(pow10[scale-int(d.scale)]))
+	y = new(big.Int).Mul(big.NewInt(e.coef), big.NewInt(pow10[scale-int(e.scale)]))
+	return x, y, scale
+}
+
+// Add retorna d + e
+func (d Decimal) Add(e Decimal) Decimal {
+	x, y, scale := d.aligned(e)
+	return fromBig(x.Add(x, y), scale)
+}
+
+// CheckedAdd es Add con ErrDesbordamiento en lugar del pánico
+func (d Decimal) CheckedAdd(e Decimal) (Decimal, error) {
+	x, y, scale := d.aligned(e)
+	return checkedFromBig(x.Add(x, y), scale)
+}
+
+// Sub retorna d - e
+func (d Decimal) Sub(e Decimal) Decimal {
+	x, y, scale := d.aligned(e)
+	return fromBig(x.Sub(x, y), scale)
+}
+
+// CheckedSub es Sub con ErrDesbordamiento en lugar del pánico
+func (d Decimal) CheckedSub(e Decimal) (Decimal, error) {
+	x, y, scale := d.aligned(e)
+	return checkedFromBig(x.Sub(x, y), scale)
+}
+
+// Mul retorna d × e redondeado a 8 decimales
+func (d Decimal) Mul(e Decimal) Decimal {
+	v := new(big.Int).Mul(big.NewInt(d.coef), big.NewInt(e.coef))
+	return fromBig(v, int(d.scale)+int(e.scale))
+}
+
+// CheckedMul es Mul con ErrDesbordamiento en lugar del pánico
+func (d Decimal) CheckedMul(e Decimal) (Decimal, error) {
+	v := new(big.Int).Mul(big.NewInt(d.coef), big.NewInt(e.coef))
+	return checkedFromBig(v, int(d.scale)+int(e.scale))
+}
+
+// Div retorna d / e redondeado a 8 decimales. Entra en pánico si e es cero.
+func (d Decimal) Div(e Decimal) Decimal {
+	return d.DivRound(e, MaxDecimales)
+}
+
+// DivRound retorna d / e redondeado directamente a la cantidad de decimales
+// indicada (0-8), sin el doble redondeo de Div seguido de Round. Entra en
+// pánico si e es cero.
+func (d Decimal) DivRound(e Decimal, decimals int) Decimal {
+	q, err := d.CheckedDivRound(e, decimals)
+	if err != nil {
+		panic(err.Error())
+	}
+	return q
+}
+
+// CheckedDivRound es DivRound con ErrDivisionPorCero o ErrDesbordamiento en
+// lugar del pánico
+func (d Decimal) CheckedDivRound(e Decimal, decimals int) (Decimal, error) {
+	if e.coef == 0 {
+		return Decimal{}, ErrDivisionPorCero
+	}
+	decimals = min(max(decimals, 0), MaxDecimales)
+	// d/e × 10^(decimals+1) = d.coef × 10^(decimals+1+e.scale) / (e.coef × 10^d.scale),
+	// con un dígito de más para redondear
+	num := new(big.Int).Mul(big.NewInt(d.coef), pow10Big(decimals+1+int(e.scale)))
+	den := new(big.Int).Mul(big.NewInt(e.coef), pow10Big(int(d.scale)))
+	q := num.Quo(num, den)
+	return checkedFromBig(roundBig(q, 1), decimals)
+}
+
+// Neg retorna -d
+func (d Decimal) Neg() Decimal {
+	return Decimal{coef: -d.coef, scale: d.scale}
+}
+
+// Round redondea d a la cantidad de decimales indicada con la regla del
+// manual: si el primer dígito descartado es 5 o más, se suma uno al último
+// dígito conservado (la mitad se redondea lejos del cero)
+func (d Decimal) Round(decimals int) Decimal {
+	if decimals >= int(d.scale) {
+		return d
+	}
+	return fromBig(roundBig(big.NewInt(d.coef), int(d.scale)-decimals), decimals)
+}
+
+// Cmp compara d con e y retorna -1, 0 o +1
+func (d Decimal) Cmp(e Decimal) int {
+	x, y, _ := d.aligned(e)
+	return x.Cmp(y)
+}
+
+// Sign retorna -1, 0 o +1 según el signo de d
+func (d Decimal) Sign() int {
+	switch {
+	case d.coef < 0:
+		return -1
+	case d.coef > 0:
+		return 1
+	}
+	return 0
+}
+
+// IsZero indica si d es cero
+func (d Decimal) IsZero() bool {
+	return d.coef == 0
+}
+
+// Decimales retorna la cantidad de decimales significativos de d
+func (d Decimal) Decimales() int {
+	return int(d.scale)
+}
+
+// IntPart retorna la parte entera de d
+func (d Decimal) IntPart() int64 {
+	return d.coef / pow10[d.scale]
+}
+
+// Float64 retorna d como float64, para mostrarlo; no usar en cálculos
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// Ptr retorna un puntero a una copia de d, para los campos opcionales
+func (d Decimal) Ptr() *Decimal {
+	return &d
+}
+
+// String retorna la forma canónica de xs:decimal, sin ceros a la derecha
+func (d Decimal) String() string {
+	s := strconv.FormatInt(d.coef, 10)
+	if d.scale == 0 {
+		return s
+	}
+	sign := ""
+	if d.coef < 0 {
+		sign, s = "-", s[1:]
+	}
+	if n := int(d.scale) + 1 - len(s); n > 0 {
+		s = strings.Repeat("0", n) + s
+	}
+	i := len(s) - int(d.scale)
+	return sign + s[:i] + "." + s[i:]
+}
+
+// MarshalText implementa encoding.TextMarshaler, que usan encoding/xml y
+// encoding/json
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implementa encoding.TextUnmarshaler con ParseDecimal
+func (d *Decimal) UnmarshalText(text []byte) error {
+	v, err := ParseDecimal(string(text))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// Decimales retorna los decimales de los montos en la moneda: 0 en guaraníes
+// y 8 en las demás
+func (c CMondT) Decimales() int {
+	if c == CMondT_PYG {
+		return 0
+	}
+	return MaxDecimales
+}
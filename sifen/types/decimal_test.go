@@ -0,0 +1,160 @@
+package types
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		in, want string
+		wantErr  bool
+	}{
+		{"1500000", "1500000", false},
+		{"-10.50", "-10.5", false},
+		{".5", "0.5", false},
+		{"+007.000", "7", false},
+		{"0.00000001", "0.00000001", false},
+		{"12.345678900", "12.3456789", false},
+		{"-0.0", "0", false},
+		{"1.5e+06", "", true},
+		{"1.123456789", "", true},
+		{"99999999999999999999", "", true},
+		{"", "", true},
+		{"1,5", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDecimal(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDecimal(%q) error = %v", tt.in, err)
+			continue
+		}
+		if err == nil && got.String() != tt.want {
+			t.Errorf("ParseDecimal(%q) = %s; want %s", tt.in, got, tt.want)
+		}
+	}
+	if MustParseDecimal("10.50") != NewDecimal(105, 1) {
+		t.Error("los valores iguales deben ser == sin importar los ceros a la derecha")
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	d := MustParseDecimal
+	tests := []struct {
+		name      string
+		got, want Decimal
+	}{
+		{"suma", d("0.1").Add(d("0.2")), d("0.3")},
+		{"resta", d("330000").Sub(d("330000.5")), d("-0.5")},
+		{"producto", d("110000").Mul(d("3")), d("330000")},
+		{"producto con decimales", d("10.5").Mul(d("0.33333333")), d("3.49999997")},
+		{"producto redondeado", d("0.00000005").Mul(d("0.5")), d("0.00000003")},
+		{"división", d("330000").Div(d("1.1")), d("300000")},
+		{"división periódica", d("100000").Div(d("11")), d("9090.90909091")},
+		{"división negativa", d("-2").Div(d("3")), d("-0.66666667")},
+		{"redondeo hacia arriba", d("1122.5").Round(0), d("1123")},
+		{"redondeo hacia abajo", d("1122.49999999").Round(0), d("1122")},
+		{"redondeo negativo", d("-2.5").Round(0), d("-3")},
+		{"redondeo a decenas", d("1234").Round(-1), d("1230")},
+		{"redondeo sin efecto", d("2.5").Round(2), d("2.5")},
+		{"negación", d("2.5").Neg(), d("-2.5")},
+		{"desde float", DecimalFromFloat(0.1 + 0.2), d("0.3")},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %s; want %s", tt.name, tt.got, tt.want)
+		}
+	}
+
+	if d("1.5").Cmp(d("1.50")) != 0 || d("-1").Cmp(d("0.5")) != -1 || d("1000000").Sign() != 1 {
+		t.Error("Cmp o Sign incorrecto")
+	}
+	if d("12345.678").IntPart() != 12345 || d("-0.5").IntPart() != 0 || d("12.340").Decimales() != 2 {
+		t.Error("IntPart o Decimales incorrecto")
+	}
+}
+
+func TestDecimalOverflow(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Mul debe entrar en pánico al desbordar")
+		}
+	}()
+	DecimalFromInt(9e18).Mul(DecimalFromInt(10))
+}
+
+func TestDecimalChecked(t *testing.T) {
+	grande := DecimalFromInt(9e18)
+	if _, err := grande.CheckedAdd(grande); err != ErrDesbordamiento {
+		t.Errorf("CheckedAdd() error = %v; want ErrDesbordamiento", err)
+	}
+	if _, err := grande.Neg().CheckedSub(grande); err != ErrDesbordamiento {
+		t.Errorf("CheckedSub() error = %v; want ErrDesbordamiento", err)
+	}
+	if _, err := grande.CheckedMul(DecimalFromInt(10)); err != ErrDesbordamiento {
+		t.Errorf("CheckedMul() error = %v; want ErrDesbordamiento", err)
+	}
+	if _, err := grande.CheckedDivRound(MustParseDecimal("0.1"), 0); err != ErrDesbordamiento {
+		t.Errorf("CheckedDivRound() error = %v; want ErrDesbordamiento", err)
+	}
+	if _, err := DecimalFromInt(1).CheckedDivRound(Decimal{}, 2); err != ErrDivisionPorCero {
+		t.Errorf("CheckedDivRound(0) error = %v; want ErrDivisionPorCero", err)
+	}
+	if got, err := MustParseDecimal("10.5").CheckedMul(DecimalFromInt(2)); err != nil || got != DecimalFromInt(21) {
+		t.Errorf("CheckedMul() = %s, %v; want 21", got, err)
+	}
+}
+
+func TestDecimalXML(t *testing.T) {
+	type doc struct {
+		Monto    Decimal  `xml:"dMonto"`
+		Opcional *Decimal `xml:"dOpcional,omitempty"`
+		Attr     Decimal  `xml:"v,attr"`
+	}
+
+	out, err := xml.Marshal(doc{Monto: DecimalFromInt(1000000), Attr: MustParseDecimal("0.5")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `<doc v="0.5"><dMonto>1000000</dMonto></doc>`; string(out) != want {
+		t.Errorf("Marshal = %s; want %s", out, want)
+	}
+
+	var in doc
+	if err := xml.Unmarshal([]byte(`<doc v="1"><dMonto> 1234567.89 </dMonto><dOpcional>7</dOpcional></doc>`), &in); err != nil {
+		t.Fatal(err)
+	}
+	if in.Monto != MustParseDecimal("1234567.89") || in.Opcional == nil || *in.Opcional != DecimalFromInt(7) || in.Attr != DecimalFromInt(1) {
+		t.Errorf("Unmarshal = %+v", in)
+	}
+	if err := xml.Unmarshal([]byte(`<doc><dMonto>1e+06</dMonto></doc>`), &in); err == nil {
+		t.Error("Unmarshal debe rechazar la notación exponencial")
+	}
+
+	if CMondT_PYG.Decimales() != 0 || CMondT_USD.Decimales() != 8 {
+		t.Error("Decimales por moneda incorrecto")
+	}
+}
+
+func TestDecimalDivRound(t *testing.T) {
+	d := MustParseDecimal
+	tests := []struct {
+		a, b     string
+		decimals int
+		want     string
+	}{
+		{"1234500", "110", 0, "11223"},
+		{"2", "3", 2, "0.67"},
+		{"-2", "3", 0, "-1"},
+		{"1", "8", 2, "0.13"},
+		{"0.00000001", "3", 8, "0"},
+		{"10.5", "0.25", 0, "42"},
+		// Div redondea primero a 8 decimales: 0.499999999... daría 1
+		{"4999999999", "10000000001", 0, "0"},
+	}
+	for _, tt := range tests {
+		if got := d(tt.a).DivRound(d(tt.b), tt.decimals); got != d(tt.want) {
+			t.Errorf("%s.DivRound(%s, %d) = %s; want %s", tt.a, tt.b, tt.decimals, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,22 @@
 package types
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+)
+
+// Codigo es un código numérico de SIFEN cuya descripción oficial da String().
+// Los códigos sin descripción conocida se representan con su número.
+type Codigo interface {
+	~int16
+	String() string
+}
+
+// Descripcion retorna la descripción oficial del código e indica si es conocida
+func Descripcion[T Codigo](c T) (string, bool) {
+	desc := c.String()
+	return desc, desc != strconv.Itoa(int(c))
+}
 
 // ============================================================================
 // TTiDE: Tipo de Documento Electronico (Document Type)
@@ -37,7 +53,7 @@ func (t TTiDE) String() string {
 	case TTiDE_ComprobanteRetencionElectronico:
 		return "Comprobante de retención electrónico"
 	default:
-		return fmt.Sprintf("Tipo desconocido: %d", t)
+		return fmt.Sprintf("%d", t)
 	}
 }
 
@@ -232,11 +248,11 @@ func (t TiAfecIVA) String() string {
 	case TiAfecIVA_GravadoIVA:
 		return "Gravado IVA"
 	case TiAfecIVA_Exonerado:
-		return "Exonerado (Art. 83-Ley 125/91)"
+		return "Exonerado (Art. 83- Ley 125/91)"
 	case TiAfecIVA_Exento:
 		return "Exento"
 	case TiAfecIVA_GravadoParcial:
-		return "Gravado parcial (Grav-Exento)"
+		return "Gravado parcial (Grav- Exento)"
 	default:
 		return fmt.Sprintf("%d", t)
 	}
@@ -314,7 +330,7 @@ func (t TiCondCredito) String() string {
 	case TiCondCredito_Plazo:
 		return "Plazo"
 	case TiCondCredito_Cuotas:
-		return "Cuotas"
+		return "Cuota"
 	default:
 		return fmt.Sprintf("%d", t)
 	}
@@ -326,26 +342,54 @@ func (t TiCondCredito) String() string {
 type TiTipPago int16
 
 const (
-	TiTipPago_Efectivo            TiTipPago = 1
-	TiTipPago_Cheque              TiTipPago = 2
-	TiTipPago_TarjetaCredito      TiTipPago = 3
-	TiTipPago_TarjetaDebito       TiTipPago = 4
-	TiTipPago_TransferenciaBanco  TiTipPago = 5
-	TiTipPago_GirosBancarios      TiTipPago = 6
-	TiTipPago_BilleteraMobile     TiTipPago = 7
-	TiTipPago_CreditosFiscales    TiTipPago = 8
-	TiTipPago_Voucher             TiTipPago = 9
-	TiTipPago_RetencionParcial    TiTipPago = 10
-	TiTipPago_RetencionTotal      TiTipPago = 11
-	TiTipPago_PagoPorAnticipo     TiTipPago = 12
-	TiTipPago_ValorFiscal         TiTipPago = 13
-	TiTipPago_ValorComercial      TiTipPago = 14
-	TiTipPago_Compensacion        TiTipPago = 15
-	TiTipPago_Permuta             TiTipPago = 16
-	TiTipPago_PagoMovilBilletera  TiTipPago = 17
-	TiTipPago_InterbancariaCuenta TiTipPago = 18
-	TiTipPago_InterbancariaTarj   TiTipPago = 19
-	TiTipPago_Otro                TiTipPago = 99
+	TiTipPago_Efectivo           TiTipPago = 1
+	TiTipPago_Cheque             TiTipPago = 2
+	TiTipPago_TarjetaCredito     TiTipPago = 3
+	TiTipPago_TarjetaDebito      TiTipPago = 4
+	TiTipPago_Transferencia      TiTipPago = 5
+	TiTipPago_Giro               TiTipPago = 6
+	TiTipPago_BilleteraElectron  TiTipPago = 7
+	TiTipPago_TarjetaEmpresarial TiTipPago = 8
+	TiTipPago_Vale               TiTipPago = 9
+	TiTipPago_Retencion          TiTipPago = 10
+	TiTipPago_PagoAnticipo       TiTipPago = 11
+	TiTipPago_ValorFiscal        TiTipPago = 12
+	TiTipPago_ValorComercial     TiTipPago = 13
+	TiTipPago_Compensacion       TiTipPago = 14
+	TiTipPago_Permuta            TiTipPago = 15
+	TiTipPago_PagoBancario       TiTipPago = 16
+	TiTipPago_PagoMovil          TiTipPago = 17
+	TiTipPago_Donacion           TiTipPago = 18
+	TiTipPago_Promocion          TiTipPago = 19
+	TiTipPago_ConsumoInterno     TiTipPago = 20
+	TiTipPago_PagoElectronico    TiTipPago = 21
+	TiTipPago_Otro               TiTipPago = 99
+)
+
+// Nombres anteriores, que no coincidían con los códigos del Manual Técnico v150
+const (
+	// Deprecated: usar TiTipPago_Transferencia
+	TiTipPago_TransferenciaBanco = TiTipPago_Transferencia
+	// Deprecated: usar TiTipPago_Giro
+	TiTipPago_GirosBancarios = TiTipPago_Giro
+	// Deprecated: usar TiTipPago_BilleteraElectron
+	TiTipPago_BilleteraMobile = TiTipPago_BilleteraElectron
+	// Deprecated: el código 8 es TiTipPago_TarjetaEmpresarial
+	TiTipPago_CreditosFiscales = TiTipPago_TarjetaEmpresarial
+	// Deprecated: el código 9 es TiTipPago_Vale
+	TiTipPago_Voucher = TiTipPago_Vale
+	// Deprecated: el código 10 es TiTipPago_Retencion
+	TiTipPago_RetencionParcial = TiTipPago_Retencion
+	// Deprecated: el código 11 es TiTipPago_PagoAnticipo
+	TiTipPago_RetencionTotal = TiTipPago_PagoAnticipo
+	// Deprecated: el código 12 es TiTipPago_ValorFiscal
+	TiTipPago_PagoPorAnticipo = TiTipPago_ValorFiscal
+	// Deprecated: el código 17 es TiTipPago_PagoMovil
+	TiTipPago_PagoMovilBilletera = TiTipPago_PagoMovil
+	// Deprecated: el código 18 es TiTipPago_Donacion
+	TiTipPago_InterbancariaCuenta = TiTipPago_Donacion
+	// Deprecated: el código 19 es TiTipPago_Promocion
+	TiTipPago_InterbancariaTarj = TiTipPago_Promocion
 )
 
 func (t TiTipPago) String() string {
@@ -358,14 +402,44 @@ func (t TiTipPago) String() string {
 		return "Tarjeta de crédito"
 	case TiTipPago_TarjetaDebito:
 		return "Tarjeta de débito"
-	case TiTipPago_TransferenciaBanco:
-		return "Transferencia bancaria"
-	case TiTipPago_GirosBancarios:
-		return "Giros bancarios"
-	case TiTipPago_BilleteraMobile:
+	case TiTipPago_Transferencia:
+		return "Transferencia"
+	case TiTipPago_Giro:
+		return "Giro"
+	case TiTipPago_BilleteraElectron:
 		return "Billetera electrónica"
+	case TiTipPago_TarjetaEmpresarial:
+		return "Tarjeta empresarial"
+	case TiTipPago_Vale:
+		return "Vale"
+	case TiTipPago_Retencion:
+		return "Retención"
+	case TiTipPago_PagoAnticipo:
+		return "Pago por anticipo"
+	case TiTipPago_ValorFiscal:
+		return "Valor fiscal"
+	case TiTipPago_ValorComercial:
+		return "Valor comercial"
+	case TiTipPago_Compensacion:
+		return "Compensación"
+	case TiTipPago_Permuta:
+		return "Permuta"
+	case TiTipPago_PagoBancario:
+		return "Pago bancario"
+	case TiTipPago_PagoMovil:
+		return "Pago Móvil"
+	case TiTipPago_Donacion:
+		return "Donación"
+	case TiTipPago_Promocion:
+		return "Promoción"
+	case TiTipPago_ConsumoInterno:
+		return "Consumo Interno"
+	case TiTipPago_PagoElectronico:
+		return "Pago Electrónico"
+	case TiTipPago_Otro:
+		return "Otro"
 	default:
-		return fmt.Sprintf("Tipo pago: %d", t)
+		return fmt.Sprintf("%d", t)
 	}
 }
 
@@ -620,7 +694,7 @@ func (t TDepartamento) String() string {
 	case TDepartamento_AltoParaguay:
 		return "ALTO PARAGUAY"
 	default:
-		return fmt.Sprintf("Departamento %d", t)
+		return fmt.Sprintf("%d", t)
 	}
 }
 
@@ -680,7 +754,7 @@ func (u TcUniMed) String() string {
 	case TcUniMed_Servicio:
 		return "Servicio"
 	default:
-		return fmt.Sprintf("Unidad %d", u)
+		return fmt.Sprintf("%d", u)
 	}
 }
 
@@ -747,6 +821,47 @@ const (
 
 func (c CMondT) String() string { return string(c) }
 
+// Descripcion retorna la descripción de la moneda (dDesMoneOpe) según el
+// catálogo de SIFEN, o el código si no es conocida
+func (c CMondT) Descripcion() string {
+	switch c {
+	case CMondT_PYG:
+		return "Guarani"
+	case CMondT_USD:
+		return "US Dollar"
+	case CMondT_EUR:
+		return "Euro"
+	case CMondT_BRL:
+		return "Brazilian Real"
+	case CMondT_ARS:
+		return "Argentine Peso"
+	case CMondT_UYU:
+		return "Peso Uruguayo"
+	case CMondT_CLP:
+		return "Chilean Peso"
+	case CMondT_BOB:
+		return "Boliviano"
+	case CMondT_PEN:
+		return "Nuevo Sol"
+	case CMondT_COP:
+		return "Colombian Peso"
+	case CMondT_GBP:
+		return "Pound Sterling"
+	case CMondT_JPY:
+		return "Yen"
+	case CMondT_CHF:
+		return "Swiss Franc"
+	case CMondT_CAD:
+		return "Canadian Dollar"
+	case CMondT_AUD:
+		return "Australian Dollar"
+	case CMondT_CNY:
+		return "Yuan Renminbi"
+	default:
+		return string(c)
+	}
+}
+
 func (c CMondT) Codigo() string { return string(c) }
 
 // ============================================================================
@@ -868,7 +983,7 @@ func (t TiTipDocAso) String() string {
 	case TiTipDocAso_Impreso:
 		return "Impreso"
 	case TiTipDocAso_ConstanciaElectronica:
-		return "Constancia electrónica"
+		return "Constancia Electrónica"
 	default:
 		return fmt.Sprintf("%d", t)
 	}
@@ -917,7 +1032,7 @@ const (
 func (t TdTipCons) String() string {
 	switch t {
 	case TdTipCons_ConstanciaNoRetencion:
-		return "Constancia de no retención"
+		return "Constancia de no ser contribuyente"
 	case TdTipCons_ConstanciaMicroproductores:
 		return "Constancia de microproductores"
 	default:
@@ -966,7 +1081,7 @@ func (t TTiEvento) String() string {
 	case TTiEvento_ActualizacionTransporte:
 		return "Actualización de datos de transporte"
 	default:
-		return fmt.Sprintf("Evento %d", t)
+		return fmt.Sprintf("%d", t)
 	}
 }
 
@@ -0,0 +1,381 @@
+package validation
+
+import (
+	stderrors "errors"
+	"fmt"
+	"strings"
+
+	"github.com/rodascaar/sifen-go-py/sifen/errors"
+	"github.com/rodascaar/sifen-go-py/sifen/models"
+	"github.com/rodascaar/sifen-go-py/sifen/types"
+)
+
+// ============================================================================
+// Validación de reglas del Manual Técnico v150
+// ============================================================================
+
+// Violation es una regla del Manual Técnico v150 que el DE no cumple
+type Violation struct {
+	Code    string // Código del campo en el manual (E701, F008, ...)
+	Field   string // Ruta del campo en models.DocumentoElectronico
+	Message string
+}
+
+func (v Violation) Error() string {
+	return fmt.Sprintf("%s %s: %s", v.Code, v.Field, v.Message)
+}
+
+// Violations es el resultado de Validate, en el orden de los grupos del DE
+type Violations []Violation
+
+// Err retorna nil si no hay violaciones y, si las hay, ErrDEInvalido con
+// todas ellas como causa
+func (vs Violations) Err() error {
+	if len(vs) == 0 {
+		return nil
+	}
+	errs := make([]error, len(vs))
+	for i, v := range vs {
+		errs[i] = v
+	}
+	return errors.ErrDEInvalido.WithCause(stderrors.Join(errs...)).WithContext("violaciones", len(vs))
+}
+
+// Codes retorna los códigos de campo de las violaciones
+func (vs Violations) Codes() []string {
+	codes := make([]string, len(vs))
+	for i, v := range vs {
+		codes[i] = v.Code
+	}
+	return codes
+}
+
+// Validate aplica al DE las reglas entre campos del manual: grupos
+// obligatorios según iTiDE, datos del receptor según iNatRec, condición de la
+// operación, IVA de los ítems, totales contra la suma de los ítems y
+// documentos asociados. Conviene ejecutarlo antes de firmar.
+func Validate(de *models.DocumentoElectronico) Violations {
+	v := &validator{de: &de.DE, tipo: de.DE.GTimb.ITiDE}
+	if ope := v.de.GDatGralOpe.GOpeCom; ope != nil && ope.CMoneOpe != "" {
+		v.decimals = ope.CMoneOpe.Decimales()
+	}
+
+	v.timbrado()
+	v.operacion()
+	v.emisor()
+	v.receptor()
+	v.gruposPorTipo()
+	v.condicion()
+	v.items()
+	v.totales()
+	v.asociados()
+	return v.violations
+}
+
+type validator struct {
+	de         *models.DE
+	tipo       types.TTiDE
+	decimals   int
+	violations Violations
+}
+
+func (v *validator) add(code, field, format string, args ...any) {
+	v.violations = append(v.violations, Violation{Code: code, Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) is(tipos ...types.TTiDE) bool {
+	for _, t := range tipos {
+		if v.tipo == t {
+			return true
+		}
+	}
+	return false
+}
+
+// equal compara montos redondeados a los decimales de la moneda de la operación
+func (v *validator) equal(a, b types.Decimal) bool {
+	return a.Round(v.decimals) == b.Round(v.decimals)
+}
+
+func (v *validator) timbrado() {
+	t := v.de.GTimb
+	if t.DNumTim <= 0 {
+		v.add("C004", "DE.GTimb.DNumTim", "Número de timbrado requerido")
+	}
+	if len(t.DEst) != 3 {
+		v.add("C005", "DE.GTimb.DEst", "El establecimiento debe tener 3 dígitos")
+	}
+	if len(t.DPunExp) != 3 {
+		v.add("C006", "DE.GTimb.DPunExp", "El punto de expedición debe tener 3 dígitos")
+	}
+	if len(t.DNumDoc) != 7 {
+		v.add("C007", "DE.GTimb.DNumDoc", "El número de documento debe tener 7 dígitos")
+	}
+}
+
+func (v *validator) operacion() {
+	ope := v.de.GDatGralOpe.GOpeCom
+	if ope == nil {
+		if !v.is(types.TTiDE_NotaRemisionElectronica) {
+			v.add("D010", "DE.GDatGralOpe.GOpeCom", "Grupo gOpeCom requerido salvo en la nota de remisión")
+		}
+		return
+	}
+	if ope.ITipTra == nil && v.is(types.TTiDE_FacturaElectronica, types.TTiDE_AutofacturaElectronica) {
+		v.add("D011", "DE.GDatGralOpe.GOpeCom.ITipTra", "Tipo de transacción requerido en factura y autofactura")
+	}
+	if ope.CMoneOpe != types.CMondT_PYG {
+		if ope.DCondTiCam == nil {
+			v.add("D017", "DE.GDatGralOpe.GOpeCom.DCondTiCam", "Condición del tipo de cambio requerida si la moneda no es PYG")
+		} else if *ope.DCondTiCam == 1 && ope.DTiCam == nil {
+			v.add("D018", "DE.GDatGralOpe.GOpeCom.DTiCam", "Tipo de cambio requerido si la condición es global")
+		}
+	}
+}
+
+func (v *validator) emisor() {
+	emis := v.de.GDatGralOpe.GEmis
+	if emis.DRucEm == "" {
+		v.add("D101", "DE.GDatGralOpe.GEmis.DRucEm", "RUC del emisor requerido")
+	}
+	if emis.ITipCont == 0 {
+		v.add("D103", "DE.GDatGralOpe.GEmis.ITipCont", "Tipo de contribuyente del emisor requerido")
+	}
+	if emis.DNomEmi == "" {
+		v.add("D105", "DE.GDatGralOpe.GEmis.DNomEmi", "Nombre o razón social del emisor requerido")
+	}
+}
+
+func (v *validator) receptor() {
+	const path = "DE.GDatGralOpe.GDatRec"
+	rec := v.de.GDatGralOpe.GDatRec
+	switch rec.INatRec {
+	case types.TiNatRec_Contribuyente:
+		if rec.ITiContRec == nil {
+			v.add("D205", path+".ITiContRec", "Tipo de contribuyente del receptor requerido si es contribuyente")
+		}
+		if rec.DRucRec == "" {
+			v.add("D206", path+".DRucRec", "RUC del receptor requerido si es contribuyente")
+		}
+		if rec.ITipIDRec != nil {
+			v.add("D208", path+".ITipIDRec", "No informar el documento de identidad si el receptor es contribuyente")
+		}
+	case types.TiNatRec_NoContribuyente:
+		if rec.DRucRec != "" {
+			v.add("D206", path+".DRucRec", "No informar el RUC si el receptor no es contribuyente")
+		}
+		if rec.ITipIDRec == nil && rec.ITiOpe != types.TiTiOpe_B2F {
+			v.add("D208", path+".ITipIDRec", "Tipo de documento del receptor requerido si no es contribuyente")
+		}
+		if rec.ITipIDRec != nil && rec.DNumIDRec == "" {
+			v.add("D210", path+".DNumIDRec", "Número de documento del receptor requerido")
+		}
+	default:
+		v.add("D201", path+".INatRec", "Naturaleza del receptor debe ser 1 (contribuyente) o 2 (no contribuyente)")
+	}
+	if rec.DNomRec == "" {
+		v.add("D211", path+".DNomRec", "Nombre o razón social del receptor requerido")
+	}
+}
+
+// gruposPorTipo verifica que cada tipo de DE tenga su grupo propio y no los
+// de otros tipos
+func (v *validator) gruposPorTipo() {
+	dtip := v.de.GDtipDE
+	groups := []struct {
+		code, field, name string
+		present           bool
+		tipos             []types.TTiDE
+	}{
+		{"E010", "DE.GDtipDE.GCamFE", "gCamFE", dtip.GCamFE != nil, []types.TTiDE{types.TTiDE_FacturaElectronica}},
+		{"E300", "DE.GDtipDE.GCamAE", "gCamAE", dtip.GCamAE != nil, []types.TTiDE{types.TTiDE_AutofacturaElectronica}},
+		{"E400", "DE.GDtipDE.GCamNCDE", "gCamNCDE", dtip.GCamNCDE != nil, []types.TTiDE{types.TTiDE_NotaCreditoElectronica, types.TTiDE_NotaDebitoElectronica}},
+		{"E500", "DE.GDtipDE.GCamNRE", "gCamNRE", dtip.GCamNRE != nil, []types.TTiDE{types.TTiDE_NotaRemisionElectronica}},
+		{"E600", "DE.GDtipDE.GCamCond", "gCamCond", dtip.GCamCond != nil, []types.TTiDE{types.TTiDE_FacturaElectronica, types.TTiDE_AutofacturaElectronica}},
+	}
+	for _, g := range groups {
+		switch required := v.is(g.tipos...); {
+		case required && !g.present:
+			v.add(g.code, g.field, "Grupo %s requerido para %s", g.name, v.tipo)
+		case !required && g.present:
+			v.add(g.code, g.field, "No informar el grupo %s para %s", g.name, v.tipo)
+		}
+	}
+
+	if v.is(types.TTiDE_NotaRemisionElectronica) && dtip.GTransp == nil {
+		v.add("E900", "DE.GDtipDE.GTransp", "Grupo gTransp requerido en la nota de remisión")
+	}
+}
+
+func (v *validator) condicion() {
+	const path = "DE.GDtipDE.GCamCond"
+	cond := v.de.GDtipDE.GCamCond
+	if cond == nil {
+		return
+	}
+	switch cond.ICondOpe {
+	case types.TiCondOpe_Contado:
+		if len(cond.GPaConEIni) == 0 {
+			v.add("E605", path+".GPaConEIni", "Forma de pago requerida si la operación es al contado")
+		}
+	case types.TiCondOpe_Credito:
+		cred := cond.GCredCond
+		if cred == nil {
+			v.add("E640", path+".GCredCond", "Grupo gPagCred requerido si la operación es a crédito")
+			return
+		}
+		switch cred.ICondCred {
+		case types.TiCondCredito_Plazo:
+			if cred.DPlazoCre == "" {
+				v.add("E643", path+".GCredCond.DPlazoCre", "Plazo del crédito requerido si la condición es a plazo")
+			}
+		case types.TiCondCredito_Cuotas:
+			if cred.DCuotas <= 0 {
+				v.add("E644", path+".GCredCond.DCuotas", "Cantidad de cuotas requerida si la condición es en cuotas")
+			}
+		default:
+			v.add("E641", path+".GCredCond.ICondCred", "Condición del crédito debe ser 1 (plazo) o 2 (cuota)")
+		}
+	default:
+		v.add("E601", path+".ICondOpe", "Condición de la operación debe ser 1 (contado) o 2 (crédito)")
+	}
+}
+
+// requiresIVA indica si los ítems deben informar gCamIVA (E730)
+func (v *validator) requiresIVA() bool {
+	ope := v.de.GDatGralOpe.GOpeCom
+	if ope == nil || v.is(types.TTiDE_AutofacturaElectronica, types.TTiDE_NotaRemisionElectronica) {
+		return false
+	}
+	return ope.ITImp != types.TTImp_ISC
+}
+
+func (v *validator) items() {
+	items := v.de.GDtipDE.GCamItemList
+	if len(items) == 0 {
+		v.add("E700", "DE.GDtipDE.GCamItemList", "El DE debe tener al menos un ítem")
+		return
+	}
+	requiresIVA := v.requiresIVA()
+
+	for i, item := range items {
+		path := fmt.Sprintf("DE.GDtipDE.GCamItemList[%d]", i)
+		if item.DCodInt == "" {
+			v.add("E701", path+".DCodInt", "Código interno del ítem requerido")
+		}
+		if strings.TrimSpace(item.DDesProSer) == "" {
+			v.add("E708", path+".DDesProSer", "Descripción del producto o servicio requerida")
+		}
+		if item.DCantProSer.Sign() <= 0 {
+			v.add("E711", path+".DCantProSer", "La cantidad debe ser mayor a cero")
+		}
+
+		if v.is(types.TTiDE_NotaRemisionElectronica) {
+			continue
+		}
+		val := item.GValorItem
+		resta := val.GValorRestaItem
+		unit := val.DPUniProSer.Sub(deref(resta.DDescItem)).Sub(deref(resta.DDescGloItem)).
+			Sub(deref(resta.DAntPreUniIt)).Sub(deref(resta.DAntGloPreUniIt))
+		if want := unit.Mul(item.DCantProSer); !v.equal(resta.DTotOpeItem, want) {
+			v.add("EA008", path+".GValorItem.GValorRestaItem.DTotOpeItem",
+				"Total de la operación del ítem %s no coincide con precio menos descuentos y anticipos por cantidad (%s)",
+				resta.DTotOpeItem, want)
+		}
+
+		iva := item.GCamIVA
+		if iva == nil {
+			if requiresIVA {
+				v.add("E730", path+".GCamIVA", "Grupo gCamIVA requerido según el tipo de impuesto")
+			}
+			continue
+		}
+		switch iva.IAfecIVA {
+		case types.TiAfecIVA_GravadoIVA, types.TiAfecIVA_GravadoParcial:
+			if iva.DTasaIVA != types.DecimalFromInt(5) && iva.DTasaIVA != types.DecimalFromInt(10) {
+				v.add("E734", path+".GCamIVA.DTasaIVA", "La tasa de IVA de un ítem gravado debe ser 5 o 10")
+			}
+		case types.TiAfecIVA_Exonerado, types.TiAfecIVA_Exento:
+			if !iva.DTasaIVA.IsZero() {
+				v.add("E734", path+".GCamIVA.DTasaIVA", "La tasa de IVA de un ítem exento o exonerado debe ser 0")
+			}
+		}
+	}
+}
+
+func (v *validator) totales() {
+	const path = "DE.GTotSub"
+	tot := v.de.GTotSub
+	if v.is(types.TTiDE_NotaRemisionElectronica) {
+		if tot != nil {
+			v.add("F001", path, "No informar el grupo gTotSub en la nota de remisión")
+		}
+		return
+	}
+	if tot == nil {
+		v.add("F001", path, "Grupo gTotSub requerido")
+		return
+	}
+
+	var sum types.Decimal
+	for _, item := range v.de.GDtipDE.GCamItemList {
+		sum = sum.Add(item.GValorItem.GValorRestaItem.DTotOpeItem)
+	}
+	if !v.equal(tot.DTotOpe, sum) {
+		v.add("F008", path+".DTotOpe", "Total bruto %s no coincide con la suma de los ítems (%s)", tot.DTotOpe, sum)
+	}
+	if gral := tot.DTotOpe.Sub(tot.DRedon).Add(deref(tot.DComi)); !v.equal(tot.DTotGralOpe, gral) {
+		v.add("F014", path+".DTotGralOpe", "Total general %s no coincide con dTotOpe - dRedon + dComi (%s)", tot.DTotGralOpe, gral)
+	}
+	if ope := v.de.GDatGralOpe.GOpeCom; ope != nil && (ope.ITImp == types.TTImp_IVA || ope.ITImp == types.TTImp_IVARenta) {
+		iva := deref(tot.DIVA5).Add(deref(tot.DIVA10)).Sub(deref(tot.DLiqTotIVA5)).Sub(deref(tot.DLiqTotIVA10)).Add(deref(tot.DIVAComi))
+		if !v.equal(deref(tot.DTotIVA), iva) {
+			v.add("F017", path+".DTotIVA", "Total del IVA %s no coincide con dIVA5 + dIVA10 - dLiqTotIVA5 - dLiqTotIVA10 + dIVAComi (%s)", deref(tot.DTotIVA), iva)
+		}
+	}
+}
+
+func (v *validator) asociados() {
+	asoc := v.de.GCamDEAsoc
+	if len(asoc) == 0 {
+		if v.is(types.TTiDE_AutofacturaElectronica, types.TTiDE_NotaCreditoElectronica, types.TTiDE_NotaDebitoElectronica) {
+			v.add("H001", "DE.GCamDEAsoc", "Documento asociado requerido para %s", v.tipo)
+		}
+		return
+	}
+
+	constancia := false
+	for i, a := range asoc {
+		path := fmt.Sprintf("DE.GCamDEAsoc[%d]", i)
+		switch a.ITipDocAso {
+		case types.TiTipDocAso_Electronico:
+			if len(a.DCdCDERef) != 44 {
+				v.add("H004", path+".DCdCDERef", "CDC del documento asociado requerido (44 dígitos)")
+			}
+		case types.TiTipDocAso_Impreso:
+			if a.DNTimDI == "" {
+				v.add("H005", path+".DNTimDI", "Timbrado del documento impreso requerido")
+			}
+			if a.DNumDocAso == "" {
+				v.add("H008", path+".DNumDocAso", "Número del documento impreso requerido")
+			}
+		case types.TiTipDocAso_ConstanciaElectronica:
+			constancia = true
+			if a.ITipCons == nil {
+				v.add("H014", path+".ITipCons", "Tipo de constancia requerido")
+			}
+		default:
+			v.add("H002", path+".ITipDocAso", "Tipo de documento asociado debe ser 1, 2 o 3")
+		}
+	}
+	if v.is(types.TTiDE_AutofacturaElectronica) && !constancia {
+		v.add("H002", "DE.GCamDEAsoc", "La autofactura requiere una constancia electrónica asociada")
+	}
+}
+
+func deref(d *types.Decimal) types.Decimal {
+	if d == nil {
+		return types.Decimal{}
+	}
+	return *d
+}
@@ -0,0 +1,107 @@
+package validation
+
+import (
+	stderrors "errors"
+	"reflect"
+	"testing"
+
+	"github.com/rodascaar/sifen-go-py/sifen/errors"
+	"github.com/rodascaar/sifen-go-py/sifen/models"
+	"github.com/rodascaar/sifen-go-py/sifen/types"
+)
+
+func validFactura() *models.DocumentoElectronico {
+	de := models.NewDE("01800695631001001000000612024123017595714694")
+	tipTra := types.TTipTra_VentaMercaderia
+	tipCont := types.TiTipCont_PersonaJuridica
+	de.DE.GTimb = models.TgTimb{ITiDE: types.TTiDE_FacturaElectronica, DNumTim: 12345678, DEst: "001", DPunExp: "001", DNumDoc: "0000061"}
+	de.DE.GDatGralOpe = models.TdDatGralOpe{
+		GOpeCom: &models.TgOpeCom{ITipTra: &tipTra, ITImp: types.TTImp_IVA, CMoneOpe: types.CMondT_PYG},
+		GEmis:   models.TgEmis{DRucEm: "80069563", ITipCont: types.TiTipCont_PersonaJuridica, DNomEmi: "Empresa Test SA"},
+		GDatRec: models.TgDatRec{INatRec: types.TiNatRec_Contribuyente, ITiContRec: &tipCont, DRucRec: "80012345", DNomRec: "Cliente SA"},
+	}
+	de.DE.GDtipDE = models.TgDtipDE{
+		GCamFE:   &models.TgCamFE{IIndPres: types.TiIndPres_Presencial},
+		GCamCond: &models.TgCamCond{ICondOpe: types.TiCondOpe_Contado, GPaConEIni: []models.TgPaConEIni{{ITiPago: types.TiTipPago_Efectivo, DMonTiPag: types.DecimalFromInt(330000)}}},
+		GCamItemList: []models.TgCamItem{{
+			DCodInt: "001", DDesProSer: "Producto", CUniMed: types.TcUniMed_Unidad, DCantProSer: types.DecimalFromInt(3),
+			GValorItem: models.TgValorItem{DPUniProSer: types.DecimalFromInt(110000), DTotBruOpeItem: types.DecimalFromInt(330000), GValorRestaItem: models.TgValorRestaItem{DTotOpeItem: types.DecimalFromInt(330000)}},
+			GCamIVA:    &models.TgCamIVA{IAfecIVA: types.TiAfecIVA_GravadoIVA, DPropIVA: types.DecimalFromInt(100), DTasaIVA: types.DecimalFromInt(10), DBasGravIVA: types.DecimalFromInt(300000), DLiqIVAItem: types.DecimalFromInt(30000)},
+		}},
+	}
+	de.DE.GTotSub = &models.TgTotSub{DSub10: types.DecimalFromInt(330000).Ptr(), DTotOpe: types.DecimalFromInt(330000), DTotGralOpe: types.DecimalFromInt(330000), DIVA10: types.DecimalFromInt(30000).Ptr(), DTotIVA: types.DecimalFromInt(30000).Ptr()}
+	return de
+}
+
+func TestValidateValid(t *testing.T) {
+	if vs := Validate(validFactura()); len(vs) != 0 {
+		t.Fatalf("Validate() = %v", vs)
+	}
+	if err := Violations(nil).Err(); err != nil {
+		t.Errorf("Err() = %v", err)
+	}
+}
+
+func TestValidateRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(de *models.DE)
+		want   []string
+	}{
+		{"receptor contribuyente sin RUC", func(de *models.DE) { de.GDatGralOpe.GDatRec.DRucRec = "" }, []string{"D206"}},
+		{"receptor no contribuyente sin documento", func(de *models.DE) {
+			de.GDatGralOpe.GDatRec = models.TgDatRec{INatRec: types.TiNatRec_NoContribuyente, ITiOpe: types.TiTiOpe_B2C, DRucRec: "80012345", DNomRec: "Cliente"}
+		}, []string{"D206", "D208"}},
+		{"factura sin gCamFE y con gCamNCDE", func(de *models.DE) {
+			de.GDtipDE.GCamFE = nil
+			de.GDtipDE.GCamNCDE = &models.TgCamNCDE{}
+		}, []string{"E010", "E400"}},
+		{"crédito sin gPagCred", func(de *models.DE) { de.GDtipDE.GCamCond = &models.TgCamCond{ICondOpe: types.TiCondOpe_Credito} }, []string{"E640"}},
+		{"crédito en cuotas sin cantidad", func(de *models.DE) {
+			de.GDtipDE.GCamCond = &models.TgCamCond{ICondOpe: types.TiCondOpe_Credito, GCredCond: &models.TgCredCond{ICondCred: types.TiCondCredito_Cuotas}}
+		}, []string{"E644"}},
+		{"ítem sin código ni IVA", func(de *models.DE) {
+			de.GDtipDE.GCamItemList[0].DCodInt = ""
+			de.GDtipDE.GCamItemList[0].GCamIVA = nil
+		}, []string{"E701", "E730"}},
+		{"total del ítem distinto", func(de *models.DE) {
+			de.GDtipDE.GCamItemList[0].GValorItem.GValorRestaItem.DTotOpeItem = types.DecimalFromInt(320000)
+		}, []string{"EA008", "F008"}},
+		{"total general distinto", func(de *models.DE) { de.GTotSub.DTotGralOpe = types.DecimalFromInt(330001) }, []string{"F014"}},
+		{"nota de crédito sin documento asociado", func(de *models.DE) {
+			de.GTimb.ITiDE = types.TTiDE_NotaCreditoElectronica
+			de.GDtipDE.GCamFE = nil
+			de.GDtipDE.GCamCond = nil
+			de.GDtipDE.GCamNCDE = &models.TgCamNCDE{IMotEmi: types.TiMotEmiNC_Devolucion}
+		}, []string{"H001"}},
+		{"nota de remisión sin transporte y con totales", func(de *models.DE) {
+			de.GTimb.ITiDE = types.TTiDE_NotaRemisionElectronica
+			de.GDtipDE.GCamFE = nil
+			de.GDtipDE.GCamCond = nil
+			de.GDtipDE.GCamNRE = &models.TgCamNRE{IMotEmiNR: types.TiMotEmiNR_TrasladoVentas}
+		}, []string{"E900", "F001"}},
+	}
+
+	for _, tt := range tests {
+		de := validFactura()
+		tt.mutate(&de.DE)
+		vs := Validate(de)
+		if got := vs.Codes(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Validate() = %v; want codes %v", tt.name, vs, tt.want)
+		}
+		if err := vs.Err(); !stderrors.Is(err, errors.ErrDEInvalido) {
+			t.Errorf("%s: Err() = %v", tt.name, err)
+		}
+	}
+}
+
+func TestViolationField(t *testing.T) {
+	de := validFactura()
+	de.DE.GDtipDE.GCamItemList[0].GCamIVA.DTasaIVA = types.Decimal{}
+
+	vs := Validate(de)
+	want := Violation{Code: "E734", Field: "DE.GDtipDE.GCamItemList[0].GCamIVA.DTasaIVA", Message: "La tasa de IVA de un ítem gravado debe ser 5 o 10"}
+	if len(vs) != 1 || vs[0] != want {
+		t.Errorf("Validate() = %v; want %v", vs, want)
+	}
+}